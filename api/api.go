@@ -0,0 +1,30 @@
+// Package api contains OpenAPI contract of /api endpoints
+package api
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Spec is OpenAPI specification of the service in YAML
+//
+//go:embed openapi.yml
+var Spec []byte
+
+// Load parses Spec and checks that it is a valid OpenAPI document
+func Load(ctx context.Context) (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(Spec)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse openapi spec: %w", err)
+	}
+
+	err = doc.Validate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid openapi spec: %w", err)
+	}
+
+	return doc, nil
+}
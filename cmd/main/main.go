@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -9,9 +10,16 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	"zadanie-6105/api"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/auth/oidc"
 	"zadanie-6105/internal/config"
 	"zadanie-6105/internal/server"
+	authmw "zadanie-6105/internal/server/middleware/auth"
 	"zadanie-6105/internal/server/middleware/logger"
+	"zadanie-6105/internal/server/middleware/validation"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/memory"
 
 	"zadanie-6105/internal/storage/postgres"
 
@@ -37,19 +45,87 @@ func main() {
 	log.Info(fmt.Sprintf("SERVER_ADDRESS env variable: %s", cfg.SERVER_ADDRESS))
 	log.Debug("debug messages are enabled")
 
-	// TODO add logging to storage operations
-	storage, err := postgres.New(ctx, *cfg)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(ctx, log, *cfg, os.Args[2:]))
+	}
+
+	var storage storage.Storage
+	switch cfg.STORAGE_DRIVER {
+	case "memory":
+		memoryStorage := memory.New()
+		if cfg.MEMORY_FIXTURES != "" {
+			err = memoryStorage.SeedFile(cfg.MEMORY_FIXTURES)
+			if err != nil {
+				log.Error(fmt.Errorf("failed to load fixtures: %s", err).Error())
+				os.Exit(1)
+			}
+		}
+		storage = memoryStorage
+		log.Warn("in-memory storage is used, data will be lost on shutdown")
+	case "postgres":
+		// TODO add logging to storage operations
+		postgresStorage, err := postgres.New(ctx, *cfg)
+		if err != nil {
+			log.Error(fmt.Errorf("failed to init storage: %s", err).Error())
+			os.Exit(1)
+		}
+		defer postgresStorage.Pool.Close()
+		storage = postgresStorage
+		log.Info("database connected")
+	default:
+		log.Error(fmt.Sprintf("unknown STORAGE_DRIVER: %s", cfg.STORAGE_DRIVER))
+		os.Exit(1)
+	}
+
+	secret := []byte(cfg.AUTH_SECRET)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Error(fmt.Errorf("failed to generate auth secret: %w", err).Error())
+			os.Exit(1)
+		}
+		log.Warn("AUTH_SECRET is not set, tokens will be invalid after restart")
+	}
+	tokens := auth.NewTokens(secret, cfg.AUTH_TOKEN_TTL)
+	if cfg.AUTH_LEGACY_USERNAME {
+		log.Warn("legacy username authentication is enabled")
+	}
+
+	var idp *oidc.Provider
+	if cfg.OIDC_ISSUER != "" {
+		idp, err = oidc.Discover(ctx, oidc.Config{
+			Issuer:          cfg.OIDC_ISSUER,
+			Audience:        cfg.OIDC_AUDIENCE,
+			UsernameClaim:   cfg.OIDC_USERNAME_CLAIM,
+			CreateEmployees: cfg.OIDC_CREATE_EMPLOYEES,
+			JWKSCacheTTL:    cfg.OIDC_JWKS_CACHE_TTL,
+		})
+		if err != nil {
+			log.Error(fmt.Errorf("failed to init oidc provider: %w", err).Error())
+			os.Exit(1)
+		}
+		log.Info(fmt.Sprintf("oidc provider %s is enabled", cfg.OIDC_ISSUER))
+	}
+
+	spec, err := api.Load(ctx)
 	if err != nil {
-		log.Error(fmt.Errorf("failed to init storage: %s", err).Error())
+		log.Error(err.Error())
+		os.Exit(1)
+	}
+	validator, err := validation.New(log, spec)
+	if err != nil {
+		log.Error(err.Error())
 		os.Exit(1)
 	}
-	defer storage.Pool.Close()
-	log.Info("database connected")
 
 	r := mux.NewRouter()
 	apiRouter := r.PathPrefix("/api").Subrouter()
 	apiRouter.Use(logger.New(log))
-	server.LoadRoutes(apiRouter, storage)
+	apiRouter.Use(validator)
+	apiRouter.Use(authmw.New(log, tokens, idp, storage, cfg.AUTH_LEGACY_USERNAME))
+	// TODO send reset tokens by email
+	notifier := auth.NewLogNotifier(log)
+	server.LoadRoutes(apiRouter, storage, tokens, notifier, spec, *cfg)
 
 	server := &http.Server{
 		Addr:    cfg.SERVER_ADDRESS,
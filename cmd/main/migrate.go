@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"zadanie-6105/internal/config"
+	"zadanie-6105/internal/storage/postgres"
+)
+
+const migrateUsage = "usage: main migrate up | down [steps] | version"
+
+// runMigrate handles `migrate` subcommand and returns exit code
+func runMigrate(ctx context.Context, log *slog.Logger, cfg config.Config, args []string) int {
+	if len(args) == 0 {
+		log.Error(migrateUsage)
+		return 2
+	}
+
+	cfg.MIGRATE_ON_START = false
+	storage, err := postgres.New(ctx, cfg)
+	if err != nil {
+		log.Error(fmt.Errorf("failed to init storage: %w", err).Error())
+		return 1
+	}
+	defer storage.Pool.Close()
+
+	switch args[0] {
+	case "up":
+		err = storage.MigrateUp(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Error(migrateUsage)
+				return 2
+			}
+		}
+		err = storage.MigrateDown(ctx, steps)
+	case "version":
+	default:
+		log.Error(migrateUsage)
+		return 2
+	}
+	if err != nil {
+		log.Error(err.Error())
+		return 1
+	}
+
+	version, err := storage.SchemaVersion(ctx)
+	if err != nil {
+		log.Error(err.Error())
+		return 1
+	}
+	log.Info("schema version", slog.Int("version", version))
+
+	return 0
+}
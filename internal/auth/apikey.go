@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+)
+
+// Scopes of API keys. Key can be used only on routes
+// which require one of its scopes
+const (
+	ScopeReadTenders     = "tenders:read"
+	ScopeCreateBids      = "bids:create"
+	ScopeSubmitDecisions = "bids:decide"
+)
+
+var scopes = []string{ScopeReadTenders, ScopeCreateBids, ScopeSubmitDecisions}
+
+// apiKeyPrefix tells API keys apart from other secrets, e.g. in logs
+const apiKeyPrefix = "tk_"
+
+// ValidScope reports whether scope is known
+func ValidScope(scope string) bool {
+	return slices.Contains(scopes, scope)
+}
+
+// APIKey is a key of organization presented in Authorization: ApiKey
+// header. It acts on behalf of the employee who issued or rotated it
+type APIKey struct {
+	ID     string
+	Scopes []string
+	Principal
+}
+
+// Allows reports whether key has scope
+func (k APIKey) Allows(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// WithAPIKey returns context carrying the API key of request. Principal
+// of the key is set only by routes which accept API keys
+func WithAPIKey(ctx context.Context, key APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyKey, key)
+}
+
+// APIKeyFromContext returns API key used by request if there is one
+func APIKeyFromContext(ctx context.Context) (APIKey, bool) {
+	key, ok := ctx.Value(apiKeyKey).(APIKey)
+	return key, ok
+}
+
+// NewAPIKey returns random API key shown to the user once, its hash
+// which is kept in storage and a short prefix to recognize it in lists
+func NewAPIKey() (string, string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", fmt.Errorf("cannot generate api key: %w", err)
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	return key, HashAPIKey(key), key[:len(apiKeyPrefix)+6], nil
+}
+
+// HashAPIKey returns hash under which API key is stored
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,165 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// errUnknownKey is returned if token is signed by key which
+// provider does not publish
+var errUnknownKey = errors.New("unknown signing key")
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// key returns public key with kid. Keys are cached for JWKSCacheTTL, unknown
+// kid means keys were rotated and triggers refetch, but not more often than
+// JWKSMinRefresh. Cached keys are used if provider is not available
+func (p *Provider) key(ctx context.Context, kid string) (any, error) {
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	fetched := p.keys != nil
+	age := p.now().Sub(p.fetchedAt)
+	p.mu.Unlock()
+
+	if ok && age < p.cfg.JWKSCacheTTL {
+		return key, nil
+	}
+	if !ok && fetched && age < p.cfg.JWKSMinRefresh {
+		return nil, errUnknownKey
+	}
+
+	// keys are fetched without holding the lock,
+	// concurrent lookups share one request
+	keys, err, _ := p.fetches.Do("jwks", func() (any, error) {
+		return p.refreshKeys(ctx)
+	})
+	if err != nil {
+		if ok {
+			return key, nil
+		}
+
+		return nil, err
+	}
+
+	key, ok = keys.(map[string]any)[kid]
+	if !ok {
+		return nil, errUnknownKey
+	}
+
+	return key, nil
+}
+
+// refreshKeys fetches signing keys and replaces cached ones
+func (p *Provider) refreshKeys(ctx context.Context) (map[string]any, error) {
+	keys, err := p.fetchKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.fetchedAt = p.now()
+	p.mu.Unlock()
+
+	return keys, nil
+}
+
+// fetchKeys returns signing keys published by provider by their kid,
+// keys of unsupported types are skipped. Failed request is reported
+// as ErrUnavailable
+func (p *Provider) fetchKeys(ctx context.Context) (map[string]any, error) {
+	var set jwks
+	if err := getJSON(ctx, p.cfg.Client, p.jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("%w: cannot fetch jwks: %s", ErrUnavailable, err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid rsa exponent")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve")
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
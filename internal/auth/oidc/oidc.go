@@ -0,0 +1,186 @@
+// Package oidc verifies bearer tokens issued by an external OpenID
+// Connect provider and maps them to employees by a claim
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrInvalidToken is returned if token is not signed by provider,
+// is expired, is issued for another audience or lacks username claim
+var ErrInvalidToken = errors.New("invalid oidc token")
+
+// ErrUnavailable is returned if signing keys cannot be fetched
+// from provider and there are no cached ones to check token with
+var ErrUnavailable = errors.New("oidc provider is not available")
+
+// Defaults of Config
+const (
+	DefaultUsernameClaim   = "preferred_username"
+	DefaultJWKSCacheTTL    = time.Hour
+	DefaultJWKSMinRefresh  = 10 * time.Second
+	defaultLeeway          = 30 * time.Second
+	discoveryPath          = "/.well-known/openid-configuration"
+	maxProviderResponseLen = 1 << 20
+)
+
+// signingMethods are asymmetric algorithms accepted from provider
+var signingMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// Config of OpenID Connect provider
+type Config struct {
+	// Issuer is URL of provider, discovery document is fetched from it
+	// and iss claim of tokens must be equal to it
+	Issuer string
+	// Audience must be present in aud claim, it is usually client id
+	Audience string
+	// UsernameClaim holds employee.username, preferred_username by default
+	UsernameClaim string
+	// CreateEmployees enables just-in-time creation of employees
+	// who sign in for the first time
+	CreateEmployees bool
+	// JWKSCacheTTL is how long signing keys are used without refetching
+	JWKSCacheTTL time.Duration
+	// JWKSMinRefresh limits how often keys are refetched
+	// when token is signed by unknown key
+	JWKSMinRefresh time.Duration
+	Client         *http.Client
+}
+
+// Identity is a user authenticated by provider
+type Identity struct {
+	Subject   string
+	Username  string
+	FirstName string
+	LastName  string
+}
+
+// Provider verifies tokens of one OpenID Connect provider
+type Provider struct {
+	cfg     Config
+	jwksURI string
+	now     func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]any
+	fetchedAt time.Time
+	fetches   singleflight.Group
+}
+
+type discovery struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// Discover fetches discovery document of cfg.Issuer
+// and returns Provider verifying its tokens
+func Discover(ctx context.Context, cfg Config) (*Provider, error) {
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return nil, errors.New("issuer and audience are required")
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = DefaultUsernameClaim
+	}
+	if cfg.JWKSCacheTTL <= 0 {
+		cfg.JWKSCacheTTL = DefaultJWKSCacheTTL
+	}
+	if cfg.JWKSMinRefresh <= 0 {
+		cfg.JWKSMinRefresh = DefaultJWKSMinRefresh
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	var doc discovery
+	err := getJSON(ctx, cfg.Client, strings.TrimSuffix(cfg.Issuer, "/")+discoveryPath, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot discover provider: %w", err)
+	}
+	if doc.Issuer != cfg.Issuer {
+		return nil, fmt.Errorf("issuer mismatch: expected %q, provider reports %q", cfg.Issuer, doc.Issuer)
+	}
+	if doc.JWKSURI == "" {
+		return nil, errors.New("provider has no jwks_uri")
+	}
+
+	return &Provider{
+		cfg:     cfg,
+		jwksURI: doc.JWKSURI,
+		now:     time.Now,
+	}, nil
+}
+
+// CreateEmployees reports whether unknown users should be created
+func (p *Provider) CreateEmployees() bool {
+	return p.cfg.CreateEmployees
+}
+
+// Verify checks signature, issuer, audience and expiration
+// of token and returns identity it was issued for
+func (p *Provider) Verify(ctx context.Context, token string) (Identity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(defaultLeeway),
+		jwt.WithTimeFunc(p.now),
+	)
+	if errors.Is(err, ErrUnavailable) {
+		return Identity{}, err
+	}
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+
+	identity := Identity{
+		FirstName: stringClaim(claims, "given_name"),
+		LastName:  stringClaim(claims, "family_name"),
+	}
+	identity.Subject, _ = claims.GetSubject()
+	identity.Username = stringClaim(claims, p.cfg.UsernameClaim)
+	if identity.Username == "" {
+		return Identity{}, fmt.Errorf("%w: claim %s is empty", ErrInvalidToken, p.cfg.UsernameClaim)
+	}
+
+	return identity, nil
+}
+
+func stringClaim(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s from %s", resp.Status, url)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, maxProviderResponseLen)).Decode(v)
+}
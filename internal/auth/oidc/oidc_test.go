@@ -0,0 +1,273 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"zadanie-6105/internal/auth/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// clock is a time source for provider which can be moved forward
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func newProvider(t *testing.T, idp *oidctest.Server) (*Provider, *clock) {
+	t.Helper()
+
+	p, err := Discover(context.Background(), Config{
+		Issuer:         idp.Issuer(),
+		Audience:       oidctest.Audience,
+		JWKSCacheTTL:   time.Minute,
+		JWKSMinRefresh: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("discover: %s", err)
+	}
+
+	c := &clock{t: time.Now()}
+	p.now = c.now
+
+	return p, c
+}
+
+func TestVerify(t *testing.T) {
+	idp := oidctest.NewServer()
+	defer idp.Close()
+	other := oidctest.NewServer()
+	defer other.Close()
+
+	p, _ := newProvider(t, idp)
+
+	tests := []struct {
+		name     string
+		token    string
+		username string
+	}{
+		{
+			name:     "valid",
+			token:    idp.Token("user1", nil),
+			username: "user1",
+		},
+		{
+			name:     "audience in list",
+			token:    idp.Token("user1", jwt.MapClaims{"aud": []string{"other", oidctest.Audience}}),
+			username: "user1",
+		},
+		{
+			name:  "another audience",
+			token: idp.Token("user1", jwt.MapClaims{"aud": "other"}),
+		},
+		{
+			name:  "another issuer",
+			token: idp.Token("user1", jwt.MapClaims{"iss": other.Issuer()}),
+		},
+		{
+			name:  "expired",
+			token: idp.Token("user1", jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}),
+		},
+		{
+			name:  "without expiration",
+			token: idp.Token("user1", jwt.MapClaims{"exp": nil}),
+		},
+		{
+			name:  "without username",
+			token: idp.Token("", nil),
+		},
+		{
+			name:  "signed by another provider",
+			token: other.Token("user1", jwt.MapClaims{"iss": idp.Issuer()}),
+		},
+		{
+			name:  "symmetric algorithm",
+			token: hs256(t, jwt.MapClaims{"iss": idp.Issuer(), "aud": oidctest.Audience, "preferred_username": "user1"}),
+		},
+		{
+			name:  "malformed",
+			token: "not.a.token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := p.Verify(context.Background(), tt.token)
+			if tt.username == "" {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("expected ErrInvalidToken, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("verify: %s", err)
+			}
+			if identity.Username != tt.username {
+				t.Errorf("expected username %q, got %q", tt.username, identity.Username)
+			}
+			if identity.Subject != "sub-"+tt.username {
+				t.Errorf("unexpected subject %q", identity.Subject)
+			}
+		})
+	}
+}
+
+func TestVerifyNames(t *testing.T) {
+	idp := oidctest.NewServer()
+	defer idp.Close()
+
+	p, _ := newProvider(t, idp)
+	identity, err := p.Verify(context.Background(), idp.Token("user1", jwt.MapClaims{"given_name": "Ivan", "family_name": "Ivanov"}))
+	if err != nil {
+		t.Fatalf("verify: %s", err)
+	}
+	if identity.FirstName != "Ivan" || identity.LastName != "Ivanov" {
+		t.Errorf("unexpected names %q %q", identity.FirstName, identity.LastName)
+	}
+}
+
+func TestUsernameClaim(t *testing.T) {
+	idp := oidctest.NewServer()
+	defer idp.Close()
+
+	p, err := Discover(context.Background(), Config{
+		Issuer:        idp.Issuer(),
+		Audience:      oidctest.Audience,
+		UsernameClaim: "email",
+	})
+	if err != nil {
+		t.Fatalf("discover: %s", err)
+	}
+
+	identity, err := p.Verify(context.Background(), idp.Token("user1", jwt.MapClaims{"email": "user1@example.com"}))
+	if err != nil {
+		t.Fatalf("verify: %s", err)
+	}
+	if identity.Username != "user1@example.com" {
+		t.Errorf("expected username from email claim, got %q", identity.Username)
+	}
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	idp := oidctest.NewServer()
+	defer idp.Close()
+
+	_, err := Discover(context.Background(), Config{
+		Issuer:   idp.Issuer() + "/",
+		Audience: oidctest.Audience,
+	})
+	if err == nil {
+		t.Fatal("expected issuer mismatch")
+	}
+}
+
+func TestJWKSCache(t *testing.T) {
+	idp := oidctest.NewServer()
+	defer idp.Close()
+
+	p, c := newProvider(t, idp)
+	token := idp.Token("user1", nil)
+
+	for i := 0; i < 3; i++ {
+		if _, err := p.Verify(context.Background(), token); err != nil {
+			t.Fatalf("verify: %s", err)
+		}
+	}
+	if n := idp.JWKSRequests(); n != 1 {
+		t.Fatalf("expected keys to be fetched once, got %d", n)
+	}
+
+	// token signed by unknown key does not refetch keys too often
+	forged := oidctest.NewServer()
+	defer forged.Close()
+	forged.Rotate(true)
+	for i := 0; i < 3; i++ {
+		if _, err := p.Verify(context.Background(), forged.Token("user1", jwt.MapClaims{"iss": idp.Issuer()})); err == nil {
+			t.Fatal("expected token of unknown key to be rejected")
+		}
+	}
+	if n := idp.JWKSRequests(); n != 1 {
+		t.Fatalf("expected no refetch within min refresh interval, got %d requests", n)
+	}
+
+	c.advance(2 * time.Minute)
+	if _, err := p.Verify(context.Background(), token); err != nil {
+		t.Fatalf("verify: %s", err)
+	}
+	if n := idp.JWKSRequests(); n != 2 {
+		t.Fatalf("expected keys to be refetched after cache ttl, got %d requests", n)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	idp := oidctest.NewServer()
+	defer idp.Close()
+
+	p, c := newProvider(t, idp)
+	old := idp.Token("user1", nil)
+	if _, err := p.Verify(context.Background(), old); err != nil {
+		t.Fatalf("verify: %s", err)
+	}
+
+	idp.Rotate(true)
+	c.advance(2 * time.Second)
+
+	if _, err := p.Verify(context.Background(), idp.Token("user1", nil)); err != nil {
+		t.Fatalf("token signed by new key: %s", err)
+	}
+	if n := idp.JWKSRequests(); n != 2 {
+		t.Fatalf("expected keys to be refetched on unknown kid, got %d requests", n)
+	}
+	if _, err := p.Verify(context.Background(), old); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected token of removed key to be rejected, got %v", err)
+	}
+}
+
+func TestProviderUnavailable(t *testing.T) {
+	idp := oidctest.NewServer()
+
+	p, c := newProvider(t, idp)
+	token := idp.Token("user1", nil)
+	if _, err := p.Verify(context.Background(), token); err != nil {
+		t.Fatalf("verify: %s", err)
+	}
+
+	idp.Close()
+	c.advance(2 * time.Minute)
+
+	if _, err := p.Verify(context.Background(), token); err != nil {
+		t.Fatalf("expected cached key to be used while provider is down: %s", err)
+	}
+
+	// without cached keys token cannot be checked, it is not invalid
+	other := oidctest.NewServer()
+	defer other.Close()
+	fresh, _ := newProvider(t, other)
+	fresh.jwksURI = p.jwksURI
+	_, err := fresh.Verify(context.Background(), token)
+	if !errors.Is(err, ErrUnavailable) || errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected %v, got %v", ErrUnavailable, err)
+	}
+}
+
+func hs256(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("sign: %s", err)
+	}
+
+	return token
+}
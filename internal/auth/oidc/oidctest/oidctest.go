@@ -0,0 +1,147 @@
+// Package oidctest is an in-process OpenID Connect provider for tests.
+// It serves discovery document and JWKS and signs tokens with RSA keys
+// which can be rotated
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Audience is aud claim of tokens issued by Token
+const Audience = "zadanie-6105"
+
+type signingKey struct {
+	kid string
+	key *rsa.PrivateKey
+}
+
+// Server is a fake provider, its URL is the issuer
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	keys         []signingKey
+	generated    int
+	jwksRequests int
+}
+
+// NewServer starts provider with one signing key
+func NewServer() *Server {
+	s := &Server{}
+	s.Rotate(false)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /jwks", s.jwks)
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// Issuer returns iss claim of tokens and URL to discover provider
+func (s *Server) Issuer() string {
+	return s.URL
+}
+
+// Rotate adds new signing key used for tokens issued from now on.
+// Previous keys are kept in JWKS unless dropOld is set
+func (s *Server) Rotate(dropOld bool) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: cannot generate key: %s", err))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if dropOld {
+		s.keys = nil
+	}
+	s.generated++
+	s.keys = append(s.keys, signingKey{
+		kid: fmt.Sprintf("key-%d", s.generated),
+		key: key,
+	})
+}
+
+// JWKSRequests returns how many times JWKS was fetched
+func (s *Server) JWKSRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.jwksRequests
+}
+
+// Token returns token for username signed by the current key.
+// claims override default ones, e.g. aud or exp, nil removes claim
+func (s *Server) Token(username string, claims jwt.MapClaims) string {
+	now := time.Now()
+	c := jwt.MapClaims{
+		"iss":                s.Issuer(),
+		"aud":                Audience,
+		"sub":                "sub-" + username,
+		"preferred_username": username,
+		"iat":                now.Unix(),
+		"exp":                now.Add(time.Hour).Unix(),
+	}
+	for name, value := range claims {
+		if value == nil {
+			delete(c, name)
+			continue
+		}
+		c[name] = value
+	}
+
+	s.mu.Lock()
+	key := s.keys[len(s.keys)-1]
+	s.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+	token.Header["kid"] = key.kid
+	signed, err := token.SignedString(key.key)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: cannot sign token: %s", err))
+	}
+
+	return signed
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                                s.Issuer(),
+		"jwks_uri":                              s.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.jwksRequests++
+	keys := make([]map[string]string, 0, len(s.keys))
+	for _, k := range s.keys {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": k.kid,
+			"n":   base64.RawURLEncoding.EncodeToString(k.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.key.E)).Bytes()),
+		})
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+}
@@ -0,0 +1,72 @@
+// Package auth authenticates employees and keeps
+// the authenticated user in request context
+package auth
+
+import "context"
+
+// Principal is the authenticated user on whose behalf request is made.
+// OrganizationID is the acting organization, it is empty if it should
+// be resolved from organizations the user is responsible for. Legacy
+// is set if identity is not verified: user is named by username param
+// or signed in without password in legacy mode. CredentialsVersion is
+// the version of password the token was issued for, 0 if user has none
+type Principal struct {
+	UserID             string
+	Username           string
+	OrganizationID     string
+	Legacy             bool
+	CredentialsVersion int
+}
+
+type contextKey int
+
+const (
+	principalKey contextKey = iota
+	legacyKey
+	legacyTrustedKey
+	apiKeyKey
+)
+
+// WithPrincipal returns context carrying the authenticated user
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// FromContext returns the authenticated user if there is one
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey).(Principal)
+	return p, ok
+}
+
+// legacyIdentity is the user named by legacy client,
+// principal is nil if request names nobody
+type legacyIdentity struct {
+	principal *Principal
+}
+
+// WithLegacy marks context of request served while legacy identity is
+// still accepted and puts aside p, the user named by username param or by
+// token issued without password. It is trusted only by routes which
+// accept legacy identity, see WithLegacyTrusted
+func WithLegacy(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, legacyKey, legacyIdentity{principal: p})
+}
+
+// LegacyFromContext returns the user put aside by WithLegacy, false
+// is returned if legacy identity is not accepted at all
+func LegacyFromContext(ctx context.Context) (*Principal, bool) {
+	identity, ok := ctx.Value(legacyKey).(legacyIdentity)
+	return identity.principal, ok
+}
+
+// WithLegacyTrusted marks context of route which accepts legacy identity
+func WithLegacyTrusted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, legacyTrustedKey, true)
+}
+
+// LegacyEnabled reports whether identity from request body
+// can be trusted when nobody is authenticated
+func LegacyEnabled(ctx context.Context) bool {
+	trusted, _ := ctx.Value(legacyTrustedKey).(bool)
+	return trusted
+}
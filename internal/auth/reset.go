@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// NewResetToken returns random password reset token sent to the user
+// and its hash which is the only thing kept in storage
+func NewResetToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("cannot generate reset token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	return token, HashResetToken(token), nil
+}
+
+// HashResetToken returns hash under which reset token is stored.
+// Token is random, so plain sha256 is enough
+func HashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ResetNotifier delivers password reset token to the user,
+// e.g. by email
+type ResetNotifier interface {
+	NotifyPasswordReset(ctx context.Context, p Principal, token string, expiresAt time.Time) error
+}
+
+// LogNotifier writes reset tokens to log. It is the default
+// notifier meant for development only
+type LogNotifier struct {
+	log *slog.Logger
+}
+
+func NewLogNotifier(log *slog.Logger) *LogNotifier {
+	return &LogNotifier{
+		log: log.With(slog.String("component", "auth/notifier")),
+	}
+}
+
+func (n *LogNotifier) NotifyPasswordReset(ctx context.Context, p Principal, token string, expiresAt time.Time) error {
+	n.log.Info("password reset requested",
+		slog.String("username", p.Username),
+		slog.String("token", token),
+		slog.Time("expires_at", expiresAt),
+	)
+
+	return nil
+}
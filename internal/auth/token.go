@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken is returned for malformed, expired or forged tokens
+var ErrInvalidToken = errors.New("invalid token")
+
+type claims struct {
+	Username           string `json:"username"`
+	Legacy             bool   `json:"legacy,omitempty"`
+	CredentialsVersion int    `json:"cv,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Tokens issues and verifies HMAC signed JWT
+type Tokens struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewTokens returns Tokens signing with secret,
+// issued tokens expire after ttl
+func NewTokens(secret []byte, ttl time.Duration) *Tokens {
+	return &Tokens{
+		secret: secret,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// Issue returns signed token for p and time when it expires
+func (t *Tokens) Issue(p Principal) (string, time.Time, error) {
+	now := t.now()
+	expiresAt := now.Add(t.ttl)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		Username:           p.Username,
+		Legacy:             p.Legacy,
+		CredentialsVersion: p.CredentialsVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   p.UserID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+
+	signed, err := token.SignedString(t.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("cannot sign token: %w", err)
+	}
+
+	return signed, expiresAt, nil
+}
+
+// Parse verifies token and returns principal it was issued for
+func (t *Tokens) Parse(token string) (Principal, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (any, error) {
+		return t.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(t.now),
+	)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+	if c.Subject == "" {
+		return Principal{}, fmt.Errorf("%w: subject is empty", ErrInvalidToken)
+	}
+
+	return Principal{
+		UserID:             c.Subject,
+		Username:           c.Username,
+		Legacy:             c.Legacy,
+		CredentialsVersion: c.CredentialsVersion,
+	}, nil
+}
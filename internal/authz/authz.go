@@ -0,0 +1,232 @@
+// Package authz answers whether user may perform action on resource.
+// Roles of employees in organizations are described by domain.RoleAllows,
+// visibility of tenders and bids by storage which follows domain policies.
+// Denials are returned as *Error, handlers turn them into 401, 403 or 404
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/domain"
+)
+
+var (
+	// ErrUnauthenticated is returned when user is not provided or does not exist
+	ErrUnauthenticated = errors.New("user does not exist")
+	// ErrForbidden is returned when role of user does not allow action
+	// or user is not responsible for organization at all
+	ErrForbidden = errors.New("action is not allowed")
+	// ErrNotFound is returned when checked tender or bid does not exist
+	ErrNotFound = errors.New("resource not found")
+)
+
+// Kinds of resources
+const (
+	KindOrganization = "organization"
+	KindTender       = "tender"
+	KindBid          = "bid"
+)
+
+// Resource is an entity action is performed on
+type Resource struct {
+	Kind string
+	ID   string
+}
+
+func Organization(id string) Resource {
+	return Resource{Kind: KindOrganization, ID: id}
+}
+
+func Tender(id string) Resource {
+	return Resource{Kind: KindTender, ID: id}
+}
+
+func Bid(id string) Resource {
+	return Resource{Kind: KindBid, ID: id}
+}
+
+// Error describes denied action, it matches ErrUnauthenticated,
+// ErrForbidden or ErrNotFound with errors.Is
+type Error struct {
+	Err      error
+	Action   string
+	Resource Resource
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s on %s %q: %s", e.Action, e.Resource.Kind, e.Resource.ID, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Repository is a part of storage needed to resolve users, their roles,
+// organizations which own tenders and bids and visibility of them
+type Repository interface {
+	GetUserID(ctx context.Context, username string) (string, error)
+	GetRole(ctx context.Context, organizationID, userID string) (string, error)
+	GetOrganizationIDByTender(ctx context.Context, tenderID string) (string, error)
+	GetBidOrganizationID(ctx context.Context, bidID string) (string, error)
+	GetBidTenderID(ctx context.Context, bidID string) (string, error)
+	TenderVisible(ctx context.Context, tenderID, userID string) (bool, error)
+	BidVisible(ctx context.Context, bidID, userID string) (bool, error)
+}
+
+// Authorizer checks permissions of employees
+type Authorizer struct {
+	repo Repository
+}
+
+func New(repo Repository) *Authorizer {
+	return &Authorizer{repo: repo}
+}
+
+// UserID returns id of employee with username
+// or ErrUnauthenticated if there is no such employee
+func (a *Authorizer) UserID(ctx context.Context, username string) (string, error) {
+	userID, err := a.userID(ctx, username)
+	if errors.Is(err, ErrUnauthenticated) {
+		return "", &Error{Err: err}
+	}
+
+	return userID, err
+}
+
+func (a *Authorizer) userID(ctx context.Context, username string) (string, error) {
+	if username == "" {
+		return "", ErrUnauthenticated
+	}
+
+	userID, err := a.repo.GetUserID(ctx, username)
+	if err != nil {
+		return "", fmt.Errorf("cannot get user id: %w", err)
+	}
+	if userID == "" {
+		return "", ErrUnauthenticated
+	}
+
+	return userID, nil
+}
+
+// Authorize checks that user with username may perform action on resource
+// and returns his id. Rules are:
+//   - action on organization is allowed by role of user in it;
+//   - action on tender is allowed by role in organization of the tender,
+//     published tenders are seen by anyone, even anonymous user;
+//   - bids are seen according to domain.BidVisible, decisions and feedback
+//     are allowed by role in organization of the tender, other actions
+//     on bid by role in organization which made it;
+//   - authenticated user bound to organization, e.g. by API key, cannot
+//     act on behalf of another one.
+func (a *Authorizer) Authorize(ctx context.Context, username, action string, resource Resource) (string, error) {
+	userID, err := a.authorize(ctx, username, action, resource)
+	if errors.Is(err, ErrUnauthenticated) || errors.Is(err, ErrForbidden) || errors.Is(err, ErrNotFound) {
+		return "", &Error{Err: err, Action: action, Resource: resource}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return userID, nil
+}
+
+func (a *Authorizer) authorize(ctx context.Context, username, action string, resource Resource) (string, error) {
+	var userID string
+	anonymous := username == "" && action == domain.ActionViewTender && resource.Kind == KindTender
+	if !anonymous {
+		var err error
+		userID, err = a.userID(ctx, username)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	switch resource.Kind {
+	case KindOrganization:
+		return userID, a.organization(ctx, userID, resource.ID, action)
+	case KindTender:
+		return userID, a.tender(ctx, userID, resource.ID, action)
+	case KindBid:
+		return userID, a.bid(ctx, userID, resource.ID, action)
+	}
+
+	return "", fmt.Errorf("unknown resource %q", resource.Kind)
+}
+
+func (a *Authorizer) organization(ctx context.Context, userID, organizationID, action string) error {
+	if organizationID == "" || userID == "" {
+		return ErrForbidden
+	}
+
+	p, ok := auth.FromContext(ctx)
+	if ok && p.UserID == userID && p.OrganizationID != "" && p.OrganizationID != organizationID {
+		return ErrForbidden
+	}
+
+	role, err := a.repo.GetRole(ctx, organizationID, userID)
+	if err != nil {
+		return fmt.Errorf("cannot get role: %w", err)
+	}
+	if !domain.RoleAllows(role, action) {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+func (a *Authorizer) tender(ctx context.Context, userID, tenderID, action string) error {
+	organizationID, err := a.repo.GetOrganizationIDByTender(ctx, tenderID)
+	if err != nil {
+		return fmt.Errorf("cannot get organization of tender: %w", err)
+	}
+	if organizationID == "" {
+		return ErrNotFound
+	}
+
+	if action == domain.ActionViewTender {
+		visible, err := a.repo.TenderVisible(ctx, tenderID, userID)
+		if err != nil {
+			return fmt.Errorf("cannot check tender visibility: %w", err)
+		}
+		if visible {
+			return nil
+		}
+	}
+
+	return a.organization(ctx, userID, organizationID, action)
+}
+
+func (a *Authorizer) bid(ctx context.Context, userID, bidID, action string) error {
+	tenderID, err := a.repo.GetBidTenderID(ctx, bidID)
+	if err != nil {
+		return fmt.Errorf("cannot get tender of bid: %w", err)
+	}
+	if tenderID == "" {
+		return ErrNotFound
+	}
+
+	switch action {
+	case domain.ActionViewBid:
+		visible, err := a.repo.BidVisible(ctx, bidID, userID)
+		if err != nil {
+			return fmt.Errorf("cannot check bid visibility: %w", err)
+		}
+		if !visible {
+			return ErrForbidden
+		}
+
+		return nil
+	case domain.ActionDecideBid, domain.ActionLeaveFeedback:
+		return a.tender(ctx, userID, tenderID, action)
+	}
+
+	organizationID, err := a.repo.GetBidOrganizationID(ctx, bidID)
+	if err != nil {
+		return fmt.Errorf("cannot get organization of bid: %w", err)
+	}
+
+	return a.organization(ctx, userID, organizationID, action)
+}
@@ -0,0 +1,220 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage/memory"
+	"zadanie-6105/internal/storage/models"
+)
+
+type fixture struct {
+	st *memory.Storage
+
+	buyer, supplier     string
+	draft, published    string
+	bidDraft, bidPublic string
+}
+
+// newFixture creates buyer organization with an employee of every role,
+// its draft and published tenders, and supplier organization which made
+// a draft and a published bid on the published tender
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+
+	ctx := context.Background()
+	st := memory.New()
+	f := fixture{st: st}
+
+	f.buyer = st.AddOrganization("buyer", "", "LLC")
+	for username, role := range map[string]string{
+		"owner":    domain.RoleOwner,
+		"manager":  domain.RoleProcurementManager,
+		"approver": domain.RoleApprover,
+		"viewer":   domain.RoleViewer,
+	} {
+		st.AddMember(f.buyer, st.AddEmployee(username, "", ""), role)
+	}
+
+	f.supplier = st.AddOrganization("supplier", "", "LLC")
+	supplierID := st.AddEmployee("supplier", "", "")
+	st.AddResponsible(f.supplier, supplierID)
+	st.AddEmployee("outsider", "", "")
+
+	for _, id := range []*string{&f.draft, &f.published} {
+		tender, err := st.InsertTender(ctx, &models.NewTenderRequest{
+			Name:           "tender",
+			ServiceType:    "Construction",
+			OrganizationID: f.buyer,
+		}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		*id = tender.ID
+	}
+	if _, err := st.ChangeTenderStatus(ctx, f.published, memory.StatusPublished); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []*string{&f.bidDraft, &f.bidPublic} {
+		bid, err := st.InsertBid(ctx, models.BidRequest{
+			Name:       "bid",
+			TenderID:   f.published,
+			AuthorType: "Organization",
+			AuthorID:   supplierID,
+		}, f.supplier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		*id = bid.ID
+	}
+	if _, err := st.ChangeBitStatus(ctx, f.bidPublic, domain.BidPublished); err != nil {
+		t.Fatal(err)
+	}
+
+	return f
+}
+
+func TestAuthorize(t *testing.T) {
+	f := newFixture(t)
+
+	tests := []struct {
+		name     string
+		username string
+		action   string
+		resource Resource
+		err      error
+	}{
+		// organization roles
+		{"owner creates tender", "owner", domain.ActionCreateTender, Organization(f.buyer), nil},
+		{"manager creates tender", "manager", domain.ActionCreateTender, Organization(f.buyer), nil},
+		{"approver cannot create tender", "approver", domain.ActionCreateTender, Organization(f.buyer), ErrForbidden},
+		{"viewer cannot create tender", "viewer", domain.ActionCreateTender, Organization(f.buyer), ErrForbidden},
+		{"owner manages members", "owner", domain.ActionManageMembers, Organization(f.buyer), nil},
+		{"manager cannot manage members", "manager", domain.ActionManageMembers, Organization(f.buyer), ErrForbidden},
+		{"viewer views members", "viewer", domain.ActionViewMembers, Organization(f.buyer), nil},
+		{"owner manages api keys", "owner", domain.ActionManageAPIKeys, Organization(f.buyer), nil},
+		{"manager cannot manage api keys", "manager", domain.ActionManageAPIKeys, Organization(f.buyer), ErrForbidden},
+		{"outsider cannot act on organization", "outsider", domain.ActionViewMembers, Organization(f.buyer), ErrForbidden},
+		{"member of another organization", "supplier", domain.ActionCreateTender, Organization(f.buyer), ErrForbidden},
+		{"empty organization", "owner", domain.ActionCreateTender, Organization(""), ErrForbidden},
+
+		// authentication
+		{"anonymous cannot act on organization", "", domain.ActionViewMembers, Organization(f.buyer), ErrUnauthenticated},
+		{"unknown user", "ghost", domain.ActionViewMembers, Organization(f.buyer), ErrUnauthenticated},
+		{"unknown user views published tender", "ghost", domain.ActionViewTender, Tender(f.published), ErrUnauthenticated},
+
+		// tenders
+		{"anonymous views published tender", "", domain.ActionViewTender, Tender(f.published), nil},
+		{"anonymous cannot view draft tender", "", domain.ActionViewTender, Tender(f.draft), ErrForbidden},
+		{"anonymous cannot edit tender", "", domain.ActionEditTender, Tender(f.published), ErrUnauthenticated},
+		{"outsider views published tender", "outsider", domain.ActionViewTender, Tender(f.published), nil},
+		{"outsider cannot view draft tender", "outsider", domain.ActionViewTender, Tender(f.draft), ErrForbidden},
+		{"viewer views draft tender", "viewer", domain.ActionViewTender, Tender(f.draft), nil},
+		{"manager edits tender", "manager", domain.ActionEditTender, Tender(f.draft), nil},
+		{"manager changes tender status", "manager", domain.ActionChangeTender, Tender(f.draft), nil},
+		{"approver cannot edit tender", "approver", domain.ActionEditTender, Tender(f.draft), ErrForbidden},
+		{"viewer sees tender history", "viewer", domain.ActionTenderHistory, Tender(f.draft), nil},
+		{"outsider cannot see tender history", "outsider", domain.ActionTenderHistory, Tender(f.published), ErrForbidden},
+		{"missing tender", "owner", domain.ActionEditTender, Tender("missing"), ErrNotFound},
+		{"anonymous views missing tender", "", domain.ActionViewTender, Tender("missing"), ErrNotFound},
+
+		// bids
+		{"author organization views draft bid", "supplier", domain.ActionViewBid, Bid(f.bidDraft), nil},
+		{"author organization edits bid", "supplier", domain.ActionEditBid, Bid(f.bidDraft), nil},
+		{"tender organization cannot view draft bid", "owner", domain.ActionViewBid, Bid(f.bidDraft), ErrForbidden},
+		{"tender organization views published bid", "viewer", domain.ActionViewBid, Bid(f.bidPublic), nil},
+		{"outsider cannot view published bid", "outsider", domain.ActionViewBid, Bid(f.bidPublic), ErrForbidden},
+		{"tender organization cannot edit bid", "owner", domain.ActionEditBid, Bid(f.bidPublic), ErrForbidden},
+		{"approver decides", "approver", domain.ActionDecideBid, Bid(f.bidPublic), nil},
+		{"owner decides", "owner", domain.ActionDecideBid, Bid(f.bidPublic), nil},
+		{"manager cannot decide", "manager", domain.ActionDecideBid, Bid(f.bidPublic), ErrForbidden},
+		{"author organization cannot decide", "supplier", domain.ActionDecideBid, Bid(f.bidPublic), ErrForbidden},
+		{"approver leaves feedback", "approver", domain.ActionLeaveFeedback, Bid(f.bidPublic), nil},
+		{"viewer cannot leave feedback", "viewer", domain.ActionLeaveFeedback, Bid(f.bidPublic), ErrForbidden},
+		{"missing bid", "supplier", domain.ActionViewBid, Bid("missing"), ErrNotFound},
+		{"decision on missing bid", "approver", domain.ActionDecideBid, Bid("missing"), ErrNotFound},
+	}
+
+	a := New(f.st)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID, err := a.Authorize(context.Background(), tt.username, tt.action, tt.resource)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+			if err != nil {
+				var authzErr *Error
+				if !errors.As(err, &authzErr) {
+					t.Fatalf("expected *Error, got %T", err)
+				}
+				if userID != "" {
+					t.Errorf("expected no user id on denial, got %q", userID)
+				}
+				return
+			}
+			if tt.username != "" && userID == "" {
+				t.Errorf("expected user id of %s", tt.username)
+			}
+		})
+	}
+}
+
+// TestAuthorizeBoundPrincipal checks that user authenticated with
+// API key of one organization cannot act on behalf of another one
+func TestAuthorizeBoundPrincipal(t *testing.T) {
+	f := newFixture(t)
+	other := f.st.AddOrganization("other", "", "LLC")
+
+	ownerID, err := f.st.GetUserID(context.Background(), "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.st.AddMember(other, ownerID, domain.RoleOwner)
+
+	tests := []struct {
+		name         string
+		boundTo      string
+		organization string
+		err          error
+	}{
+		{"unbound principal", "", other, nil},
+		{"bound to the organization", f.buyer, f.buyer, nil},
+		{"bound to another organization", f.buyer, other, ErrForbidden},
+	}
+
+	a := New(f.st)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := auth.WithPrincipal(context.Background(), auth.Principal{
+				UserID:         ownerID,
+				Username:       "owner",
+				OrganizationID: tt.boundTo,
+			})
+
+			_, err := a.Authorize(ctx, "owner", domain.ActionCreateTender, Organization(tt.organization))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestUserID(t *testing.T) {
+	f := newFixture(t)
+	a := New(f.st)
+
+	if _, err := a.UserID(context.Background(), ""); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("empty username: expected %v, got %v", ErrUnauthenticated, err)
+	}
+	if _, err := a.UserID(context.Background(), "ghost"); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("unknown username: expected %v, got %v", ErrUnauthenticated, err)
+	}
+
+	userID, err := a.UserID(context.Background(), "viewer")
+	if err != nil || userID == "" {
+		t.Errorf("viewer: expected user id, got %q, %v", userID, err)
+	}
+}
@@ -1,7 +1,12 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -13,6 +18,34 @@ type Config struct {
 	POSTGRES_PASSWORD string
 	POSTGRES_PORT     string
 	POSTGRES_DATABASE string
+	STORAGE_DRIVER    string
+	MEMORY_FIXTURES   string
+	MIGRATE_ON_START  bool
+	// AUTH_SECRET is a key for signing tokens, random key is
+	// generated on start if it is empty
+	AUTH_SECRET    string
+	AUTH_TOKEN_TTL time.Duration
+	// AUTH_LEGACY_USERNAME keeps trusting username param and identity
+	// from request body for clients which do not send tokens yet. It is
+	// meant for migration and applies only to routes which existed
+	// before token authentication
+	AUTH_LEGACY_USERNAME bool
+	// AUTH_MAX_LOGIN_ATTEMPTS wrong passwords in a row lock
+	// account for AUTH_LOCKOUT_DURATION
+	AUTH_MAX_LOGIN_ATTEMPTS int
+	AUTH_LOCKOUT_DURATION   time.Duration
+	AUTH_RESET_TOKEN_TTL    time.Duration
+	// ADMIN_USERNAMES is a comma separated list of employees
+	// allowed to manage accounts
+	ADMIN_USERNAMES []string
+	// OIDC_ISSUER enables sign in with tokens of OpenID Connect
+	// provider, OIDC_AUDIENCE is required then
+	OIDC_ISSUER         string
+	OIDC_AUDIENCE       string
+	OIDC_USERNAME_CLAIM string
+	// OIDC_CREATE_EMPLOYEES creates employees on first sign in
+	OIDC_CREATE_EMPLOYEES bool
+	OIDC_JWKS_CACHE_TTL   time.Duration
 }
 
 // NewConfig returns pointer to new Config instance and error if occurs
@@ -26,7 +59,72 @@ func NewConfig() (*Config, error) {
 		POSTGRES_HOST:     os.Getenv("POSTGRES_HOST"),
 		POSTGRES_PORT:     os.Getenv("POSTGRES_PORT"),
 		POSTGRES_DATABASE: os.Getenv("POSTGRES_DATABASE"),
+		STORAGE_DRIVER:    envLoad("STORAGE_DRIVER", "postgres"),
+		MEMORY_FIXTURES:   os.Getenv("MEMORY_FIXTURES"),
+		AUTH_SECRET:       os.Getenv("AUTH_SECRET"),
+		OIDC_ISSUER:       os.Getenv("OIDC_ISSUER"),
+		OIDC_AUDIENCE:     os.Getenv("OIDC_AUDIENCE"),
+
+		OIDC_USERNAME_CLAIM: envLoad("OIDC_USERNAME_CLAIM", "preferred_username"),
+	}
+
+	migrateOnStart, err := strconv.ParseBool(envLoad("MIGRATE_ON_START", "true"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MIGRATE_ON_START: %w", err)
+	}
+	config.MIGRATE_ON_START = migrateOnStart
+
+	tokenTTL, err := time.ParseDuration(envLoad("AUTH_TOKEN_TTL", "24h"))
+	if err != nil || tokenTTL <= 0 {
+		return nil, fmt.Errorf("invalid AUTH_TOKEN_TTL: %s", os.Getenv("AUTH_TOKEN_TTL"))
+	}
+	config.AUTH_TOKEN_TTL = tokenTTL
+
+	legacyUsername, err := strconv.ParseBool(envLoad("AUTH_LEGACY_USERNAME", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AUTH_LEGACY_USERNAME: %w", err)
+	}
+	config.AUTH_LEGACY_USERNAME = legacyUsername
+
+	maxLoginAttempts, err := strconv.Atoi(envLoad("AUTH_MAX_LOGIN_ATTEMPTS", "5"))
+	if err != nil || maxLoginAttempts <= 0 {
+		return nil, fmt.Errorf("invalid AUTH_MAX_LOGIN_ATTEMPTS: %s", os.Getenv("AUTH_MAX_LOGIN_ATTEMPTS"))
+	}
+	config.AUTH_MAX_LOGIN_ATTEMPTS = maxLoginAttempts
+
+	lockoutDuration, err := time.ParseDuration(envLoad("AUTH_LOCKOUT_DURATION", "15m"))
+	if err != nil || lockoutDuration <= 0 {
+		return nil, fmt.Errorf("invalid AUTH_LOCKOUT_DURATION: %s", os.Getenv("AUTH_LOCKOUT_DURATION"))
+	}
+	config.AUTH_LOCKOUT_DURATION = lockoutDuration
+
+	resetTokenTTL, err := time.ParseDuration(envLoad("AUTH_RESET_TOKEN_TTL", "1h"))
+	if err != nil || resetTokenTTL <= 0 {
+		return nil, fmt.Errorf("invalid AUTH_RESET_TOKEN_TTL: %s", os.Getenv("AUTH_RESET_TOKEN_TTL"))
+	}
+	config.AUTH_RESET_TOKEN_TTL = resetTokenTTL
+
+	for _, username := range strings.Split(os.Getenv("ADMIN_USERNAMES"), ",") {
+		if username = strings.TrimSpace(username); username != "" {
+			config.ADMIN_USERNAMES = append(config.ADMIN_USERNAMES, username)
+		}
+	}
+
+	if config.OIDC_ISSUER != "" && config.OIDC_AUDIENCE == "" {
+		return nil, errors.New("OIDC_AUDIENCE is required if OIDC_ISSUER is set")
+	}
+
+	createEmployees, err := strconv.ParseBool(envLoad("OIDC_CREATE_EMPLOYEES", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OIDC_CREATE_EMPLOYEES: %w", err)
+	}
+	config.OIDC_CREATE_EMPLOYEES = createEmployees
+
+	jwksCacheTTL, err := time.ParseDuration(envLoad("OIDC_JWKS_CACHE_TTL", "1h"))
+	if err != nil || jwksCacheTTL <= 0 {
+		return nil, fmt.Errorf("invalid OIDC_JWKS_CACHE_TTL: %s", os.Getenv("OIDC_JWKS_CACHE_TTL"))
 	}
+	config.OIDC_JWKS_CACHE_TTL = jwksCacheTTL
 
 	return &config, nil
 }
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrFinalStatus is returned when entity in final status is changed
+var ErrFinalStatus = errors.New("status is final")
+
+// Statuses of bid. Created, Published and Canceled are set by author
+// through bidAuthorStatus enum of the API, Approved and Rejected are results
+// of decisions made by tender organization
+const (
+	BidCreated   = "Created"
+	BidPublished = "Published"
+	BidCanceled  = "Canceled"
+	BidApproved  = "Approved"
+	BidRejected  = "Rejected"
+)
+
+// bidTransitions lists statuses reachable from every status,
+// Canceled, Approved and Rejected are final
+var bidTransitions = map[string][]string{
+	BidCreated:   {BidPublished, BidCanceled},
+	BidPublished: {BidCanceled, BidApproved, BidRejected},
+	BidCanceled:  {},
+	BidApproved:  {},
+	BidRejected:  {},
+}
+
+// ValidBidStatus reports whether author can set status
+// through bidStatus enum
+func ValidBidStatus(status string) bool {
+	return status == BidCreated || status == BidPublished || status == BidCanceled
+}
+
+// BidTransition returns ErrInvalidStatus if to is not a bid status
+// and ErrIllegalTransition if bid cannot move from one status to
+// another. Moving to the same status is allowed and changes nothing
+func BidTransition(from, to string) error {
+	if _, ok := bidTransitions[to]; !ok {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, to)
+	}
+	if from == to {
+		return nil
+	}
+	if !slices.Contains(bidTransitions[from], to) {
+		return fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, from, to)
+	}
+
+	return nil
+}
+
+// BidFinal reports whether bid with the given status
+// cannot be edited or rolled back anymore
+func BidFinal(status string) bool {
+	transitions, ok := bidTransitions[status]
+	return ok && len(transitions) == 0
+}
+
+// BidTenderOwnerStatuses are statuses of bids which responsibles
+// of the tender organization can see
+var BidTenderOwnerStatuses = []string{BidPublished, BidApproved, BidRejected}
+
+// BidViewer describes how user is related to a bid
+type BidViewer struct {
+	// Owner is set for author of the bid and responsibles
+	// of organization the bid was made from
+	Owner bool
+	// TenderOwner is set for responsibles of organization
+	// which owns the tender
+	TenderOwner bool
+}
+
+// BidVisible is the visibility policy of bids: owners see all their
+// bids, tender owners see only published and decided bids and
+// everyone else sees nothing
+func BidVisible(status string, viewer BidViewer) bool {
+	if viewer.Owner {
+		return true
+	}
+
+	return viewer.TenderOwner && slices.Contains(BidTenderOwnerStatuses, status)
+}
+
+// BidAcceptsDecisions reports whether tender organization
+// can approve or reject bid with the given status
+func BidAcceptsDecisions(status string) bool {
+	return status == BidPublished
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBidTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		err      error
+	}{
+		{BidCreated, BidCreated, nil},
+		{BidCreated, BidPublished, nil},
+		{BidCreated, BidCanceled, nil},
+		{BidCreated, BidApproved, ErrIllegalTransition},
+		{BidCreated, BidRejected, ErrIllegalTransition},
+		{BidPublished, BidCreated, ErrIllegalTransition},
+		{BidPublished, BidCanceled, nil},
+		{BidPublished, BidApproved, nil},
+		{BidPublished, BidRejected, nil},
+		{BidCanceled, BidPublished, ErrIllegalTransition},
+		{BidApproved, BidRejected, ErrIllegalTransition},
+		{BidApproved, BidApproved, nil},
+		{BidRejected, BidPublished, ErrIllegalTransition},
+		{BidPublished, "Archived", ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			if err := BidTransition(tt.from, tt.to); !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestBidFinal(t *testing.T) {
+	for status, final := range map[string]bool{
+		BidCreated:   false,
+		BidPublished: false,
+		BidCanceled:  true,
+		BidApproved:  true,
+		BidRejected:  true,
+		"Archived":   false,
+	} {
+		if BidFinal(status) != final {
+			t.Errorf("%s: expected final %v", status, final)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+)
+
+// ErrOrganizationInUse is returned when organization whose
+// tenders or bids are still in progress is deleted
+var ErrOrganizationInUse = errors.New("organization has tenders or bids in progress")
+
+// Types of organization from organization_type enum
+const (
+	OrganizationIE  = "IE"
+	OrganizationLLC = "LLC"
+	OrganizationJSC = "JSC"
+)
+
+// ValidOrganizationType reports whether type belongs to organization_type enum
+func ValidOrganizationType(organizationType string) bool {
+	return organizationType == OrganizationIE || organizationType == OrganizationLLC || organizationType == OrganizationJSC
+}
+
+// Deleting organization cascades to its tenders and bids, so it is refused
+// while any of them has one of these statuses. Open tenders and pending
+// bids are in progress, approved bid is the result of a tender of another
+// organization and must outlive its author. Closed tender must outlive
+// its owner too while it has approved bid of another organization,
+// otherwise the bid would be deleted along with the tender
+var (
+	TenderStatusesBlockingDeletion      = []string{TenderCreated, TenderPublished}
+	BidStatusesBlockingDeletion         = []string{BidCreated, BidPublished, BidApproved}
+	ReceivedBidStatusesBlockingDeletion = []string{BidApproved}
+)
+
+// OrganizationRemovable reports whether organization with tenders and bids
+// in the given statuses can be deleted. Received bid statuses are statuses
+// of bids submitted to tenders of the organization
+func OrganizationRemovable(tenderStatuses, bidStatuses, receivedBidStatuses []string) bool {
+	for _, status := range tenderStatuses {
+		if slices.Contains(TenderStatusesBlockingDeletion, status) {
+			return false
+		}
+	}
+	for _, status := range bidStatuses {
+		if slices.Contains(BidStatusesBlockingDeletion, status) {
+			return false
+		}
+	}
+	for _, status := range receivedBidStatuses {
+		if slices.Contains(ReceivedBidStatusesBlockingDeletion, status) {
+			return false
+		}
+	}
+
+	return true
+}
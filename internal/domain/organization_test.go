@@ -0,0 +1,29 @@
+package domain
+
+import "testing"
+
+func TestOrganizationRemovable(t *testing.T) {
+	tests := []struct {
+		name                string
+		tenderStatuses      []string
+		bidStatuses         []string
+		receivedBidStatuses []string
+		removable           bool
+	}{
+		{"nothing", nil, nil, nil, true},
+		{"finished", []string{TenderClosed, TenderCanceled}, []string{BidRejected, BidCanceled}, []string{BidRejected}, true},
+		{"open tender", []string{TenderClosed, TenderPublished}, nil, nil, false},
+		{"created tender", []string{TenderCreated}, nil, nil, false},
+		{"pending bid", nil, []string{BidPublished}, nil, false},
+		{"approved bid", nil, []string{BidApproved}, nil, false},
+		{"closed tender with approved bid", []string{TenderClosed}, nil, []string{BidRejected, BidApproved}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OrganizationRemovable(tt.tenderStatuses, tt.bidStatuses, tt.receivedBidStatuses); got != tt.removable {
+				t.Fatalf("expected %v, got %v", tt.removable, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+)
+
+// ErrLastOwner is returned when the only owner of organization
+// would lose his role
+var ErrLastOwner = errors.New("organization must keep an owner")
+
+// Roles of employees responsible for organization
+const (
+	RoleOwner              = "owner"
+	RoleProcurementManager = "procurement_manager"
+	RoleApprover           = "approver"
+	RoleViewer             = "viewer"
+)
+
+// Actions which employees perform on behalf of organization
+const (
+	ActionCreateTender  = "tender:create"
+	ActionEditTender    = "tender:edit"
+	ActionChangeTender  = "tender:status"
+	ActionViewTender    = "tender:view"
+	ActionTenderHistory = "tender:history"
+	ActionCreateBid     = "bid:create"
+	ActionEditBid       = "bid:edit"
+	ActionViewBid       = "bid:view"
+	ActionDecideBid     = "bid:decide"
+	ActionLeaveFeedback = "bid:feedback"
+	ActionViewMembers   = "members:view"
+	ActionManageMembers = "members:manage"
+	ActionManageAPIKeys = "api_keys:manage"
+
+	ActionViewOrganization   = "organization:view"
+	ActionManageOrganization = "organization:manage"
+)
+
+// rolePermissions lists actions allowed to every role. Procurement
+// managers run tenders and bids, approvers decide on bids of
+// organization tenders and viewers only read
+var rolePermissions = map[string][]string{
+	RoleOwner: {
+		ActionCreateTender, ActionEditTender, ActionChangeTender, ActionViewTender, ActionTenderHistory,
+		ActionCreateBid, ActionEditBid, ActionViewBid, ActionDecideBid, ActionLeaveFeedback,
+		ActionViewMembers, ActionManageMembers, ActionManageAPIKeys,
+		ActionViewOrganization, ActionManageOrganization,
+	},
+	RoleProcurementManager: {
+		ActionCreateTender, ActionEditTender, ActionChangeTender, ActionViewTender, ActionTenderHistory,
+		ActionCreateBid, ActionEditBid, ActionViewBid, ActionViewMembers, ActionViewOrganization,
+	},
+	RoleApprover: {
+		ActionViewTender, ActionTenderHistory, ActionViewBid, ActionDecideBid, ActionLeaveFeedback,
+		ActionViewMembers, ActionViewOrganization,
+	},
+	RoleViewer: {
+		ActionViewTender, ActionTenderHistory, ActionViewBid, ActionViewMembers, ActionViewOrganization,
+	},
+}
+
+// ValidRole reports whether role exists
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RoleAllows reports whether role permits action
+func RoleAllows(role, action string) bool {
+	return slices.Contains(rolePermissions[role], action)
+}
+
+// RolesAllowedTo returns roles which permit action
+func RolesAllowedTo(action string) []string {
+	roles := make([]string, 0, len(rolePermissions))
+	for role, actions := range rolePermissions {
+		if slices.Contains(actions, action) {
+			roles = append(roles, role)
+		}
+	}
+	slices.Sort(roles)
+
+	return roles
+}
@@ -0,0 +1,68 @@
+// Package domain holds business rules which do not depend
+// on the transport or on the storage
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	ErrInvalidStatus     = errors.New("invalid status")
+	ErrIllegalTransition = errors.New("illegal status transition")
+	ErrTenderClosed      = errors.New("tender does not accept bids")
+)
+
+// Statuses of tender from tenderStatus enum of the API
+const (
+	TenderCreated   = "Created"
+	TenderPublished = "Published"
+	TenderClosed    = "Closed"
+	TenderCanceled  = "Canceled"
+)
+
+// tenderTransitions lists statuses reachable from every status,
+// Closed and Canceled are final
+var tenderTransitions = map[string][]string{
+	TenderCreated:   {TenderPublished, TenderCanceled},
+	TenderPublished: {TenderClosed, TenderCanceled},
+	TenderClosed:    {},
+	TenderCanceled:  {},
+}
+
+// ValidTenderStatus reports whether status belongs to tenderStatus enum
+func ValidTenderStatus(status string) bool {
+	_, ok := tenderTransitions[status]
+	return ok
+}
+
+// TenderTransition returns ErrInvalidStatus if to is not a tender status
+// and ErrIllegalTransition if tender cannot move from one status to
+// another. Moving to the same status is allowed and changes nothing
+func TenderTransition(from, to string) error {
+	if !ValidTenderStatus(to) {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, to)
+	}
+	if from == to {
+		return nil
+	}
+	if !slices.Contains(tenderTransitions[from], to) {
+		return fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, from, to)
+	}
+
+	return nil
+}
+
+// TenderPublic reports whether tender with the given status is visible
+// to everyone, other tenders are visible only to responsibles of the
+// organization which owns them
+func TenderPublic(status string) bool {
+	return status == TenderPublished
+}
+
+// TenderAcceptsBids reports whether new bids and decisions
+// can be made for tender with the given status
+func TenderAcceptsBids(status string) bool {
+	return status == TenderPublished
+}
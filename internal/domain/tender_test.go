@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTenderTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		err      error
+	}{
+		{TenderCreated, TenderCreated, nil},
+		{TenderCreated, TenderPublished, nil},
+		{TenderCreated, TenderCanceled, nil},
+		{TenderCreated, TenderClosed, ErrIllegalTransition},
+		{TenderPublished, TenderCreated, ErrIllegalTransition},
+		{TenderPublished, TenderClosed, nil},
+		{TenderPublished, TenderCanceled, nil},
+		{TenderClosed, TenderPublished, ErrIllegalTransition},
+		{TenderClosed, TenderClosed, nil},
+		{TenderCanceled, TenderPublished, ErrIllegalTransition},
+		{TenderCanceled, TenderCreated, ErrIllegalTransition},
+		{TenderPublished, "Archived", ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			if err := TenderTransition(tt.from, tt.to); !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
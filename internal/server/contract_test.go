@@ -0,0 +1,647 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"zadanie-6105/api"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/config"
+	authmw "zadanie-6105/internal/server/middleware/auth"
+	"zadanie-6105/internal/server/middleware/validation"
+	"zadanie-6105/internal/storage/memory"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gorilla/mux"
+)
+
+// unknownID is a well formed id which belongs to no entity
+const unknownID = "00000000-0000-0000-0000-000000000000"
+
+// mailbox keeps password reset tokens instead of sending them
+type mailbox map[string]string
+
+func (m mailbox) NotifyPasswordReset(ctx context.Context, p auth.Principal, token string, expiresAt time.Time) error {
+	m[p.Username] = token
+	return nil
+}
+
+// contract serves router built by LoadRoutes over memory storage the
+// same way main does, checks every response against the spec and
+// records which statuses each operation of the spec returned
+type contract struct {
+	doc     *openapi3.T
+	handler http.Handler
+	router  routers.Router
+	covered map[string]map[int]bool
+}
+
+func newContract(t *testing.T, st *memory.Storage, notifier auth.ResetNotifier, admins ...string) *contract {
+	t.Helper()
+
+	doc, err := api.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Swagger UI page is described as string
+	openapi3filter.RegisterBodyDecoder("text/html", openapi3filter.RegisteredBodyDecoder("text/plain"))
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	validator, err := validation.New(log, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Config{
+		AUTH_LEGACY_USERNAME:    true,
+		AUTH_MAX_LOGIN_ATTEMPTS: 3,
+		AUTH_LOCKOUT_DURATION:   time.Minute,
+		AUTH_RESET_TOKEN_TTL:    time.Hour,
+		ADMIN_USERNAMES:         admins,
+	}
+	tokens := auth.NewTokens([]byte("secret"), time.Hour)
+
+	r := mux.NewRouter()
+	apiRouter := r.PathPrefix("/api").Subrouter()
+	apiRouter.Use(validator, authmw.New(log, tokens, nil, st, cfg.AUTH_LEGACY_USERNAME))
+	LoadRoutes(apiRouter, st, tokens, notifier, doc, cfg)
+
+	return &contract{
+		doc:     doc,
+		handler: r,
+		router:  router,
+		covered: make(map[string]map[int]bool),
+	}
+}
+
+// request is a call of operation. Authorization is sent as is,
+// organization goes to X-Organization-Id header
+type request struct {
+	method        string
+	path          string
+	body          string
+	authorization string
+	organization  string
+}
+
+// do sends request and fails test if response status differs from status
+// or if status or body of response is not described by the operation
+func (c *contract) do(t *testing.T, req request, status int) []byte {
+	t.Helper()
+
+	r := httptest.NewRequest(req.method, req.path, strings.NewReader(req.body))
+	if req.body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	if req.authorization != "" {
+		r.Header.Set("Authorization", req.authorization)
+	}
+	if req.organization != "" {
+		r.Header.Set("X-Organization-Id", req.organization)
+	}
+
+	w := httptest.NewRecorder()
+	c.handler.ServeHTTP(w, r)
+	body := w.Body.Bytes()
+
+	call := req.method + " " + req.path
+	if w.Code != status {
+		t.Fatalf("%s: expected status %d, got %d: %s", call, status, w.Code, body)
+	}
+
+	route, pathParams, err := c.router.FindRoute(r)
+	if err != nil {
+		t.Fatalf("%s: operation is not described in spec: %s", call, err)
+	}
+	err = openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+		},
+		Status:  w.Code,
+		Header:  w.Header(),
+		Body:    io.NopCloser(bytes.NewReader(body)),
+		Options: &openapi3filter.Options{IncludeResponseStatus: true},
+	})
+	if err != nil {
+		t.Fatalf("%s: response does not match spec: %s\n%s", call, err, body)
+	}
+
+	operationID := route.Operation.OperationID
+	if c.covered[operationID] == nil {
+		c.covered[operationID] = make(map[int]bool)
+	}
+	c.covered[operationID][w.Code] = true
+
+	return body
+}
+
+// checkCoverage fails test for every operation of the spec which was
+// never called with valid input, or never called with invalid input
+// although the spec documents client errors for it
+func (c *contract) checkCoverage(t *testing.T) {
+	t.Helper()
+
+	var missing []string
+	for path, item := range c.doc.Paths.Map() {
+		for method, op := range item.Operations() {
+			var success, clientError, documentsClientError bool
+			for status := range c.covered[op.OperationID] {
+				success = success || status < 300
+				clientError = clientError || status >= 400 && status < 500
+			}
+			for code := range op.Responses.Map() {
+				documentsClientError = documentsClientError || strings.HasPrefix(code, "4")
+			}
+
+			if !success {
+				missing = append(missing, fmt.Sprintf("%s %s (%s): no valid call", method, path, op.OperationID))
+			}
+			if documentsClientError && !clientError {
+				missing = append(missing, fmt.Sprintf("%s %s (%s): no invalid call", method, path, op.OperationID))
+			}
+		}
+	}
+
+	sort.Strings(missing)
+	for _, m := range missing {
+		t.Error(m)
+	}
+}
+
+// field returns string field of JSON object in body
+func field(t *testing.T, body []byte, name string) string {
+	t.Helper()
+
+	var object map[string]any
+	if err := json.Unmarshal(body, &object); err != nil {
+		t.Fatalf("cannot decode %s: %s", body, err)
+	}
+	value, ok := object[name].(string)
+	if !ok {
+		t.Fatalf("no string field %q in %s", name, body)
+	}
+
+	return value
+}
+
+// TestContract calls every operation of api/openapi.yml through the
+// router with valid and invalid input and checks that statuses and
+// bodies of responses match the spec. Steps share storage and go in
+// order: later steps use entities created by earlier ones
+func TestContract(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+	admin := "admin"
+	st.AddEmployee(admin, "", "")
+	ownerID := st.AddEmployee("owner", "Ivan", "Petrov")
+	bidderID := st.AddEmployee("bidder", "", "")
+	st.AddEmployee("stranger", "", "")
+	st.AddEmployee("newbie", "", "")
+	acme := st.AddOrganization("acme", "", "LLC")
+	st.AddResponsible(acme, ownerID)
+	bidco := st.AddOrganization("bidco", "", "IE")
+	st.AddResponsible(bidco, bidderID)
+
+	resets := mailbox{}
+	c := newContract(t, st, resets, admin)
+
+	// every employee signs in with password, calls are made
+	// with the issued token unless legacy auth is tested
+	bearers := make(map[string]string)
+	for _, username := range []string{admin, "owner", "bidder", "stranger"} {
+		userID, err := st.GetUserID(ctx, username)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash, err := auth.HashPassword(username + " password")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := st.SetPassword(ctx, userID, hash); err != nil {
+			t.Fatal(err)
+		}
+
+		login := fmt.Sprintf(`{"username":%q,"password":%q}`, username, username+" password")
+		body := c.do(t, request{method: http.MethodPost, path: "/api/auth/login", body: login}, http.StatusOK)
+		bearers[username] = "Bearer " + field(t, body, "token")
+	}
+	as := func(username string) string {
+		return bearers[username]
+	}
+
+	t.Run("service", func(t *testing.T) {
+		c.do(t, request{method: http.MethodGet, path: "/api/ping"}, http.StatusOK)
+		c.do(t, request{method: http.MethodGet, path: "/api/openapi.yaml"}, http.StatusOK)
+		c.do(t, request{method: http.MethodGet, path: "/api/openapi.json"}, http.StatusOK)
+		c.do(t, request{method: http.MethodGet, path: "/api/docs/"}, http.StatusOK)
+	})
+
+	t.Run("auth", func(t *testing.T) {
+		login := func(body string, status int) []byte {
+			return c.do(t, request{method: http.MethodPost, path: "/api/auth/login", body: body}, status)
+		}
+		login(`{"username":"ghost"}`, http.StatusUnauthorized)
+		login(`{"password":"secret password"}`, http.StatusBadRequest)
+
+		password := func(body, authorization string, status int) {
+			c.do(t, request{method: http.MethodPut, path: "/api/auth/password", body: body, authorization: authorization}, status)
+		}
+		password(`{"currentPassword":"stranger password","newPassword":"secret password"}`, as("stranger"), http.StatusOK)
+		// changed password revokes tokens issued before
+		password(`{"currentPassword":"secret password","newPassword":"other password"}`, as("stranger"), http.StatusUnauthorized)
+		body := login(`{"username":"stranger","password":"secret password"}`, http.StatusOK)
+		bearers["stranger"] = "Bearer " + field(t, body, "token")
+		password(`{"currentPassword":"wrong password","newPassword":"other password"}`, as("stranger"), http.StatusForbidden)
+		password(`{"newPassword":"short"}`, as("stranger"), http.StatusBadRequest)
+		password(`{"newPassword":"other password"}`, "", http.StatusUnauthorized)
+
+		login(`{"username":"stranger","password":"wrong password"}`, http.StatusUnauthorized)
+		login(`{"username":"stranger","password":"wrong password"}`, http.StatusUnauthorized)
+		login(`{"username":"stranger","password":"wrong password"}`, http.StatusTooManyRequests)
+		password(`{"currentPassword":"secret password","newPassword":"other password"}`, as("stranger"), http.StatusTooManyRequests)
+
+		reset := func(body string, status int) {
+			c.do(t, request{method: http.MethodPost, path: "/api/auth/password/reset", body: body}, status)
+		}
+		reset(`{"username":"stranger"}`, http.StatusAccepted)
+		reset(`{"username":"ghost"}`, http.StatusAccepted)
+		reset(`{}`, http.StatusBadRequest)
+
+		confirm := fmt.Sprintf(`{"token":%q,"newPassword":"new secret password"}`, resets["stranger"])
+		c.do(t, request{method: http.MethodPost, path: "/api/auth/password/reset/confirm", body: confirm}, http.StatusOK)
+		c.do(t, request{method: http.MethodPost, path: "/api/auth/password/reset/confirm", body: confirm}, http.StatusBadRequest)
+		// reset revokes tokens issued before as well
+		c.do(t, request{method: http.MethodGet, path: "/api/organizations/my", authorization: as("stranger")}, http.StatusUnauthorized)
+		body = login(`{"username":"stranger","password":"new secret password"}`, http.StatusOK)
+		bearers["stranger"] = "Bearer " + field(t, body, "token")
+	})
+
+	t.Run("employees", func(t *testing.T) {
+		c.do(t, request{method: http.MethodGet, path: "/api/employees", authorization: as("owner")}, http.StatusOK)
+		c.do(t, request{method: http.MethodGet, path: "/api/employees?limit=51", authorization: as("owner")}, http.StatusBadRequest)
+		c.do(t, request{method: http.MethodGet, path: "/api/employees"}, http.StatusUnauthorized)
+
+		create := func(authorization, body string, status int) {
+			c.do(t, request{method: http.MethodPost, path: "/api/employees/new", body: body, authorization: authorization}, status)
+		}
+		create(as(admin), `{"username":"newcomer","firstName":"Anna"}`, http.StatusOK)
+		create(as(admin), `{"username":"newcomer"}`, http.StatusConflict)
+		create(as(admin), `{"username":"New Comer"}`, http.StatusBadRequest)
+		create(as("owner"), `{"username":"another"}`, http.StatusForbidden)
+		create("", `{"username":"another"}`, http.StatusUnauthorized)
+
+		get := func(authorization, username string, status int) {
+			c.do(t, request{method: http.MethodGet, path: "/api/employees/" + username, authorization: authorization}, status)
+		}
+		get(as("bidder"), "owner", http.StatusOK)
+		get(as("bidder"), "ghost", http.StatusNotFound)
+		get("", "owner", http.StatusUnauthorized)
+
+		edit := func(authorization, username, body string, status int) {
+			c.do(t, request{method: http.MethodPatch, path: "/api/employees/" + username + "/edit", body: body, authorization: authorization}, status)
+		}
+		edit(as("owner"), "owner", `{"firstName":"Pavel","lastName":null}`, http.StatusOK)
+		edit(as("owner"), "bidder", `{"firstName":"Pavel"}`, http.StatusForbidden)
+		edit(as(admin), "ghost", `{"firstName":"Pavel"}`, http.StatusNotFound)
+		edit(as("owner"), "owner", `{"username":"pavel"}`, http.StatusBadRequest)
+		edit("", "owner", `{"firstName":"Pavel"}`, http.StatusUnauthorized)
+
+		deactivate := func(authorization, username string, status int) {
+			c.do(t, request{method: http.MethodPut, path: "/api/employees/" + username + "/deactivate", authorization: authorization}, status)
+		}
+		deactivate(as("owner"), "newcomer", http.StatusForbidden)
+		deactivate(as(admin), "newcomer", http.StatusOK)
+		deactivate(as(admin), "newcomer", http.StatusConflict)
+		deactivate(as(admin), "owner", http.StatusConflict)
+		deactivate(as(admin), "ghost", http.StatusNotFound)
+		deactivate("", "newcomer", http.StatusUnauthorized)
+
+		edit(as(admin), "newcomer", `{"firstName":"Anna"}`, http.StatusConflict)
+	})
+
+	var holding string
+	t.Run("organizations", func(t *testing.T) {
+		create := func(authorization, body string, status int) []byte {
+			return c.do(t, request{method: http.MethodPost, path: "/api/organizations/new", body: body, authorization: authorization}, status)
+		}
+		holding = field(t, create(as("owner"), `{"name":"acme holding","type":"JSC"}`, http.StatusOK), "id")
+		create(as("owner"), `{"name":"acme holding","type":"Trust"}`, http.StatusBadRequest)
+		create("", `{"name":"acme","type":"JSC"}`, http.StatusUnauthorized)
+
+		c.do(t, request{method: http.MethodGet, path: "/api/organizations/my", authorization: as("owner")}, http.StatusOK)
+		c.do(t, request{method: http.MethodGet, path: "/api/organizations/my"}, http.StatusUnauthorized)
+
+		get := func(authorization, id string, status int) {
+			c.do(t, request{method: http.MethodGet, path: "/api/organizations/" + id, authorization: authorization}, status)
+		}
+		get(as("owner"), acme, http.StatusOK)
+		get(as("stranger"), acme, http.StatusForbidden)
+		get(as("owner"), strings.Repeat("a", 101), http.StatusBadRequest)
+		get("", acme, http.StatusUnauthorized)
+
+		edit := func(authorization, body string, status int) {
+			c.do(t, request{method: http.MethodPatch, path: "/api/organizations/" + acme + "/edit", body: body, authorization: authorization}, status)
+		}
+		edit(as("owner"), `{"description":"Поставки оборудования"}`, http.StatusOK)
+		edit(as("owner"), `{"type":null}`, http.StatusBadRequest)
+		edit(as("stranger"), `{"description":""}`, http.StatusForbidden)
+		edit("", `{"name":"x"}`, http.StatusUnauthorized)
+
+		members := func(authorization, id string, status int) {
+			c.do(t, request{method: http.MethodGet, path: "/api/organizations/" + id + "/members", authorization: authorization}, status)
+		}
+		members(as("owner"), acme, http.StatusOK)
+		members(as("stranger"), acme, http.StatusForbidden)
+		members(as("owner"), strings.Repeat("a", 101), http.StatusBadRequest)
+		members("", acme, http.StatusUnauthorized)
+
+		grant := func(authorization, member, role string, status int) {
+			path := fmt.Sprintf("/api/organizations/%s/members/%s/grant?role=%s", acme, member, role)
+			c.do(t, request{method: http.MethodPut, path: path, authorization: authorization}, status)
+		}
+		grant(as("owner"), "stranger", "viewer", http.StatusOK)
+		grant(as("owner"), "stranger", "king", http.StatusBadRequest)
+		grant(as("owner"), "ghost", "viewer", http.StatusNotFound)
+		grant(as("stranger"), "bidder", "viewer", http.StatusForbidden)
+		grant(as("owner"), "owner", "viewer", http.StatusConflict)
+		grant("", "stranger", "viewer", http.StatusUnauthorized)
+
+		revoke := func(authorization, id, member string, status int) {
+			path := fmt.Sprintf("/api/organizations/%s/members/%s/revoke", id, member)
+			c.do(t, request{method: http.MethodPut, path: path, authorization: authorization}, status)
+		}
+		revoke(as("stranger"), acme, "owner", http.StatusForbidden)
+		revoke(as("owner"), acme, "stranger", http.StatusOK)
+		revoke(as("owner"), acme, "stranger", http.StatusNotFound)
+		revoke(as("owner"), acme, "owner", http.StatusConflict)
+		revoke(as("owner"), strings.Repeat("a", 101), "owner", http.StatusBadRequest)
+		revoke("", acme, "owner", http.StatusUnauthorized)
+	})
+
+	var apiKey string
+	t.Run("api keys", func(t *testing.T) {
+		call := func(method, path, authorization, body string, status int) []byte {
+			return c.do(t, request{method: method, path: path, body: body, authorization: authorization, organization: acme}, status)
+		}
+
+		created := call(http.MethodPost, "/api/api_keys/new", as("owner"), `{"name":"ci","scopes":["tenders:read","bids:decide"]}`, http.StatusOK)
+		keyID := field(t, created, "id")
+		call(http.MethodPost, "/api/api_keys/new", as("owner"), `{"name":"ci","scopes":[]}`, http.StatusBadRequest)
+		call(http.MethodPost, "/api/api_keys/new", as("bidder"), `{"name":"ci","scopes":["tenders:read"]}`, http.StatusForbidden)
+		call(http.MethodPost, "/api/api_keys/new", "", `{"name":"ci","scopes":["tenders:read"]}`, http.StatusUnauthorized)
+
+		call(http.MethodGet, "/api/api_keys", as("owner"), "", http.StatusOK)
+		call(http.MethodGet, "/api/api_keys", as("bidder"), "", http.StatusForbidden)
+		call(http.MethodGet, "/api/api_keys?limit=51", as("owner"), "", http.StatusBadRequest)
+		call(http.MethodGet, "/api/api_keys", "", "", http.StatusUnauthorized)
+
+		apiKey = field(t, call(http.MethodPut, "/api/api_keys/"+keyID+"/rotate", as("owner"), "", http.StatusOK), "key")
+		call(http.MethodPut, "/api/api_keys/"+unknownID+"/rotate", as("owner"), "", http.StatusNotFound)
+		call(http.MethodPut, "/api/api_keys/"+keyID+"/rotate", as("bidder"), "", http.StatusForbidden)
+		call(http.MethodPut, "/api/api_keys/"+strings.Repeat("a", 101)+"/rotate", as("owner"), "", http.StatusBadRequest)
+		call(http.MethodPut, "/api/api_keys/"+keyID+"/rotate", "", "", http.StatusUnauthorized)
+
+		revoked := field(t, call(http.MethodPost, "/api/api_keys/new", as("owner"), `{"name":"old","scopes":["tenders:read"]}`, http.StatusOK), "id")
+		call(http.MethodPut, "/api/api_keys/"+revoked+"/revoke", as("owner"), "", http.StatusOK)
+		call(http.MethodPut, "/api/api_keys/"+unknownID+"/revoke", as("owner"), "", http.StatusNotFound)
+		call(http.MethodPut, "/api/api_keys/"+keyID+"/revoke", as("bidder"), "", http.StatusForbidden)
+		call(http.MethodPut, "/api/api_keys/"+strings.Repeat("a", 101)+"/revoke", as("owner"), "", http.StatusBadRequest)
+		call(http.MethodPut, "/api/api_keys/"+keyID+"/revoke", "", "", http.StatusUnauthorized)
+	})
+
+	// identity named by username param or by token issued without
+	// password is trusted only by operations which predate tokens
+	t.Run("legacy auth", func(t *testing.T) {
+		login := c.do(t, request{method: http.MethodPost, path: "/api/auth/login", body: `{"username":"newbie"}`}, http.StatusOK)
+		legacyToken := "Bearer " + field(t, login, "token")
+
+		c.do(t, request{method: http.MethodPut, path: "/api/auth/password", body: `{"newPassword":"stolen password"}`, authorization: legacyToken}, http.StatusUnauthorized)
+		c.do(t, request{method: http.MethodPut, path: "/api/auth/password?username=owner", body: `{"currentPassword":"x","newPassword":"stolen password"}`}, http.StatusUnauthorized)
+		c.do(t, request{method: http.MethodPost, path: "/api/employees/new?username=" + admin, body: `{"username":"intruder"}`}, http.StatusUnauthorized)
+		c.do(t, request{method: http.MethodPatch, path: "/api/employees/owner/edit?username=owner", body: `{"firstName":"Intruder"}`}, http.StatusUnauthorized)
+		c.do(t, request{method: http.MethodPut, path: "/api/employees/owner/deactivate?username=" + admin}, http.StatusUnauthorized)
+		c.do(t, request{method: http.MethodGet, path: "/api/api_keys?username=owner", organization: acme}, http.StatusUnauthorized)
+		c.do(t, request{method: http.MethodPost, path: "/api/api_keys/new?username=owner", body: `{"name":"stolen","scopes":["tenders:read"]}`, organization: acme}, http.StatusUnauthorized)
+		c.do(t, request{method: http.MethodGet, path: "/api/organizations/my?username=owner"}, http.StatusUnauthorized)
+
+		c.do(t, request{method: http.MethodGet, path: "/api/tenders/my?username=owner&organizationId=" + acme}, http.StatusOK)
+		c.do(t, request{method: http.MethodGet, path: "/api/bids/my", authorization: legacyToken}, http.StatusOK)
+	})
+
+	var tenderID string
+	t.Run("tenders", func(t *testing.T) {
+		newTender := fmt.Sprintf(`{"name":"Доставка","description":"Казань - Москва","serviceType":"Delivery","organizationId":%q}`, acme)
+		create := func(authorization, body string, status int) []byte {
+			return c.do(t, request{method: http.MethodPost, path: "/api/tenders/new", body: body, authorization: authorization}, status)
+		}
+		tenderID = field(t, create(as("owner"), newTender, http.StatusOK), "id")
+		create(as("bidder"), newTender, http.StatusForbidden)
+		create(as("owner"), strings.Replace(newTender, "Delivery", "Cooking", 1), http.StatusBadRequest)
+		create("", newTender, http.StatusUnauthorized)
+
+		status := func(authorization, id, status string, code int) {
+			path := fmt.Sprintf("/api/tenders/%s/status?status=%s", id, status)
+			c.do(t, request{method: http.MethodPut, path: path, authorization: authorization}, code)
+		}
+		status(as("owner"), tenderID, "Published", http.StatusOK)
+		status(as("owner"), tenderID, "Archived", http.StatusBadRequest)
+		status(as("bidder"), tenderID, "Closed", http.StatusForbidden)
+		status(as("owner"), unknownID, "Closed", http.StatusNotFound)
+		canceled := field(t, create(as("owner"), newTender, http.StatusOK), "id")
+		status(as("owner"), canceled, "Canceled", http.StatusOK)
+		status(as("owner"), canceled, "Published", http.StatusConflict)
+		status("", tenderID, "Closed", http.StatusUnauthorized)
+
+		getStatus := func(authorization, id string, code int) {
+			c.do(t, request{method: http.MethodGet, path: "/api/tenders/" + id + "/status", authorization: authorization}, code)
+		}
+		getStatus(as("bidder"), tenderID, http.StatusOK)
+		getStatus(as("bidder"), canceled, http.StatusForbidden)
+		getStatus(as("bidder"), unknownID, http.StatusNotFound)
+		getStatus(as("bidder"), strings.Repeat("a", 101), http.StatusBadRequest)
+		getStatus("Bearer forged", tenderID, http.StatusUnauthorized)
+
+		c.do(t, request{method: http.MethodGet, path: "/api/tenders?service_type=Delivery"}, http.StatusOK)
+		c.do(t, request{method: http.MethodGet, path: "/api/tenders", authorization: "ApiKey " + apiKey}, http.StatusOK)
+		c.do(t, request{method: http.MethodGet, path: "/api/tenders?limit=51"}, http.StatusBadRequest)
+
+		c.do(t, request{method: http.MethodGet, path: "/api/tenders/my?organizationId=" + acme, authorization: as("owner")}, http.StatusOK)
+		c.do(t, request{method: http.MethodGet, path: "/api/tenders/my?offset=-1", authorization: as("owner")}, http.StatusBadRequest)
+		c.do(t, request{method: http.MethodGet, path: "/api/tenders/my"}, http.StatusUnauthorized)
+
+		edit := func(authorization, id, body string, code int) {
+			c.do(t, request{method: http.MethodPatch, path: "/api/tenders/" + id + "/edit", body: body, authorization: authorization}, code)
+		}
+		edit(as("owner"), tenderID, `{"name":"Доставка оборудования","description":null}`, http.StatusOK)
+		edit(as("owner"), tenderID, `{"name":null}`, http.StatusBadRequest)
+		edit(as("bidder"), tenderID, `{"name":"Моя доставка"}`, http.StatusForbidden)
+		edit(as("owner"), unknownID, `{"name":"Доставка"}`, http.StatusNotFound)
+		edit("", tenderID, `{"name":"x"}`, http.StatusUnauthorized)
+
+		rollback := func(authorization, id, version string, code int) {
+			c.do(t, request{method: http.MethodPut, path: "/api/tenders/" + id + "/rollback/" + version, authorization: authorization}, code)
+		}
+		rollback(as("owner"), tenderID, "1", http.StatusOK)
+		rollback(as("owner"), tenderID, "0", http.StatusBadRequest)
+		rollback(as("owner"), tenderID, "99", http.StatusNotFound)
+		rollback(as("bidder"), tenderID, "1", http.StatusForbidden)
+		rollback("", tenderID, "1", http.StatusUnauthorized)
+
+		versions := func(authorization, path string, code int) {
+			c.do(t, request{method: http.MethodGet, path: "/api/tenders/" + path, authorization: authorization}, code)
+		}
+		versions(as("owner"), tenderID+"/versions", http.StatusOK)
+		versions("", tenderID+"/versions", http.StatusUnauthorized)
+		versions(as("bidder"), canceled+"/versions", http.StatusForbidden)
+		versions(as("owner"), unknownID+"/versions", http.StatusNotFound)
+		versions(as("owner"), tenderID+"/versions?limit=51", http.StatusBadRequest)
+
+		versions(as("owner"), tenderID+"/versions/1/diff/3", http.StatusOK)
+		versions(as("owner"), tenderID+"/versions/1/diff/99", http.StatusNotFound)
+		versions(as("owner"), tenderID+"/versions/0/diff/1", http.StatusBadRequest)
+		versions(as("bidder"), canceled+"/versions/1/diff/2", http.StatusForbidden)
+		versions("", tenderID+"/versions/1/diff/2", http.StatusUnauthorized)
+	})
+
+	t.Run("bids", func(t *testing.T) {
+		newBid := fmt.Sprintf(`{"name":"Доставим","description":"За два дня","tenderId":%q,"authorType":"User","authorId":%q}`, tenderID, bidderID)
+		create := func(authorization, body string, status int) []byte {
+			return c.do(t, request{method: http.MethodPost, path: "/api/bids/new", body: body, authorization: authorization}, status)
+		}
+		bidID := field(t, create(as("bidder"), newBid, http.StatusOK), "id")
+		create(as("owner"), newBid, http.StatusForbidden)
+		create(as("bidder"), strings.Replace(newBid, tenderID, unknownID, 1), http.StatusNotFound)
+		create(as("bidder"), strings.Replace(newBid, `"User"`, `"Robot"`, 1), http.StatusBadRequest)
+
+		c.do(t, request{method: http.MethodGet, path: "/api/bids/my", authorization: as("bidder")}, http.StatusOK)
+		c.do(t, request{method: http.MethodGet, path: "/api/bids/my?limit=51", authorization: as("bidder")}, http.StatusBadRequest)
+		c.do(t, request{method: http.MethodGet, path: "/api/bids/my"}, http.StatusUnauthorized)
+
+		status := func(authorization, id, status string, code int) {
+			c.do(t, request{method: http.MethodPut, path: "/api/bids/" + id + "/status?status=" + status, authorization: authorization}, code)
+		}
+		status(as("bidder"), bidID, "Published", http.StatusOK)
+		status(as("bidder"), bidID, "Approved", http.StatusBadRequest)
+		status(as("stranger"), bidID, "Canceled", http.StatusForbidden)
+		status(as("bidder"), unknownID, "Canceled", http.StatusNotFound)
+		canceled := field(t, create(as("bidder"), newBid, http.StatusOK), "id")
+		status(as("bidder"), canceled, "Canceled", http.StatusOK)
+		status(as("bidder"), canceled, "Published", http.StatusConflict)
+		status("", bidID, "Canceled", http.StatusUnauthorized)
+
+		get := func(authorization, path string, code int) {
+			c.do(t, request{method: http.MethodGet, path: "/api/bids/" + path, authorization: authorization, organization: acme}, code)
+		}
+		get(as("bidder"), bidID+"/status", http.StatusOK)
+		get(as("stranger"), bidID+"/status", http.StatusForbidden)
+		get(as("bidder"), unknownID+"/status", http.StatusNotFound)
+		get(as("bidder"), strings.Repeat("a", 101)+"/status", http.StatusBadRequest)
+		get("", bidID+"/status", http.StatusUnauthorized)
+
+		get(as("owner"), tenderID+"/list", http.StatusOK)
+		get(as("stranger"), tenderID+"/list", http.StatusForbidden)
+		get(as("owner"), unknownID+"/list", http.StatusNotFound)
+		get(as("owner"), tenderID+"/list?limit=51", http.StatusBadRequest)
+		get("", tenderID+"/list", http.StatusUnauthorized)
+
+		edit := func(authorization, id, body string, code int) {
+			c.do(t, request{method: http.MethodPatch, path: "/api/bids/" + id + "/edit", body: body, authorization: authorization}, code)
+		}
+		edit(as("bidder"), bidID, `{"description":"За один день"}`, http.StatusOK)
+		edit(as("bidder"), bidID, `{"name":null}`, http.StatusBadRequest)
+		edit(as("owner"), bidID, `{"name":"Наше предложение"}`, http.StatusForbidden)
+		edit(as("bidder"), unknownID, `{"name":"Доставим"}`, http.StatusNotFound)
+		edit(as("bidder"), canceled, `{"name":"Доставим"}`, http.StatusConflict)
+		edit("", bidID, `{"name":"x"}`, http.StatusUnauthorized)
+
+		rollback := func(authorization, id, version string, code int) {
+			c.do(t, request{method: http.MethodPut, path: "/api/bids/" + id + "/rollback/" + version, authorization: authorization}, code)
+		}
+		rollback(as("bidder"), bidID, "1", http.StatusOK)
+		rollback(as("bidder"), bidID, "0", http.StatusBadRequest)
+		rollback(as("bidder"), bidID, "99", http.StatusNotFound)
+		rollback(as("owner"), bidID, "1", http.StatusForbidden)
+		rollback(as("bidder"), canceled, "1", http.StatusConflict)
+		rollback("", bidID, "1", http.StatusUnauthorized)
+
+		get(as("bidder"), bidID+"/versions", http.StatusOK)
+		get(as("stranger"), bidID+"/versions", http.StatusForbidden)
+		get(as("bidder"), unknownID+"/versions", http.StatusNotFound)
+		get(as("bidder"), bidID+"/versions?limit=51", http.StatusBadRequest)
+		get("", bidID+"/versions", http.StatusUnauthorized)
+
+		get(as("bidder"), bidID+"/versions/1/diff/3", http.StatusOK)
+		get(as("bidder"), bidID+"/versions/1/diff/99", http.StatusNotFound)
+		get(as("bidder"), bidID+"/versions/0/diff/1", http.StatusBadRequest)
+		get(as("stranger"), bidID+"/versions/1/diff/2", http.StatusForbidden)
+		get("", bidID+"/versions/1/diff/2", http.StatusUnauthorized)
+
+		feedback := func(authorization, id, text string, code int) {
+			c.do(t, request{method: http.MethodPut, path: "/api/bids/" + id + "/feedback?bidFeedback=" + text, authorization: authorization}, code)
+		}
+		feedback(as("owner"), bidID, "good", http.StatusOK)
+		feedback(as("owner"), bidID, strings.Repeat("a", 1001), http.StatusBadRequest)
+		feedback(as("stranger"), bidID, "good", http.StatusForbidden)
+		feedback(as("owner"), unknownID, "good", http.StatusNotFound)
+		feedback("", bidID, "good", http.StatusUnauthorized)
+
+		reviews := func(authorization, query, id string, code int) {
+			c.do(t, request{method: http.MethodGet, path: "/api/bids/" + id + "/reviews?" + query, authorization: authorization}, code)
+		}
+		reviews(as("owner"), "authorUsername=bidder", tenderID, http.StatusOK)
+		reviews(as("owner"), "", tenderID, http.StatusBadRequest)
+		reviews(as("stranger"), "authorUsername=bidder", tenderID, http.StatusForbidden)
+		reviews(as("owner"), "authorUsername=bidder", unknownID, http.StatusNotFound)
+		reviews("", "authorUsername=bidder", tenderID, http.StatusUnauthorized)
+
+		decide := func(authorization, decision, id string, code int) {
+			c.do(t, request{method: http.MethodPut, path: "/api/bids/" + id + "/submit_decision?decision=" + decision, authorization: authorization}, code)
+		}
+		decide(as("owner"), "Maybe", bidID, http.StatusBadRequest)
+		decide(as("bidder"), "Approved", bidID, http.StatusForbidden)
+		decide(as("owner"), "Approved", unknownID, http.StatusNotFound)
+		decide("", "Approved", bidID, http.StatusUnauthorized)
+		decide("ApiKey "+apiKey, "Approved", bidID, http.StatusOK)
+		decide(as("owner"), "Rejected", bidID, http.StatusConflict)
+
+		create(as("bidder"), newBid, http.StatusConflict)
+	})
+
+	t.Run("organization deletion", func(t *testing.T) {
+		remove := func(authorization, id string, status int) {
+			c.do(t, request{method: http.MethodDelete, path: "/api/organizations/" + id, authorization: authorization}, status)
+		}
+		remove(as("stranger"), holding, http.StatusForbidden)
+		remove(as("owner"), bidco, http.StatusForbidden)
+		remove(as("owner"), strings.Repeat("a", 101), http.StatusBadRequest)
+		remove(as("owner"), holding, http.StatusOK)
+		remove("", acme, http.StatusUnauthorized)
+		// closed tender keeps approved bid of bidco
+		remove(as("owner"), acme, http.StatusConflict)
+
+		bidcoTender := fmt.Sprintf(`{"name":"Ремонт","description":"","serviceType":"Construction","organizationId":%q}`, bidco)
+		bidcoTenderID := field(t, c.do(t, request{method: http.MethodPost, path: "/api/tenders/new", body: bidcoTender, authorization: as("bidder")}, http.StatusOK), "id")
+		c.do(t, request{method: http.MethodPut, path: "/api/tenders/" + bidcoTenderID + "/status?status=Published", authorization: as("bidder")}, http.StatusOK)
+		remove(as("bidder"), bidco, http.StatusConflict)
+	})
+
+	c.checkCoverage(t)
+}
@@ -0,0 +1,297 @@
+package accounts
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/config"
+	"zadanie-6105/internal/server/handlers"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+const (
+	contentType = "Content-Type"
+	appJSON     = "application/json"
+)
+
+type AccountsHandler struct {
+	Storage          storage.Storage
+	Tokens           *auth.Tokens
+	Notifier         auth.ResetNotifier
+	Legacy           bool
+	MaxLoginAttempts int
+	LockoutDuration  time.Duration
+	ResetTokenTTL    time.Duration
+	Admins           map[string]bool
+}
+
+func New(storage storage.Storage, tokens *auth.Tokens, notifier auth.ResetNotifier, cfg config.Config) *AccountsHandler {
+	admins := make(map[string]bool, len(cfg.ADMIN_USERNAMES))
+	for _, username := range cfg.ADMIN_USERNAMES {
+		admins[username] = true
+	}
+
+	return &AccountsHandler{
+		Storage:          storage,
+		Tokens:           tokens,
+		Notifier:         notifier,
+		Legacy:           cfg.AUTH_LEGACY_USERNAME,
+		MaxLoginAttempts: cfg.AUTH_MAX_LOGIN_ATTEMPTS,
+		LockoutDuration:  cfg.AUTH_LOCKOUT_DURATION,
+		ResetTokenTTL:    cfg.AUTH_RESET_TOKEN_TTL,
+		Admins:           admins,
+	}
+}
+
+// Вход сотрудника по паролю, возвращает токен для заголовка Authorization.
+// Сотрудник без пароля может войти только в режиме совместимости
+// AUTH_LEGACY_USERNAME, такой токен не позволяет управлять учетными записями
+func (h *AccountsHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var login models.LoginRequest
+	err := json.NewDecoder(r.Body).Decode(&login)
+	if err != nil || login.Username == "" {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+		return
+	}
+
+	userID, err := h.Storage.GetUserID(r.Context(), login.Username)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if userID == "" {
+		handlers.ReturnErrorResponse(http.StatusUnauthorized, "Неверное имя пользователя или пароль.", w)
+		return
+	}
+
+	p := auth.Principal{UserID: userID, Username: login.Username}
+	credentials, err := h.Storage.GetCredentials(r.Context(), userID)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		if !h.Legacy {
+			handlers.ReturnErrorResponse(http.StatusUnauthorized, "Неверное имя пользователя или пароль.", w)
+			return
+		}
+		p.Legacy = true
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	default:
+		err = h.checkPassword(r.Context(), credentials, login.Password)
+		switch {
+		case errors.Is(err, errLocked):
+			handlers.ReturnErrorResponse(http.StatusTooManyRequests, "Слишком много неудачных попыток, учетная запись временно заблокирована.", w)
+			return
+		case errors.Is(err, errWrongPassword):
+			handlers.ReturnErrorResponse(http.StatusUnauthorized, "Неверное имя пользователя или пароль.", w)
+			return
+		case err != nil:
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		p.CredentialsVersion = credentials.Version
+	}
+
+	token, expiresAt, err := h.Tokens.Issue(p)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.TokenResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// Установка или смена пароля текущего пользователя. Если пароль
+// уже установлен, требуется текущий пароль. Недоступна пользователю,
+// чья личность не подтверждена паролем или SSO. Выданные ранее токены,
+// включая текущий, перестают действовать
+func (h *AccountsHandler) ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	p, ok := handlers.VerifiedPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	var change models.ChangePasswordRequest
+	err := json.NewDecoder(r.Body).Decode(&change)
+	if err != nil || models.ValidatePassword(change.NewPassword) != nil {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+		return
+	}
+
+	credentials, err := h.Storage.GetCredentials(r.Context(), p.UserID)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	default:
+		err = h.checkPassword(r.Context(), credentials, change.CurrentPassword)
+		switch {
+		case errors.Is(err, errLocked):
+			handlers.ReturnErrorResponse(http.StatusTooManyRequests, "Слишком много неудачных попыток, учетная запись временно заблокирована.", w)
+			return
+		case errors.Is(err, errWrongPassword):
+			handlers.ReturnErrorResponse(http.StatusForbidden, "Неверный текущий пароль.", w)
+			return
+		case err != nil:
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	hash, err := auth.HashPassword(change.NewPassword)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = h.Storage.SetPassword(r.Context(), p.UserID, hash)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Запрос сброса пароля. Токен сброса отправляется сотруднику через
+// Notifier, ответ не зависит от того, существует ли сотрудник
+func (h *AccountsHandler) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var reset models.PasswordResetRequest
+	err := json.NewDecoder(r.Body).Decode(&reset)
+	if err != nil || reset.Username == "" {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+		return
+	}
+
+	userID, err := h.Storage.GetUserID(r.Context(), reset.Username)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if userID == "" {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	token, tokenHash, err := auth.NewResetToken()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = h.Storage.CreatePasswordReset(r.Context(), userID, tokenHash, h.ResetTokenTTL)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	p := auth.Principal{UserID: userID, Username: reset.Username}
+	err = h.Notifier.NotifyPasswordReset(r.Context(), p, token, time.Now().Add(h.ResetTokenTTL))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Установка нового пароля по токену сброса, токен одноразовый.
+// Выданные ранее токены сотрудника перестают действовать
+func (h *AccountsHandler) ConfirmResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var confirm models.PasswordResetConfirmRequest
+	err := json.NewDecoder(r.Body).Decode(&confirm)
+	if err != nil || confirm.Token == "" || models.ValidatePassword(confirm.NewPassword) != nil {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+		return
+	}
+
+	hash, err := auth.HashPassword(confirm.NewPassword)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	_, err = h.Storage.ResetPassword(r.Context(), auth.HashResetToken(confirm.Token), hash)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			handlers.ReturnErrorResponse(http.StatusBadRequest, "Токен сброса недействителен или истек.", w)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Создание сотрудника, доступно только администраторам из ADMIN_USERNAMES
+func (h *AccountsHandler) NewEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	p, ok := handlers.VerifiedPrincipal(w, r)
+	if !ok {
+		return
+	}
+	if !h.admin(p) {
+		handlers.ReturnErrorResponse(http.StatusForbidden, "Недостаточно прав для выполнения действия.", w)
+		return
+	}
+
+	var newEmployee models.NewEmployeeRequest
+	err := json.NewDecoder(r.Body).Decode(&newEmployee)
+	if err != nil || newEmployee.Validate() != nil {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+		return
+	}
+
+	userID, err := h.Storage.GetUserID(r.Context(), newEmployee.Username)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if userID != "" {
+		handlers.ReturnErrorResponse(http.StatusConflict, "Пользователь с таким именем уже существует.", w)
+		return
+	}
+
+	var hash string
+	if newEmployee.Password != "" {
+		hash, err = auth.HashPassword(newEmployee.Password)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	employee, err := h.Storage.InsertEmployee(r.Context(), newEmployee, hash)
+	if err != nil {
+		// username was taken after the check above
+		if errors.Is(err, storage.ErrAlreadyExists) {
+			handlers.ReturnErrorResponse(http.StatusConflict, "Пользователь с таким именем уже существует.", w)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(employee)
+}
@@ -0,0 +1,206 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/config"
+	authmw "zadanie-6105/internal/server/middleware/auth"
+	"zadanie-6105/internal/storage/memory"
+	"zadanie-6105/internal/storage/models"
+
+	"github.com/gorilla/mux"
+)
+
+type discard struct{}
+
+func (discard) NotifyPasswordReset(ctx context.Context, p auth.Principal, token string, expiresAt time.Time) error {
+	return nil
+}
+
+func newRouter(st *memory.Storage, tokens *auth.Tokens, legacy bool) *mux.Router {
+	h := New(st, tokens, discard{}, config.Config{
+		AUTH_LEGACY_USERNAME:    legacy,
+		AUTH_MAX_LOGIN_ATTEMPTS: 3,
+		AUTH_LOCKOUT_DURATION:   time.Minute,
+		AUTH_RESET_TOKEN_TTL:    time.Hour,
+		ADMIN_USERNAMES:         []string{"admin"},
+	})
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	r := mux.NewRouter()
+	r.Use(authmw.New(log, tokens, nil, st, legacy))
+	r.HandleFunc("/auth/login", h.LoginHandler).Methods(http.MethodPost)
+	r.HandleFunc("/auth/password", h.ChangePasswordHandler).Methods(http.MethodPut)
+	r.HandleFunc("/employees/new", h.NewEmployeeHandler).Methods(http.MethodPost)
+	r.HandleFunc("/employees/{username}/edit", h.EditEmployeeHandler).Methods(http.MethodPatch)
+	r.HandleFunc("/employees/{username}/deactivate", h.DeactivateEmployeeHandler).Methods(http.MethodPut)
+
+	return r
+}
+
+func serve(router http.Handler, method, target, authorization, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if authorization != "" {
+		r.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	return w
+}
+
+// TestLegacyPrincipalCannotManageAccounts checks that identity named by
+// username param or by token issued without password in legacy mode
+// cannot set passwords, act as administrator or manage employees
+func TestLegacyPrincipalCannotManageAccounts(t *testing.T) {
+	st := memory.New()
+	adminID := st.AddEmployee("admin", "", "")
+	victimID := st.AddEmployee("victim", "", "")
+	tokens := auth.NewTokens([]byte("secret"), time.Hour)
+	router := newRouter(st, tokens, true)
+
+	w := serve(router, http.MethodPost, "/auth/login", "", `{"username":"admin"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected legacy login, got %d: %s", w.Code, w.Body.String())
+	}
+	var login models.TokenResponse
+	if err := json.NewDecoder(w.Body).Decode(&login); err != nil {
+		t.Fatal(err)
+	}
+	legacyToken := "Bearer " + login.Token
+
+	tests := []struct {
+		name          string
+		method        string
+		target        string
+		authorization string
+		body          string
+	}{
+		{
+			name:   "set password of employee without one",
+			method: http.MethodPut,
+			target: "/auth/password?username=victim",
+			body:   `{"newPassword":"stolen password"}`,
+		},
+		{
+			name:   "create employee as administrator",
+			method: http.MethodPost,
+			target: "/employees/new?username=admin",
+			body:   `{"username":"intruder"}`,
+		},
+		{
+			name:          "create employee with token issued without password",
+			method:        http.MethodPost,
+			target:        "/employees/new",
+			authorization: legacyToken,
+			body:          `{"username":"intruder"}`,
+		},
+		{
+			name:   "edit employee",
+			method: http.MethodPatch,
+			target: "/employees/victim/edit?username=victim",
+			body:   `{"firstName":"Intruder"}`,
+		},
+		{
+			name:   "deactivate employee as administrator",
+			method: http.MethodPut,
+			target: "/employees/victim/deactivate?username=admin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, tt.method, tt.target, tt.authorization, tt.body)
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("expected status 401, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+
+	if _, err := st.GetCredentials(context.Background(), victimID); err == nil {
+		t.Error("password of victim was set")
+	}
+	if id, _ := st.GetUserID(context.Background(), "intruder"); id != "" {
+		t.Error("employee was created")
+	}
+
+	// the same administrator with verified identity is allowed
+	token, _, err := tokens.Issue(auth.Principal{UserID: adminID, Username: "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = serve(router, http.MethodPost, "/employees/new", "Bearer "+token, `{"username":"newcomer"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// token issued without password stops working with legacy mode
+	w = serve(newRouter(st, tokens, false), http.MethodPut, "/auth/password", legacyToken, `{"newPassword":"stolen password"}`)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// TestChangePassword checks that current password is required once
+// it is set and that it is not required for the first password
+func TestChangePassword(t *testing.T) {
+	st := memory.New()
+	userID := st.AddEmployee("user", "", "")
+	tokens := auth.NewTokens([]byte("secret"), time.Hour)
+	router := newRouter(st, tokens, false)
+
+	token, _, err := tokens.Issue(auth.Principal{UserID: userID, Username: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bearer := "Bearer " + token
+
+	login := func(password string) string {
+		w := serve(router, http.MethodPost, "/auth/login", "", fmt.Sprintf(`{"username":"user","password":%q}`, password))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected login with %q, got %d: %s", password, w.Code, w.Body.String())
+		}
+		var login models.TokenResponse
+		if err := json.NewDecoder(w.Body).Decode(&login); err != nil {
+			t.Fatal(err)
+		}
+
+		return "Bearer " + login.Token
+	}
+
+	// every set password revokes tokens issued before, including the current one
+	steps := []struct {
+		body     string
+		status   int
+		password string
+	}{
+		{`{"newPassword":"first password"}`, http.StatusOK, "first password"},
+		{`{"newPassword":"second password"}`, http.StatusForbidden, ""},
+		{`{"currentPassword":"wrong password","newPassword":"second password"}`, http.StatusForbidden, ""},
+		{`{"currentPassword":"first password","newPassword":"second password"}`, http.StatusOK, "second password"},
+	}
+	for _, step := range steps {
+		w := serve(router, http.MethodPut, "/auth/password", bearer, step.body)
+		if w.Code != step.status {
+			t.Fatalf("%s: expected status %d, got %d: %s", step.body, step.status, w.Code, w.Body.String())
+		}
+		if step.password == "" {
+			continue
+		}
+
+		w = serve(router, http.MethodPut, "/auth/password", bearer, `{"newPassword":"stolen password"}`)
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("expected revoked token to be rejected, got %d: %s", w.Code, w.Body.String())
+		}
+		bearer = login(step.password)
+	}
+}
@@ -0,0 +1,173 @@
+package accounts
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/server/handlers"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+
+	"github.com/gorilla/mux"
+)
+
+// Список сотрудников, включая деактивированных, отсортированный
+// по имени пользователя. Доступен любому сотруднику
+func (h *AccountsHandler) EmployeesListHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := principal(w, r); !ok {
+		return
+	}
+
+	limit, err := handlers.ParseQueryParam(r, "limit", 5)
+	if err != nil {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+		return
+	}
+
+	offset, err := handlers.ParseQueryParam(r, "offset", 0)
+	if err != nil {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+		return
+	}
+
+	employees, err := h.Storage.GetEmployees(r.Context(), limit, offset)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(employees)
+}
+
+// Профиль сотрудника с организациями, ответственным которых он
+// является, и его ролями в них. Доступен любому сотруднику
+func (h *AccountsHandler) GetEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := principal(w, r); !ok {
+		return
+	}
+
+	employee, ok := h.employee(w, r, mux.Vars(r)["username"])
+	if !ok {
+		return
+	}
+
+	memberships, err := h.Storage.GetMemberships(r.Context(), employee.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.EmployeeProfile{
+		Employee:      employee,
+		Organizations: memberships,
+	})
+}
+
+// Редактирование имени и фамилии сотрудника им самим или администратором
+// из ADMIN_USERNAMES. Имя пользователя изменить нельзя
+func (h *AccountsHandler) EditEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	username := mux.Vars(r)["username"]
+
+	p, ok := handlers.VerifiedPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	var patch models.EditEmployeeRequest
+	err := json.NewDecoder(r.Body).Decode(&patch)
+	if err != nil || patch.Validate() != nil {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Данные неправильно сформированы или не соответствуют требованиям.", w)
+		return
+	}
+
+	if !h.admin(p) && p.Username != username {
+		handlers.ReturnErrorResponse(http.StatusForbidden, "Недостаточно прав для выполнения действия.", w)
+		return
+	}
+
+	employee, ok := h.employee(w, r, username)
+	if !ok {
+		return
+	}
+	if employee.DeactivatedAt != nil {
+		handlers.ReturnErrorResponse(http.StatusConflict, "Сотрудник деактивирован.", w)
+		return
+	}
+
+	employee, err = h.Storage.EditEmployee(r.Context(), employee.ID, patch)
+	if err != nil {
+		// employee was deactivated after the check above
+		if errors.Is(err, storage.ErrNotFound) {
+			handlers.ReturnErrorResponse(http.StatusConflict, "Сотрудник деактивирован.", w)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(employee)
+}
+
+// Деактивация сотрудника администратором из ADMIN_USERNAMES. Сотрудник
+// перестает быть ответственным организаций и больше не может войти,
+// его тендеры, предложения и отзывы сохраняются
+func (h *AccountsHandler) DeactivateEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	p, ok := handlers.VerifiedPrincipal(w, r)
+	if !ok {
+		return
+	}
+	if !h.admin(p) {
+		handlers.ReturnErrorResponse(http.StatusForbidden, "Недостаточно прав для выполнения действия.", w)
+		return
+	}
+
+	employee, ok := h.employee(w, r, mux.Vars(r)["username"])
+	if !ok {
+		return
+	}
+	if employee.DeactivatedAt != nil {
+		handlers.ReturnErrorResponse(http.StatusConflict, "Сотрудник уже деактивирован.", w)
+		return
+	}
+
+	employee, err := h.Storage.DeactivateEmployee(r.Context(), employee.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			handlers.ReturnErrorResponse(http.StatusConflict, "Сотрудник уже деактивирован.", w)
+			return
+		}
+		if errors.Is(err, domain.ErrLastOwner) {
+			handlers.ReturnErrorResponse(http.StatusConflict, "Организация должна сохранить хотя бы одного владельца.", w)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(employee)
+}
+
+// employee returns employee with username or writes 404
+func (h *AccountsHandler) employee(w http.ResponseWriter, r *http.Request, username string) (models.Employee, bool) {
+	employee, err := h.Storage.GetEmployee(r.Context(), username)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Сотрудник не найден.", w)
+		return models.Employee{}, false
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return models.Employee{}, false
+	}
+
+	return employee, true
+}
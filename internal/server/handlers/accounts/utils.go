@@ -0,0 +1,64 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/server/handlers"
+	"zadanie-6105/internal/storage/models"
+)
+
+var (
+	// errLocked is returned by checkPassword while account is locked
+	// after too many failed attempts
+	errLocked = errors.New("account is locked")
+	// errWrongPassword is returned by checkPassword for wrong password,
+	// the attempt is counted towards lockout
+	errWrongPassword = errors.New("wrong password")
+)
+
+// checkPassword verifies password of user with credentials. Locked account
+// is rejected with errLocked without verification, wrong password is counted
+// towards lockout and rejected with errWrongPassword
+func (h *AccountsHandler) checkPassword(ctx context.Context, credentials models.Credentials, password string) error {
+	if time.Now().Before(credentials.LockedUntil) {
+		return errLocked
+	}
+
+	ok, err := auth.VerifyPassword(credentials.PasswordHash, password)
+	if err != nil {
+		return fmt.Errorf("failed to verify password: %w", err)
+	}
+	if !ok {
+		err = h.Storage.RecordLoginFailure(ctx, credentials.UserID, h.MaxLoginAttempts, h.LockoutDuration)
+		if err != nil {
+			return err
+		}
+		return errWrongPassword
+	}
+
+	if credentials.FailedAttempts > 0 {
+		return h.Storage.ResetLoginFailures(ctx, credentials.UserID)
+	}
+
+	return nil
+}
+
+// admin reports whether p is an administrator from ADMIN_USERNAMES,
+// unverified identity is never an administrator
+func (h *AccountsHandler) admin(p auth.Principal) bool {
+	return !p.Legacy && h.Admins[p.Username]
+}
+
+// principal returns the authenticated user or writes 401
+func principal(w http.ResponseWriter, r *http.Request) (auth.Principal, bool) {
+	p, ok := auth.FromContext(r.Context())
+	if !ok {
+		handlers.ReturnErrorResponse(http.StatusUnauthorized, "Пользователь не существует или некорректен.", w)
+	}
+
+	return p, ok
+}
@@ -0,0 +1,193 @@
+package apikeys
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/authz"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/server/handlers"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	contentType = "Content-Type"
+	appJSON     = "application/json"
+)
+
+type APIKeysHandler struct {
+	Storage    storage.Storage
+	Authorizer *authz.Authorizer
+}
+
+func New(storage storage.Storage) *APIKeysHandler {
+	return &APIKeysHandler{
+		Storage:    storage,
+		Authorizer: authz.New(storage),
+	}
+}
+
+// Выпуск ключа API организации, ключ возвращается только в этом ответе
+func (h *APIKeysHandler) NewAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	p, organizationID, ok := h.responsible(w, r)
+	if !ok {
+		return
+	}
+
+	var newKey models.NewAPIKeyRequest
+	err := json.NewDecoder(r.Body).Decode(&newKey)
+	if err != nil || !validAPIKeyRequest(&newKey) {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+		return
+	}
+
+	key, keyHash, prefix, err := auth.NewAPIKey()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	apiKey, err := h.Storage.InsertAPIKey(r.Context(), organizationID, p.UserID, newKey, keyHash, prefix)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.APIKeyResponse{
+		APIKey: apiKey,
+		Key:    key,
+	})
+}
+
+// Список ключей API организации, включая отозванные
+func (h *APIKeysHandler) APIKeysListHandler(w http.ResponseWriter, r *http.Request) {
+	_, organizationID, ok := h.responsible(w, r)
+	if !ok {
+		return
+	}
+
+	limit, err := handlers.ParseQueryParam(r, "limit", 5)
+	if err != nil || limit < 0 {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+		return
+	}
+	offset, err := handlers.ParseQueryParam(r, "offset", 0)
+	if err != nil || offset < 0 {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+		return
+	}
+
+	keys, err := h.Storage.GetAPIKeys(r.Context(), organizationID, limit, offset)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(keys)
+}
+
+// Замена ключа API, старый ключ перестает действовать сразу.
+// После ротации ключ действует от имени выполнившего ее сотрудника
+func (h *APIKeysHandler) RotateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	p, organizationID, ok := h.responsible(w, r)
+	if !ok {
+		return
+	}
+
+	key, keyHash, prefix, err := auth.NewAPIKey()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	keyID := mux.Vars(r)["keyID"]
+	apiKey, err := h.Storage.RotateAPIKey(r.Context(), keyID, organizationID, p.UserID, keyHash, prefix)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			handlers.ReturnErrorResponse(http.StatusNotFound, "Ключ API не найден.", w)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.APIKeyResponse{
+		APIKey: apiKey,
+		Key:    key,
+	})
+}
+
+// Отзыв ключа API
+func (h *APIKeysHandler) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	_, organizationID, ok := h.responsible(w, r)
+	if !ok {
+		return
+	}
+
+	keyID := mux.Vars(r)["keyID"]
+	apiKey, err := h.Storage.RevokeAPIKey(r.Context(), keyID, organizationID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			handlers.ReturnErrorResponse(http.StatusNotFound, "Ключ API не найден.", w)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(apiKey)
+}
+
+// responsible returns the authenticated user and organization whose keys
+// his role allows to manage. Keys are managed only by employees, not by
+// keys, and only by verified ones: keys outlive legacy mode
+func (h *APIKeysHandler) responsible(w http.ResponseWriter, r *http.Request) (auth.Principal, string, bool) {
+	p, ok := handlers.VerifiedPrincipal(w, r)
+	if !ok {
+		return auth.Principal{}, "", false
+	}
+
+	organizationID, ok := handlers.ActingOrganization(w, r, h.Storage, p.UserID, "")
+	if !ok {
+		return auth.Principal{}, "", false
+	}
+
+	_, err := h.Authorizer.Authorize(r.Context(), p.Username, domain.ActionManageAPIKeys, authz.Organization(organizationID))
+	if err != nil {
+		handlers.AuthorizationError(w, err)
+		return auth.Principal{}, "", false
+	}
+
+	return p, organizationID, true
+}
+
+// validAPIKeyRequest checks name and scopes of key, duplicate scopes are dropped
+func validAPIKeyRequest(newKey *models.NewAPIKeyRequest) bool {
+	if newKey.Name == "" || len(newKey.Name) > 100 || len(newKey.Scopes) == 0 {
+		return false
+	}
+	for _, scope := range newKey.Scopes {
+		if !auth.ValidScope(scope) {
+			return false
+		}
+	}
+	slices.Sort(newKey.Scopes)
+	newKey.Scopes = slices.Compact(newKey.Scopes)
+
+	return true
+}
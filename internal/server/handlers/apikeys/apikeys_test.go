@@ -0,0 +1,94 @@
+package apikeys
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"zadanie-6105/internal/auth"
+	authmw "zadanie-6105/internal/server/middleware/auth"
+	"zadanie-6105/internal/storage/memory"
+
+	"github.com/gorilla/mux"
+)
+
+// TestLegacyPrincipalCannotManageKeys checks that owner named by username
+// param cannot issue, rotate or revoke keys which would keep working after
+// legacy mode is disabled, while the same owner signed in with token can
+func TestLegacyPrincipalCannotManageKeys(t *testing.T) {
+	st := memory.New()
+	ownerID := st.AddEmployee("owner", "", "")
+	organizationID := st.AddOrganization("acme", "", "LLC")
+	st.AddResponsible(organizationID, ownerID)
+
+	tokens := auth.NewTokens([]byte("secret"), time.Hour)
+	token, _, err := tokens.Issue(auth.Principal{UserID: ownerID, Username: "owner"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bearer := "Bearer " + token
+
+	h := New(st)
+	router := mux.NewRouter()
+	router.Use(authmw.New(slog.New(slog.NewTextHandler(io.Discard, nil)), tokens, nil, st, true))
+	router.HandleFunc("/api_keys", h.APIKeysListHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api_keys/new", h.NewAPIKeyHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api_keys/{keyID}/rotate", h.RotateAPIKeyHandler).Methods(http.MethodPut)
+	router.HandleFunc("/api_keys/{keyID}/revoke", h.RevokeAPIKeyHandler).Methods(http.MethodPut)
+
+	serve := func(method, target, authorization, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		return w
+	}
+
+	newKey := `{"name":"ci","scopes":["tenders:read"]}`
+	w := serve(http.MethodPost, "/api_keys/new", bearer, newKey)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected key to be issued, got %d: %s", w.Code, w.Body.String())
+	}
+	keys, err := st.GetAPIKeys(context.Background(), organizationID, 5, 0)
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("expected one key, got %v, %v", keys, err)
+	}
+	keyID := keys[0].ID
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+	}{
+		{"issue", http.MethodPost, "/api_keys/new?username=owner", newKey},
+		{"list", http.MethodGet, "/api_keys?username=owner", ""},
+		{"rotate", http.MethodPut, "/api_keys/" + keyID + "/rotate?username=owner", ""},
+		{"revoke", http.MethodPut, "/api_keys/" + keyID + "/revoke?username=owner", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(tt.method, tt.target, "", tt.body)
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("expected status 401, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+
+	keys, err = st.GetAPIKeys(context.Background(), organizationID, 5, 0)
+	if err != nil || len(keys) != 1 || keys[0].RotatedAt != nil || keys[0].RevokedAt != nil {
+		t.Fatalf("keys were changed by legacy principal: %v, %v", keys, err)
+	}
+
+	w = serve(http.MethodPut, "/api_keys/"+keyID+"/revoke", bearer, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected key to be revoked, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -2,13 +2,15 @@ package bids
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
 	"strconv"
-	"strings"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/authz"
+	"zadanie-6105/internal/domain"
 	"zadanie-6105/internal/server/handlers"
+	"zadanie-6105/internal/storage"
 	"zadanie-6105/internal/storage/models"
-	"zadanie-6105/internal/storage/postgres"
 
 	"github.com/gorilla/mux"
 )
@@ -19,12 +21,14 @@ const (
 )
 
 type BidsHandler struct {
-	Storage *postgres.Storage
+	Storage    storage.Storage
+	Authorizer *authz.Authorizer
 }
 
-func New(storage *postgres.Storage) *BidsHandler {
+func New(storage storage.Storage) *BidsHandler {
 	return &BidsHandler{
-		Storage: storage,
+		Storage:    storage,
+		Authorizer: authz.New(storage),
 	}
 }
 
@@ -37,83 +41,84 @@ func (h *BidsHandler) NewBidHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// FIXME unhandled error
-	statusCode, _ := userExists(r, w, h, newBid.AuthorID)
-	switch statusCode {
-	case UnhandledError:
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	case HandledError:
+	authorID, ok := handlers.ClaimedIdentity(w, r, newBid.AuthorID, func(p auth.Principal) string {
+		return p.UserID
+	})
+	if !ok {
 		return
 	}
+	newBid.AuthorID = authorID
 
-	// FIXME unhandled error
-	statusCode, _ = isOrganizationResponsible(r, w, h, newBid.AuthorID)
-	switch statusCode {
-	case UnhandledError:
+	username, err := h.Storage.GetUsername(r.Context(), newBid.AuthorID)
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
-	case HandledError:
-		return
 	}
 
-	// FIXME unhandled error
-	statusCode, _ = tenderExists(r, w, h, newBid.TenderID)
-	switch statusCode {
-	case UnhandledError:
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	case HandledError:
+	organizationID, ok := handlers.ActingOrganization(w, r, h.Storage, newBid.AuthorID, newBid.OrganizationID)
+	if !ok {
 		return
 	}
 
-	organizationID, err := h.Storage.GetOrganizationID(r.Context(), newBid.AuthorID)
+	_, err = h.Authorizer.Authorize(r.Context(), username, domain.ActionCreateBid, authz.Organization(organizationID))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		handlers.AuthorizationError(w, err)
 		return
 	}
 
+	// статус тендера проверяется при сохранении, чтобы тендер
+	// не закрылся между проверкой и созданием предложения
 	bid, err := h.Storage.InsertBid(r.Context(), newBid, organizationID)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Тендер не найден.", w)
+		return
+	}
+	if errors.Is(err, domain.ErrTenderClosed) {
+		handlers.ReturnErrorResponse(http.StatusConflict, "Тендер не принимает предложения.", w)
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(bid)
 }
 
 func (h *BidsHandler) MyBidsListHandler(w http.ResponseWriter, r *http.Request) {
-	statusCode, limit, offset := getLimitAndOfset(w, r)
-	if statusCode == HandledError {
+	limit, offset, ok := getLimitAndOfset(w, r)
+	if !ok {
 		return
 	}
 
-	username := r.URL.Query().Get("username")
-	if username == "" {
-		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+	username := handlers.Username(r)
+	userID, err := h.Authorizer.UserID(r.Context(), username)
+	if err != nil {
+		handlers.AuthorizationError(w, err)
 		return
 	}
 
-	// FIXME unhandled error
-	userID, statusCode, _ := getUserID(w, r, h, username)
-	switch statusCode {
-	case UnhandledError:
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	case HandledError:
-		return
+	// предложения можно отфильтровать по организации,
+	// ответственным которой является пользователь
+	organizationID := r.URL.Query().Get("organizationId")
+	if organizationID != "" {
+		_, err = h.Authorizer.Authorize(r.Context(), username, domain.ActionViewBid, authz.Organization(organizationID))
+		if err != nil {
+			handlers.AuthorizationError(w, err)
+			return
+		}
 	}
 
-	bids, err := h.Storage.GetMyBidsList(r.Context(), limit, offset, userID)
+	bids, err := h.Storage.GetMyBidsList(r.Context(), limit, offset, userID, organizationID)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(bids)
 }
 
@@ -125,38 +130,42 @@ func (h *BidsHandler) GetBidsList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	username := r.URL.Query().Get("username")
-	if username == "" {
-		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+	username := handlers.Username(r)
+	userID, err := h.Authorizer.UserID(r.Context(), username)
+	if err != nil {
+		handlers.AuthorizationError(w, err)
 		return
 	}
 
-	userID, err := h.Storage.GetUserID(r.Context(), username)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+	// предложения тендера видят ответственные организаций, без указанной
+	// организации подходит любая организация сотрудника, остальные
+	// ограничения применяет политика видимости
+	organizationIDs, ok := handlers.ActingOrganizations(w, r, h.Storage, userID)
+	if !ok {
 		return
 	}
-	if userID == "" {
-		handlers.ReturnErrorResponse(http.StatusUnauthorized, "Пользователь не существует или некорректен.", w)
-		return
+	if len(organizationIDs) == 0 {
+		organizationIDs = []string{""}
 	}
 
-	// FIXME unhandled error
-	statusCode, _ := isOrganizationResponsible(r, w, h, userID)
-	switch statusCode {
-	case UnhandledError:
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	case HandledError:
+	var denied *authz.Error
+	for _, organizationID := range organizationIDs {
+		_, err = h.Authorizer.Authorize(r.Context(), username, domain.ActionViewBid, authz.Organization(organizationID))
+		if !errors.As(err, &denied) {
+			break
+		}
+	}
+	if err != nil {
+		handlers.AuthorizationError(w, err)
 		return
 	}
 
-	statusCode, limit, offset := getLimitAndOfset(w, r)
-	if statusCode == HandledError {
+	limit, offset, ok := getLimitAndOfset(w, r)
+	if !ok {
 		return
 	}
 
-	bids, err := h.Storage.GetTenderBids(r.Context(), limit, offset, tenderID)
+	bids, err := h.Storage.GetTenderBids(r.Context(), limit, offset, tenderID, userID)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -166,8 +175,8 @@ func (h *BidsHandler) GetBidsList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(bids)
 }
 
@@ -179,29 +188,9 @@ func (h *BidsHandler) GetBidStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	username := r.URL.Query().Get("username")
-	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
-
-	// FIXME unhandled error
-	userID, statusCode, _ := getUserID(w, r, h, username)
-	switch statusCode {
-	case UnhandledError:
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	case HandledError:
-		return
-	}
-
-	// FIXME unhandled error
-	statusCode, _ = isOrganizationResponsible(r, w, h, userID)
-	switch statusCode {
-	case UnhandledError:
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	case HandledError:
+	_, err := h.Authorizer.Authorize(r.Context(), handlers.Username(r), domain.ActionViewBid, authz.Bid(bidID))
+	if err != nil {
+		handlers.AuthorizationError(w, err)
 		return
 	}
 
@@ -215,8 +204,8 @@ func (h *BidsHandler) GetBidStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(status)
 }
 
@@ -229,34 +218,33 @@ func (h *BidsHandler) ChangeBidStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	status := r.URL.Query().Get("status")
-	if status == "" {
+	if !domain.ValidBidStatus(status) {
 		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
 	}
 
-	username := r.URL.Query().Get("username")
-	if username == "" {
-		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+	_, err := h.Authorizer.Authorize(r.Context(), handlers.Username(r), domain.ActionEditBid, authz.Bid(bidID))
+	if err != nil {
+		handlers.AuthorizationError(w, err)
 		return
 	}
 
-	// FIXME unhandled error
-	statusCode, _ := checkOwner(w, r, h, username, bidID)
-	switch statusCode {
-	case UnhandledError:
+	bid, err := h.Storage.ChangeBitStatus(r.Context(), bidID, status)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Предложение не найдено.", w)
 		return
-	case HandledError:
+	}
+	if errors.Is(err, domain.ErrIllegalTransition) {
+		handlers.ReturnErrorResponse(http.StatusConflict, "Предложение не может перейти в этот статус.", w)
 		return
 	}
-
-	bid, err := h.Storage.ChangeBitStatus(r.Context(), bidID, status)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(bid)
 }
 
@@ -271,62 +259,41 @@ func (h *BidsHandler) EditBidHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	username := r.URL.Query().Get("username")
-	
-	if username == "" {
-		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
-		return
-	}
-
-	// FIXME unhandled error
-	statusCode, _ := checkOwner(w, r, h, username, bidID)
-	
-	switch statusCode {
-	case UnhandledError:
-		return
-	case HandledError:
+	userID, err := h.Authorizer.Authorize(r.Context(), handlers.Username(r), domain.ActionEditBid, authz.Bid(bidID))
+	if err != nil {
+		handlers.AuthorizationError(w, err)
 		return
 	}
 
 	var editBid models.EditBidRequest
-	err := json.NewDecoder(r.Body).Decode(&editBid)
+	err = json.NewDecoder(r.Body).Decode(&editBid)
 	
 	if err != nil {
 		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
 	}
-	empty := editBid == models.EditBidRequest{}
-	if empty {
+	err = editBid.Validate()
+	if err != nil {
 		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
 	}
 
-	var namePart, descriptionPart string
-	if editBid.Name != "" {
-		namePart = fmt.Sprintf("name='%s'", editBid.Name)
-	}
-	if editBid.Description != "" {
-		descriptionPart = fmt.Sprintf("description='%s'", editBid.Description)
-	}
-	parts := make([]string, 0, 2)
-
-	if namePart != "" {
-		parts = append(parts, namePart)
+	newBid, err := h.Storage.EditBid(r.Context(), bidID, userID, editBid)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Предложение не найдено.", w)
+		return
 	}
-	if descriptionPart != "" {
-		parts = append(parts, descriptionPart)
+	if errors.Is(err, domain.ErrFinalStatus) {
+		handlers.ReturnErrorResponse(http.StatusConflict, "Предложение в финальном статусе не может быть изменено.", w)
+		return
 	}
-	changeString := strings.Join(parts[:], ", ")
-	
-
-	newBid, err := h.Storage.EditBid(r.Context(), bidID, changeString)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(newBid)
 }
 
@@ -339,69 +306,67 @@ func (h *BidsHandler) SubmitBidHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	username := r.URL.Query().Get("username")
-	
-	if username == "" {
-		handlers.ReturnErrorResponse(http.StatusBadRequest, "Решение не может быть отправлено.", w)
-		return
-	}
-
 	decision := r.URL.Query().Get("decision")
 	
-	if username == "" {
+	if decision != models.DecisionApproved && decision != models.DecisionRejected {
 		handlers.ReturnErrorResponse(http.StatusBadRequest, "Решение не может быть отправлено.", w)
 		return
 	}
 
-	// FIXME unhandled error
-	userID, statusCode, _ := getUserID(w, r, h, username)
-	
-	switch statusCode {
-	case UnhandledError:
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	case HandledError:
+	// решение принимают ответственные организации, создавшей тендер
+	userID, err := h.Authorizer.Authorize(r.Context(), handlers.Username(r), domain.ActionDecideBid, authz.Bid(bidID))
+	if err != nil {
+		handlers.AuthorizationError(w, err)
 		return
 	}
 
-	// FIXME unhandled error
-	statusCode, _ = isOrganizationResponsible(r, w, h, userID)
-	switch statusCode {
-	case UnhandledError:
-		w.WriteHeader(http.StatusInternalServerError)
+	bid, err := h.Storage.BidDecision(r.Context(), bidID, userID, decision)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Предложение не найдено.", w)
 		return
-	case HandledError:
+	}
+	if errors.Is(err, storage.ErrDecisionClosed) {
+		handlers.ReturnErrorResponse(http.StatusConflict, "Решение по предложению не может быть принято.", w)
 		return
 	}
-
-	// FIXME unhandled error
-	userOrganizationID, err := h.Storage.GetOrganizationID(r.Context(), userID)
-	
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	if userOrganizationID == "" {
-		handlers.ReturnErrorResponse(http.StatusForbidden, "Недостаточно прав для выполнения действия.", w)
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(bid)
+}
+
+func (h *BidsHandler) SendFeedbackHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bidID := vars["bidID"]
+	
+	if bidID == "" {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Решение не может быть отправлено.", w)
 		return
 	}
 
-	tenderOrganizationID, err := h.Storage.GetBidOrganizationID(r.Context(), bidID)
-	
+	userID, err := h.Authorizer.Authorize(r.Context(), handlers.Username(r), domain.ActionLeaveFeedback, authz.Bid(bidID))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		handlers.AuthorizationError(w, err)
 		return
 	}
-	if tenderOrganizationID == "" {
-		w.WriteHeader(http.StatusInternalServerError)
+
+	bidFeedback := r.URL.Query().Get("bidFeedback")
+	if bidFeedback == "" {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Отзыв не может быть отправлен.", w)
 		return
 	}
-	if userOrganizationID != tenderOrganizationID {
-		handlers.ReturnErrorResponse(http.StatusForbidden, "Недостаточно прав для выполнения действия.", w)
+
+	err = h.Storage.SendFeedback(r.Context(), bidID, userID, bidFeedback)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	bid, err := h.Storage.BidDecision(r.Context(), bidID, decision)
+	bid, err := h.Storage.GetBidByID(r.Context(), bidID)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -412,201 +377,181 @@ func (h *BidsHandler) SubmitBidHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(bid)
 }
 
-func (h *BidsHandler) SendFeedbackHandler(w http.ResponseWriter, r *http.Request) {
+func (h *BidsHandler) RollbackHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	bidID := vars["bidID"]
-	
+	versionStr := vars["version"]
+
 	if bidID == "" {
-		handlers.ReturnErrorResponse(http.StatusBadRequest, "Решение не может быть отправлено.", w)
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
 	}
 
-	username := r.URL.Query().Get("username")
-	
-	if username == "" {
-		handlers.ReturnErrorResponse(http.StatusBadRequest, "Решение не может быть отправлено.", w)
+	if versionStr == "" {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
 	}
 
-	// FIXME unhandled error
-	userID, statusCode, _ := getUserID(w, r, h, username)
-	
-	switch statusCode {
-	case UnhandledError:
-		w.WriteHeader(http.StatusInternalServerError)
+	version, err := strconv.Atoi(versionStr)
+	if err != nil || version < 1 {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
-	case HandledError:
+	}
+
+	userID, err := h.Authorizer.Authorize(r.Context(), handlers.Username(r), domain.ActionEditBid, authz.Bid(bidID))
+	if err != nil {
+		handlers.AuthorizationError(w, err)
 		return
 	}
 
-	// FIXME unhandled error
-	statusCode, _ = isOrganizationResponsible(r, w, h, userID)
-	switch statusCode {
-	case UnhandledError:
+	bid, err := h.Storage.RollbackBid(r.Context(), bidID, userID, version)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Предложение или версия не найдены.", w)
+		return
+	}
+	if errors.Is(err, domain.ErrFinalStatus) {
+		handlers.ReturnErrorResponse(http.StatusConflict, "Предложение в финальном статусе не может быть изменено.", w)
+		return
+	}
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
-	case HandledError:
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(bid)
+}
+
+// История версий предложения, начиная с последней.
+// Доступна автору предложения и ответственным организации тендера.
+func (h *BidsHandler) BidVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bidID := vars["bidID"]
+	if bidID == "" {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
 	}
 
-	bidFeedback := r.URL.Query().Get("bidFeedback")
-	if bidFeedback == "" {
-		handlers.ReturnErrorResponse(http.StatusBadRequest, "Отзыв не может быть отправлен.", w)
+	limit, offset, ok := getLimitAndOfset(w, r)
+	if !ok {
 		return
 	}
 
-	err := h.Storage.SendFeedback(r.Context(), bidID, userID, bidFeedback)
+	_, err := h.Authorizer.Authorize(r.Context(), handlers.Username(r), domain.ActionViewBid, authz.Bid(bidID))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		handlers.AuthorizationError(w, err)
 		return
 	}
 
-	bid, err := h.Storage.GetBidByID(r.Context(), bidID)
+	versions, err := h.Storage.GetBidVersions(r.Context(), bidID, limit, offset)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	empty := bid == models.Bid{}
-	if empty {
-		handlers.ReturnErrorResponse(http.StatusNotFound, "Предложение не найдено.", w)
-		return
-	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set(contentType, appJSON)
-	json.NewEncoder(w).Encode(bid)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(versions)
 }
 
-func (h *BidsHandler) RollbackHandler(w http.ResponseWriter, r *http.Request) {
+// Сравнение двух версий предложения.
+// Возвращает только поля, значения которых отличаются.
+func (h *BidsHandler) BidVersionsDiffHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	bidID := vars["bidID"]
-	versionStr := vars["version"]
-
 	if bidID == "" {
 		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
 	}
 
-	if versionStr == "" {
+	from, err := strconv.Atoi(vars["from"])
+	if err != nil || from < 1 {
 		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
 	}
 
-	version, err := strconv.Atoi(versionStr)
-	if err != nil {
+	to, err := strconv.Atoi(vars["to"])
+	if err != nil || to < 1 {
 		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
 	}
 
-	username := r.URL.Query().Get("username")
-	if username == "" {
-		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+	_, err = h.Authorizer.Authorize(r.Context(), handlers.Username(r), domain.ActionViewBid, authz.Bid(bidID))
+	if err != nil {
+		handlers.AuthorizationError(w, err)
 		return
 	}
 
-	// FIXME unhandled error
-	statusCode, _ := checkOwner(w, r, h, username, bidID)
-	switch statusCode {
-	case UnhandledError:
-		return
-	case HandledError:
+	fromVersion, err := h.Storage.GetBidVersion(r.Context(), bidID, from)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Предложение или версия не найдены.", w)
 		return
 	}
-
-	err = h.Storage.RollbackBid(r.Context(), bidID, version)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	bid, err := h.Storage.GetBidByID(r.Context(), bidID)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+	toVersion, err := h.Storage.GetBidVersion(r.Context(), bidID, to)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Предложение или версия не найдены.", w)
 		return
 	}
-	empty := bid == models.Bid{}
-	if empty {
-		handlers.ReturnErrorResponse(http.StatusNotFound, "Предложение или версия не найдены.", w)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set(contentType, appJSON)
-	json.NewEncoder(w).Encode(bid)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.DiffBidVersions(fromVersion, toVersion))
 }
 
-
-// FIXME не работаю
+// Отзывы на предложения автора, оставленные организациями тендеров.
+// Доступны ответственным организации тендера, на который автор
+// сделал предложение.
 func (h *BidsHandler) ViewReviewsHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	tenderID := vars["tenderID"]
-	
 	if tenderID == "" {
 		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
 	}
 
-	// FIXME unhandled error
-	statusCode, _ := tenderExists(r, w, h, tenderID)
-	switch statusCode {
-	case HandledError:
-		return
-	case UnhandledError:
-		w.WriteHeader(http.StatusInternalServerError)
-		return		
-	}
-	
 	authorUsername := r.URL.Query().Get("authorUsername")
-	
 	if authorUsername == "" {
 		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
 	}
-	requesterUsername := r.URL.Query().Get("requesterUsername")
-	
-	if requesterUsername == "" {
-		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
-		return
-	}
 
-	statusCode, limit, offset := getLimitAndOfset(w, r)
-	
-	if statusCode == HandledError {
+	limit, offset, ok := getLimitAndOfset(w, r)
+	if !ok {
 		return
 	}
 
-	// FIXME unhandled error
-	requesterID, statusCode, _ := getUserID(w, r, h, requesterUsername)
-	
-	switch statusCode {
-	case HandledError:
+	requesterUsername, ok := handlers.ClaimedIdentity(w, r, r.URL.Query().Get("requesterUsername"), func(p auth.Principal) string {
+		return p.Username
+	})
+	if !ok {
 		return
-	case UnhandledError:
-		w.WriteHeader(http.StatusInternalServerError)
-		return		
 	}
 
-	// FIXME unhandled error
-	authorID, statusCode, _ := getUserID(w, r, h, authorUsername)
-	
-	if statusCode == http.StatusNotFound {
-		handlers.ReturnErrorResponse(http.StatusUnauthorized, "Пользователь не существует или некорректен.", w)
+	_, err := h.Authorizer.Authorize(r.Context(), requesterUsername, domain.ActionViewBid, authz.Tender(tenderID))
+	if err != nil {
+		handlers.AuthorizationError(w, err)
 		return
 	}
 
-	// FIXME unhandled error
-	statusCode, _ = isOrganizationResponsible(r, w, h, requesterID)
-	switch statusCode {
-	case HandledError:
+	authorID, err := h.Authorizer.UserID(r.Context(), authorUsername)
+	if err != nil {
+		handlers.AuthorizationError(w, err)
 		return
-	case UnhandledError:
-		w.WriteHeader(http.StatusInternalServerError)
-		return		
 	}
 
 	authorBidsExist, err := h.Storage.AuthorBidExist(r.Context(), authorID, tenderID)
@@ -629,7 +574,7 @@ func (h *BidsHandler) ViewReviewsHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	w.Header().Set(contentType, appJSON)
 	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(feedback)
 }
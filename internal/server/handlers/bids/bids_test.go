@@ -0,0 +1,95 @@
+package bids
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage/memory"
+	"zadanie-6105/internal/storage/models"
+
+	"github.com/gorilla/mux"
+)
+
+// TestBidsListOfSeveralOrganizations checks that employee responsible
+// for several organizations lists bids of a tender without choosing
+// organization in the header
+func TestBidsListOfSeveralOrganizations(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+	ownerID := st.AddEmployee("owner", "", "")
+	bidderID := st.AddEmployee("bidder", "", "")
+	strangerID := st.AddEmployee("stranger", "", "")
+	acme := st.AddOrganization("acme", "", "LLC")
+	st.AddResponsible(acme, ownerID)
+	holding := st.AddOrganization("acme holding", "", "JSC")
+	st.AddResponsible(holding, ownerID)
+	bidco := st.AddOrganization("bidco", "", "IE")
+	st.AddResponsible(bidco, bidderID)
+
+	tender, err := st.InsertTender(ctx, &models.NewTenderRequest{
+		Name:           "tender",
+		ServiceType:    "Construction",
+		OrganizationID: holding,
+	}, ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.ChangeTenderStatus(ctx, tender.ID, memory.StatusPublished); err != nil {
+		t.Fatal(err)
+	}
+	bid, err := st.InsertBid(ctx, models.BidRequest{
+		Name:       "bid",
+		TenderID:   tender.ID,
+		AuthorType: "User",
+		AuthorID:   bidderID,
+	}, bidco)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.ChangeBitStatus(ctx, bid.ID, domain.BidPublished); err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/bids/{tenderID}/list", New(st).GetBidsList).Methods(http.MethodGet)
+
+	tests := []struct {
+		name         string
+		p            auth.Principal
+		organization string
+		status       int
+	}{
+		{"owner of several organizations", auth.Principal{UserID: ownerID, Username: "owner"}, "", http.StatusOK},
+		{"owner acting on behalf of tender organization", auth.Principal{UserID: ownerID, Username: "owner"}, holding, http.StatusOK},
+		{"bidder", auth.Principal{UserID: bidderID, Username: "bidder"}, "", http.StatusOK},
+		{"employee without organization", auth.Principal{UserID: strangerID, Username: "stranger"}, "", http.StatusForbidden},
+		{"organization of another employee", auth.Principal{UserID: ownerID, Username: "owner"}, bidco, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/bids/"+tender.ID+"/list", nil)
+			r = r.WithContext(auth.WithPrincipal(r.Context(), tt.p))
+			if tt.organization != "" {
+				r.Header.Set("X-Organization-Id", tt.organization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var bids []models.Bid
+			if err := json.NewDecoder(w.Body).Decode(&bids); err != nil || len(bids) != 1 || bids[0].ID != bid.ID {
+				t.Fatalf("expected bid %s, got %v, %v", bid.ID, bids, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package bids
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage/memory"
+	"zadanie-6105/internal/storage/models"
+
+	"github.com/gorilla/mux"
+)
+
+// TestConcurrentDecisions fires approvals of all responsibles on two bids
+// of the same tender at once. Exactly one bid must win and the tender
+// must be closed, decisions on the other bid must be refused
+func TestConcurrentDecisions(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		t.Run(fmt.Sprintf("attempt %d", i), testConcurrentDecisions)
+	}
+}
+
+func testConcurrentDecisions(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+
+	organizationID := st.AddOrganization("tender owner", "", "LLC")
+	usernames := []string{"owner1", "owner2", "owner3"}
+	userIDs := make(map[string]string)
+	for _, username := range usernames {
+		userIDs[username] = st.AddEmployee(username, "", "")
+		st.AddResponsible(organizationID, userIDs[username])
+	}
+
+	tender, err := st.InsertTender(ctx, &models.NewTenderRequest{
+		Name:           "tender",
+		ServiceType:    "Construction",
+		OrganizationID: organizationID,
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.ChangeTenderStatus(ctx, tender.ID, memory.StatusPublished); err != nil {
+		t.Fatal(err)
+	}
+
+	bidIDs := make([]string, 2)
+	for i := range bidIDs {
+		authorID := st.AddEmployee(fmt.Sprintf("author%d", i), "", "")
+		bid, err := st.InsertBid(ctx, models.BidRequest{
+			Name:       fmt.Sprintf("bid %d", i),
+			TenderID:   tender.ID,
+			AuthorType: "User",
+			AuthorID:   authorID,
+		}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := st.ChangeBitStatus(ctx, bid.ID, domain.BidPublished); err != nil {
+			t.Fatal(err)
+		}
+		bidIDs[i] = bid.ID
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/bids/{bidID}/submit_decision", New(st).SubmitBidHandler).Methods(http.MethodPut)
+
+	var mu sync.Mutex
+	approvals := make(map[string]int)
+	var wg sync.WaitGroup
+	for _, bidID := range bidIDs {
+		for _, username := range usernames {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				target := fmt.Sprintf("/bids/%s/submit_decision?decision=Approved", bidID)
+				r := httptest.NewRequest(http.MethodPut, target, nil)
+				r = r.WithContext(auth.WithPrincipal(r.Context(), auth.Principal{
+					UserID:   userIDs[username],
+					Username: username,
+				}))
+
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, r)
+
+				switch w.Code {
+				case http.StatusOK:
+					mu.Lock()
+					approvals[bidID]++
+					mu.Unlock()
+				case http.StatusConflict:
+				default:
+					t.Errorf("unexpected status %d: %s", w.Code, w.Body.String())
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	winners := 0
+	for _, bidID := range bidIDs {
+		status, err := st.GetBidStatus(ctx, bidID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status == domain.BidApproved {
+			winners++
+		}
+		if (status == domain.BidApproved) != (approvals[bidID] == len(usernames)) {
+			t.Errorf("bid %s is %s after %d approvals", bidID, status, approvals[bidID])
+		}
+	}
+	if winners != 1 {
+		t.Errorf("expected exactly one approved bid, got %d: %v", winners, approvals)
+	}
+
+	status, err := st.GetTenderStatus(ctx, tender.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != memory.StatusClosed {
+		t.Errorf("expected tender to be closed, got %s", status)
+	}
+}
@@ -0,0 +1,43 @@
+package docs
+
+import (
+	"encoding/json"
+	"net/http"
+	"zadanie-6105/api"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/swaggest/swgui/v5emb"
+)
+
+type DocsHandler struct {
+	Spec *openapi3.T
+	UI   http.Handler
+}
+
+// New returns handler of the spec and Swagger UI served at basePath,
+// UI assets are embedded into the binary
+func New(spec *openapi3.T, basePath string) *DocsHandler {
+	return &DocsHandler{
+		Spec: spec,
+		UI:   v5emb.New(spec.Info.Title, basePath+"openapi.json", basePath+"docs/"),
+	}
+}
+
+// Спецификация API в YAML в том виде, в котором она хранится в репозитории.
+func (h *DocsHandler) SpecYAMLHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(api.Spec)
+}
+
+// Спецификация API в JSON.
+func (h *DocsHandler) SpecJSONHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.Spec)
+}
+
+// Swagger UI для спецификации из SpecJSONHandler.
+func (h *DocsHandler) UIHandler(w http.ResponseWriter, r *http.Request) {
+	h.UI.ServeHTTP(w, r)
+}
@@ -11,7 +11,7 @@ func New() DefaultHandler {
 }
 
 func (h DefaultHandler) PingHandler(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("ok"))
 }
\ No newline at end of file
@@ -0,0 +1,298 @@
+package organizations
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"unicode/utf8"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/authz"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/server/handlers"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	contentType = "Content-Type"
+	appJSON     = "application/json"
+)
+
+type OrganizationsHandler struct {
+	Storage    storage.Storage
+	Authorizer *authz.Authorizer
+}
+
+func New(storage storage.Storage) *OrganizationsHandler {
+	return &OrganizationsHandler{
+		Storage:    storage,
+		Authorizer: authz.New(storage),
+	}
+}
+
+// Создание организации, создавший ее сотрудник становится владельцем.
+// Ключ API не может создавать организации.
+func (h *OrganizationsHandler) NewOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var newOrganization models.NewOrganizationRequest
+	err := json.NewDecoder(r.Body).Decode(&newOrganization)
+	if err != nil || !validName(newOrganization.Name) || !domain.ValidOrganizationType(newOrganization.Type) {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+		return
+	}
+
+	userID, err := h.Authorizer.UserID(r.Context(), handlers.Username(r))
+	if err != nil {
+		handlers.AuthorizationError(w, err)
+		return
+	}
+	if p, ok := auth.FromContext(r.Context()); ok && p.OrganizationID != "" {
+		handlers.ReturnErrorResponse(http.StatusForbidden, "Недостаточно прав для выполнения действия.", w)
+		return
+	}
+
+	organization, err := h.Storage.InsertOrganization(r.Context(), newOrganization, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(organization)
+}
+
+// Получение организации, доступно любому ее ответственному.
+func (h *OrganizationsHandler) GetOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+	organizationID := mux.Vars(r)["organizationID"]
+	if !h.authorize(w, r, organizationID, domain.ActionViewOrganization) {
+		return
+	}
+
+	organization, err := h.Storage.GetOrganization(r.Context(), organizationID)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Организация не найдена.", w)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(organization)
+}
+
+// Редактирование организации владельцем. Переданные поля заменяются,
+// описание можно очистить с помощью null.
+func (h *OrganizationsHandler) EditOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	organizationID := mux.Vars(r)["organizationID"]
+
+	var patch models.EditOrganizationRequest
+	err := json.NewDecoder(r.Body).Decode(&patch)
+	if err != nil || patch.Validate() != nil ||
+		patch.Name.Set && !validName(patch.Name.Value) ||
+		patch.Type.Set && !domain.ValidOrganizationType(patch.Type.Value) {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Данные неправильно сформированы или не соответствуют требованиям.", w)
+		return
+	}
+
+	if !h.authorize(w, r, organizationID, domain.ActionManageOrganization) {
+		return
+	}
+
+	organization, err := h.Storage.EditOrganization(r.Context(), organizationID, patch)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Организация не найдена.", w)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(organization)
+}
+
+// Удаление организации владельцем вместе с ее тендерами, предложениями,
+// ответственными и ключами API. Удаление запрещено, пока у организации
+// есть незавершенные тендеры или предложения либо принятые предложения.
+func (h *OrganizationsHandler) DeleteOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+	organizationID := mux.Vars(r)["organizationID"]
+	if !h.authorize(w, r, organizationID, domain.ActionManageOrganization) {
+		return
+	}
+
+	organization, err := h.Storage.DeleteOrganization(r.Context(), organizationID)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Организация не найдена.", w)
+		return
+	}
+	if errors.Is(err, domain.ErrOrganizationInUse) {
+		handlers.ReturnErrorResponse(http.StatusConflict, "У организации есть незавершенные тендеры или предложения.", w)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(organization)
+}
+
+// Список организаций, ответственным которых является пользователь,
+// и его ролей в них. Ключ API видит только свою организацию.
+func (h *OrganizationsHandler) MyOrganizationsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.Authorizer.UserID(r.Context(), handlers.Username(r))
+	if err != nil {
+		handlers.AuthorizationError(w, err)
+		return
+	}
+
+	memberships, err := h.Storage.GetMemberships(r.Context(), userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if p, ok := auth.FromContext(r.Context()); ok && p.OrganizationID != "" {
+		memberships = slices.DeleteFunc(memberships, func(m models.Membership) bool {
+			return m.OrganizationID != p.OrganizationID
+		})
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(memberships)
+}
+
+// Список ответственных организации и их ролей.
+// Доступен любому ответственному организации.
+func (h *OrganizationsHandler) MembersListHandler(w http.ResponseWriter, r *http.Request) {
+	organizationID := mux.Vars(r)["organizationID"]
+	if !h.authorize(w, r, organizationID, domain.ActionViewMembers) {
+		return
+	}
+
+	members, err := h.Storage.GetMembers(r.Context(), organizationID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(members)
+}
+
+// Назначение роли сотруднику, сотрудник становится ответственным
+// организации, если не был им. Роли назначает владелец организации.
+func (h *OrganizationsHandler) GrantRoleHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	organizationID := vars["organizationID"]
+
+	role := r.URL.Query().Get("role")
+	if !domain.ValidRole(role) {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+		return
+	}
+
+	if !h.authorize(w, r, organizationID, domain.ActionManageMembers) {
+		return
+	}
+
+	userID, ok := h.member(w, r, vars["username"])
+	if !ok {
+		return
+	}
+
+	member, err := h.Storage.SetRole(r.Context(), organizationID, userID, role)
+	if errors.Is(err, domain.ErrLastOwner) {
+		handlers.ReturnErrorResponse(http.StatusConflict, "Организация должна сохранить хотя бы одного владельца.", w)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(member)
+}
+
+// Отзыв роли, сотрудник перестает быть ответственным организации.
+// Роли отзывает владелец организации.
+func (h *OrganizationsHandler) RevokeRoleHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	organizationID := vars["organizationID"]
+
+	if !h.authorize(w, r, organizationID, domain.ActionManageMembers) {
+		return
+	}
+
+	userID, ok := h.member(w, r, vars["username"])
+	if !ok {
+		return
+	}
+
+	member, err := h.Storage.RevokeRole(r.Context(), organizationID, userID)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Сотрудник не является ответственным организации.", w)
+		return
+	}
+	if errors.Is(err, domain.ErrLastOwner) {
+		handlers.ReturnErrorResponse(http.StatusConflict, "Организация должна сохранить хотя бы одного владельца.", w)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(member)
+}
+
+// authorize checks that role of the authenticated user
+// in organization allows action
+func (h *OrganizationsHandler) authorize(w http.ResponseWriter, r *http.Request, organizationID, action string) bool {
+	_, err := h.Authorizer.Authorize(r.Context(), handlers.Username(r), action, authz.Organization(organizationID))
+	if err != nil {
+		handlers.AuthorizationError(w, err)
+		return false
+	}
+
+	return true
+}
+
+// validName checks name of organization, it must fit organization.name column
+func validName(name string) bool {
+	return name != "" && utf8.RuneCountInString(name) <= 100
+}
+
+// member returns id of employee whose role is changed
+func (h *OrganizationsHandler) member(w http.ResponseWriter, r *http.Request, username string) (string, bool) {
+	userID, err := h.Storage.GetUserID(r.Context(), username)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return "", false
+	}
+	if userID == "" {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Сотрудник не найден.", w)
+		return "", false
+	}
+
+	return userID, true
+}
@@ -2,12 +2,15 @@ package tenders
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
-	"strings"
+	"strconv"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/authz"
+	"zadanie-6105/internal/domain"
 	"zadanie-6105/internal/server/handlers"
+	"zadanie-6105/internal/storage"
 	"zadanie-6105/internal/storage/models"
-	"zadanie-6105/internal/storage/postgres"
 
 	"github.com/gorilla/mux"
 )
@@ -18,17 +21,20 @@ const (
 )
 
 type TendersHandler struct {
-	Storage *postgres.Storage
+	Storage    storage.Storage
+	Authorizer *authz.Authorizer
 }
 
-func New(storage *postgres.Storage) *TendersHandler {
+func New(storage storage.Storage) *TendersHandler {
 	return &TendersHandler{
-		Storage: storage,
+		Storage:    storage,
+		Authorizer: authz.New(storage),
 	}
 }
 
 // Список тендеров с возможностью фильтрации по типу услуг.
-// Если фильтры не заданы, возвращаются все тендеры.
+// Опубликованные тендеры видны всем, остальные только ответственным
+// организации, если передан username.
 func (h *TendersHandler) TenderListHandler(w http.ResponseWriter, r *http.Request) {
 	limit, err := handlers.ParseQueryParam(r, "limit", 5)
 	if err != nil {
@@ -44,7 +50,16 @@ func (h *TendersHandler) TenderListHandler(w http.ResponseWriter, r *http.Reques
 
 	serviceType := r.URL.Query().Get("service_type")
 
-	tendersList, err := h.Storage.GetTenderList(r.Context(), limit, offset, serviceType)
+	var userID string
+	if username := handlers.Username(r); username != "" {
+		userID, err = h.Authorizer.UserID(r.Context(), username)
+		if err != nil {
+			handlers.AuthorizationError(w, err)
+			return
+		}
+	}
+
+	tendersList, err := h.Storage.GetTenderList(r.Context(), limit, offset, serviceType, userID)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -55,8 +70,8 @@ func (h *TendersHandler) TenderListHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(tendersList)
 }
 
@@ -71,24 +86,16 @@ func (h *TendersHandler) NewTenderHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	username := newTender.CreatorUsername
-	userID, err := h.Storage.GetUserID(r.Context(), username)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	if userID == "" {
-		handlers.ReturnErrorResponse(http.StatusUnauthorized, "Пользователь не существует или некорректен.", w)
+	username, ok := handlers.ClaimedIdentity(w, r, newTender.CreatorUsername, func(p auth.Principal) string {
+		return p.Username
+	})
+	if !ok {
 		return
 	}
 
-	organizationID, err := h.Storage.GetOrganizationID(r.Context(), userID)
+	userID, err := h.Authorizer.Authorize(r.Context(), username, domain.ActionCreateTender, authz.Organization(newTender.OrganizationID))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	if organizationID == "" || newTender.OrganizationID != organizationID {
-		handlers.ReturnErrorResponse(http.StatusForbidden, "Недостаточно прав для выполнения действия.", w)
+		handlers.AuthorizationError(w, err)
 		return
 	}
 
@@ -98,8 +105,8 @@ func (h *TendersHandler) NewTenderHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(tender)
 }
 
@@ -118,25 +125,32 @@ func (h *TendersHandler) MyTendersListHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	username := r.URL.Query().Get("username")
-	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
+	username := handlers.Username(r)
+	userID, err := h.Authorizer.UserID(r.Context(), username)
+	if err != nil {
+		handlers.AuthorizationError(w, err)
 		return
 	}
 
-	userID, err := h.Storage.GetUserID(r.Context(), username)
-	if err != nil || userID == "" {
-		handlers.ReturnErrorResponse(http.StatusUnauthorized, "Пользователь не существует или некорректен.", w)
+	// тендеры можно отфильтровать по организации,
+	// ответственным которой является пользователь
+	organizationID := r.URL.Query().Get("organizationId")
+	if organizationID != "" {
+		_, err = h.Authorizer.Authorize(r.Context(), username, domain.ActionViewTender, authz.Organization(organizationID))
+		if err != nil {
+			handlers.AuthorizationError(w, err)
+			return
+		}
 	}
 
-	tenders, err := h.Storage.GetMyTendersList(r.Context(), limit, offset, userID)
+	tenders, err := h.Storage.GetMyTendersList(r.Context(), limit, offset, userID, organizationID)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(tenders)
 }
 
@@ -147,18 +161,10 @@ func (h *TendersHandler) MyTendersListHandler(w http.ResponseWriter, r *http.Req
 func (h *TendersHandler) TenderStatusHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	tenderID := vars["tenderID"]
-	username := r.URL.Query().Get("username")
-	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
 
-	statusCode, err := validateUserAndOrganization(r, w, h, username, tenderID)
+	_, err := h.Authorizer.Authorize(r.Context(), handlers.Username(r), domain.ActionViewTender, authz.Tender(tenderID))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	if statusCode == HandledError {
+		handlers.AuthorizationError(w, err)
 		return
 	}
 
@@ -172,8 +178,8 @@ func (h *TendersHandler) TenderStatusHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(status)
 }
 
@@ -187,45 +193,38 @@ func (h *TendersHandler) TenderChangeStatusHandler(w http.ResponseWriter, r *htt
 	}
 
 	status := r.URL.Query().Get("status")
-	if status == "" {
+	if !domain.ValidTenderStatus(status) {
 		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
 	}
 
-	username := r.URL.Query().Get("username")
-	if username == "" {
-		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+	_, err := h.Authorizer.Authorize(r.Context(), handlers.Username(r), domain.ActionChangeTender, authz.Tender(tenderID))
+	if err != nil {
+		handlers.AuthorizationError(w, err)
 		return
 	}
 
-	statusCode, err := validateUserAndOrganization(r, w, h, username, tenderID)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+	tender, err := h.Storage.ChangeTenderStatus(r.Context(), tenderID, status)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Тендер не найден.", w)
 		return
 	}
-	if statusCode == HandledError {
+	if errors.Is(err, domain.ErrIllegalTransition) {
+		handlers.ReturnErrorResponse(http.StatusConflict, "Тендер не может перейти в этот статус.", w)
 		return
 	}
-
-	tender, err := h.Storage.ChangeTenderStatus(r.Context(), tenderID, status)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	empty := tender == models.Tender{}
-	if empty {
-		handlers.ReturnErrorResponse(http.StatusNotFound, "Тендер не найден.", w)
-		return
-	}
-
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(tender)
 }
 
-// FIXME empty body crash 500
 // Изменение параметров существующего тендера.
+// Отсутствующие поля не меняются, null очищает описание.
 func (h *TendersHandler) EditTenderHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	tenderID := vars["tenderID"]
@@ -234,12 +233,6 @@ func (h *TendersHandler) EditTenderHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 	
-	username := r.URL.Query().Get("username")
-	if username == "" {
-		handlers.ReturnErrorResponse(http.StatusBadRequest, "Данные неправильно сформированы или не соответствуют требованиям.", w)
-		return
-	}
-
 	var editTender models.EditTenderRequest
 	defer r.Body.Close()
 	err := json.NewDecoder(r.Body).Decode(&editTender)
@@ -247,97 +240,159 @@ func (h *TendersHandler) EditTenderHandler(w http.ResponseWriter, r *http.Reques
 		handlers.ReturnErrorResponse(http.StatusBadRequest, "Данные неправильно сформированы или не соответствуют требованиям.", w)
 		return
 	}
+	err = editTender.Validate()
+	if err != nil {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Данные неправильно сформированы или не соответствуют требованиям.", w)
+		return
+	}
 
-	statusCode, err := validateUserAndOrganization(r, w, h, username, tenderID)
+	userID, err := h.Authorizer.Authorize(r.Context(), handlers.Username(r), domain.ActionEditTender, authz.Tender(tenderID))
+	if err != nil {
+		handlers.AuthorizationError(w, err)
+		return
+	}
+
+	tender, err := h.Storage.EditTender(r.Context(), tenderID, userID, editTender)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Тендер не найден.", w)
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	if statusCode == HandledError {
+
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(tender)
+}
+
+// Откатить параметры тендера к указанной версии.
+// Откат считается новой правкой, поэтому версия инкрементируется.
+func (h *TendersHandler) RollbackHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenderID := vars["tenderID"]
+	versionStr := vars["version"]
+
+	if tenderID == "" {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+		return
+	}
+	version, err := strconv.Atoi(versionStr)
+	if err != nil || version < 1 {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
 	}
 
-	var namePart, descriptionPart, serviceTypePart string
-	if editTender.Name != "" {
-		namePart = fmt.Sprintf("name='%s'", editTender.Name)
+	userID, err := h.Authorizer.Authorize(r.Context(), handlers.Username(r), domain.ActionEditTender, authz.Tender(tenderID))
+	if err != nil {
+		handlers.AuthorizationError(w, err)
+		return
 	}
-	if editTender.Description != "" {
-		descriptionPart = fmt.Sprintf("description='%s'", editTender.Description)
+
+	tender, err := h.Storage.RollbackTender(r.Context(), tenderID, userID, version)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Тендер или версия не найдены.", w)
+		return
 	}
-	if editTender.ServiceType != "" {
-		serviceTypePart = fmt.Sprintf("service_type='%s'", editTender.ServiceType)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
-	parts := make([]string, 0, 3)
 
-	if namePart != "" {
-		parts = append(parts, namePart)
+	w.Header().Set(contentType, appJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(tender)
+}
+
+// Получение списка версий тендера, начиная с самой новой.
+// Для каждой версии указано, кто и когда её создал.
+func (h *TendersHandler) TenderVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenderID := vars["tenderID"]
+	if tenderID == "" {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+		return
 	}
-	if descriptionPart != "" {
-		parts = append(parts, descriptionPart)
+
+	limit, err := handlers.ParseQueryParam(r, "limit", 5)
+	if err != nil {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+		return
 	}
-	if serviceTypePart != "" {
-		parts = append(parts, serviceTypePart)
+
+	offset, err := handlers.ParseQueryParam(r, "offset", 0)
+	if err != nil {
+		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
+		return
 	}
-	changeString := strings.Join(parts[:], ", ")
 
-	tender, err := h.Storage.EditTender(r.Context(), tenderID, changeString)
+	_, err = h.Authorizer.Authorize(r.Context(), handlers.Username(r), domain.ActionTenderHistory, authz.Tender(tenderID))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		handlers.AuthorizationError(w, err)
 		return
 	}
-	empty := tender == models.Tender{}
-	if empty {
-		handlers.ReturnErrorResponse(http.StatusNotFound, "Тендер не найден.", w)
+
+	versions, err := h.Storage.GetTenderVersions(r.Context(), tenderID, limit, offset)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set(contentType, appJSON)
-	json.NewEncoder(w).Encode(tender)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(versions)
 }
 
-// FIXME not found not working
-func (h *TendersHandler) RollbackHandler(w http.ResponseWriter, r *http.Request) {
+// Сравнение двух версий тендера.
+// Возвращает только поля, значения которых отличаются.
+func (h *TendersHandler) TenderVersionsDiffHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	tenderID := vars["tenderID"]
-	version := vars["version"]
-
 	if tenderID == "" {
 		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
 	}
-	if version == "" {
+
+	from, err := strconv.Atoi(vars["from"])
+	if err != nil || from < 1 {
 		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
 	}
 
-	username := r.URL.Query().Get("username")
-	if username == "" {
+	to, err := strconv.Atoi(vars["to"])
+	if err != nil || to < 1 {
 		handlers.ReturnErrorResponse(http.StatusBadRequest, "Неверный формат запроса или его параметры.", w)
 		return
 	}
 
-	statusCode, err := validateUserAndOrganization(r, w, h, username, tenderID)
+	_, err = h.Authorizer.Authorize(r.Context(), handlers.Username(r), domain.ActionTenderHistory, authz.Tender(tenderID))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		handlers.AuthorizationError(w, err)
 		return
 	}
-	if statusCode == HandledError {
+
+	fromVersion, err := h.Storage.GetTenderVersion(r.Context(), tenderID, from)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Тендер или версия не найдены.", w)
 		return
 	}
-
-	tender, err := h.Storage.RollbackTender(r.Context(), tenderID, version)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	empty := tender == models.Tender{}
-	if empty {
-		handlers.ReturnErrorResponse(http.StatusNotFound, "Тендер не найден.", w)
+
+	toVersion, err := h.Storage.GetTenderVersion(r.Context(), tenderID, to)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.ReturnErrorResponse(http.StatusNotFound, "Тендер или версия не найдены.", w)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set(contentType, appJSON)
-	json.NewEncoder(w).Encode(tender)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.DiffTenderVersions(fromVersion, toVersion))
 }
\ No newline at end of file
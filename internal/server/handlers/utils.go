@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/authz"
+	"zadanie-6105/internal/storage"
 )
 
 type ErrorResponse struct {
@@ -29,9 +33,155 @@ func ParseQueryParam(r *http.Request, param string, defaultValue int) (int, erro
     return value, nil
 }
 
+// Username returns username of the user authenticated by middleware
+// or empty string for anonymous request
+func Username(r *http.Request) string {
+    p, ok := auth.FromContext(r.Context())
+    if !ok {
+        return ""
+    }
+
+    return p.Username
+}
+
+// VerifiedPrincipal returns the authenticated user or writes 401 if there
+// is none or his identity is not verified, see auth.Principal.Legacy.
+// Accounts and credentials are managed only by verified users
+func VerifiedPrincipal(w http.ResponseWriter, r *http.Request) (auth.Principal, bool) {
+    p, ok := auth.FromContext(r.Context())
+    if !ok {
+        ReturnErrorResponse(http.StatusUnauthorized, "Пользователь не существует или некорректен.", w)
+        return auth.Principal{}, false
+    }
+    if p.Legacy {
+        ReturnErrorResponse(http.StatusUnauthorized, "Действие доступно только после входа по паролю или через SSO.", w)
+        return auth.Principal{}, false
+    }
+
+    return p, true
+}
+
+// OrganizationHeader chooses acting organization of employee
+// responsible for several organizations
+const OrganizationHeader = "X-Organization-Id"
+
+// ActingOrganization returns organization userID acts on behalf of. It is
+// requested in body or in OrganizationHeader, otherwise it is taken from
+// the authenticated user if he is userID and has one, e.g. API key is
+// bound to organization, or it is the only organization of userID.
+// Membership in requested organization is checked by authz.Authorizer,
+// empty string is returned if userID is not responsible for any.
+// Error response is written if false is returned
+func ActingOrganization(w http.ResponseWriter, r *http.Request, roles storage.RoleRepository, userID, requested string) (string, bool) {
+    if requested == "" {
+        requested = r.Header.Get(OrganizationHeader)
+    }
+    if requested != "" {
+        return requested, true
+    }
+
+    p, ok := auth.FromContext(r.Context())
+    if ok && p.UserID == userID && p.OrganizationID != "" {
+        return p.OrganizationID, true
+    }
+
+    memberships, err := roles.GetMemberships(r.Context(), userID)
+    if err != nil {
+        w.WriteHeader(http.StatusInternalServerError)
+        return "", false
+    }
+
+    switch len(memberships) {
+    case 0:
+        return "", true
+    case 1:
+        return memberships[0].OrganizationID, true
+    }
+
+    ReturnErrorResponse(http.StatusBadRequest, "Сотрудник ответственен за несколько организаций, укажите организацию в заголовке "+OrganizationHeader+".", w)
+    return "", false
+}
+
+// ActingOrganizations returns organizations userID may act on behalf of
+// when organization is optional. Organization requested in OrganizationHeader
+// or bound to the authenticated user is the only one, otherwise these are
+// all organizations of userID. Error response is written if false is returned
+func ActingOrganizations(w http.ResponseWriter, r *http.Request, roles storage.RoleRepository, userID string) ([]string, bool) {
+    if requested := r.Header.Get(OrganizationHeader); requested != "" {
+        return []string{requested}, true
+    }
+
+    p, ok := auth.FromContext(r.Context())
+    if ok && p.UserID == userID && p.OrganizationID != "" {
+        return []string{p.OrganizationID}, true
+    }
+
+    memberships, err := roles.GetMemberships(r.Context(), userID)
+    if err != nil {
+        w.WriteHeader(http.StatusInternalServerError)
+        return nil, false
+    }
+
+    organizationIDs := make([]string, 0, len(memberships))
+    for _, m := range memberships {
+        organizationIDs = append(organizationIDs, m.OrganizationID)
+    }
+
+    return organizationIDs, true
+}
+
+// ClaimedIdentity checks identity claimed in request body. Authenticated
+// user can act only as himself, claim of anonymous request is trusted only
+// in legacy mode. own picks compared field of principal. Error response is
+// written if false is returned
+func ClaimedIdentity(w http.ResponseWriter, r *http.Request, claimed string, own func(p auth.Principal) string) (string, bool) {
+    p, ok := auth.FromContext(r.Context())
+    if !ok {
+        if !auth.LegacyEnabled(r.Context()) || claimed == "" {
+            ReturnErrorResponse(http.StatusUnauthorized, "Пользователь не существует или некорректен.", w)
+            return "", false
+        }
+
+        return claimed, true
+    }
+
+    if claimed != "" && claimed != own(p) {
+        ReturnErrorResponse(http.StatusForbidden, "Недостаточно прав для выполнения действия.", w)
+        return "", false
+    }
+
+    return own(p), true
+}
+
+// notFoundReasons are reasons of 404 responses for kinds of authz.Resource
+var notFoundReasons = map[string]string{
+    authz.KindTender: "Тендер не найден.",
+    authz.KindBid:    "Предложение не найдено.",
+}
+
+// AuthorizationError writes response for error returned by authz.Authorizer:
+// 401 if user does not exist, 403 if action is not allowed, 404 if resource
+// does not exist and 500 for any other error
+func AuthorizationError(w http.ResponseWriter, err error) {
+    var denied *authz.Error
+    if !errors.As(err, &denied) {
+        w.WriteHeader(http.StatusInternalServerError)
+        return
+    }
+
+    switch {
+    case errors.Is(denied, authz.ErrUnauthenticated):
+        ReturnErrorResponse(http.StatusUnauthorized, "Пользователь не существует или некорректен.", w)
+    case errors.Is(denied, authz.ErrNotFound):
+        ReturnErrorResponse(http.StatusNotFound, notFoundReasons[denied.Resource.Kind], w)
+    default:
+        ReturnErrorResponse(http.StatusForbidden, "Недостаточно прав для выполнения действия.", w)
+    }
+}
+
 func ReturnErrorResponse(statusCode int, reason string, w http.ResponseWriter) {
-    w.WriteHeader(statusCode)
     w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(statusCode)
     json.NewEncoder(w).Encode(ErrorResponse{
         Reason: reason,
     })
@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/auth/oidc"
+	"zadanie-6105/internal/server/handlers"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+
+	"github.com/gorilla/mux"
+)
+
+// Repository is a part of storage used to authenticate requests
+type Repository interface {
+	storage.EmployeeRepository
+	storage.CredentialRepository
+	storage.APIKeyRepository
+}
+
+// errUnknownEmployee is returned if SSO user is not an employee
+// and employees are not created on first sign in
+var errUnknownEmployee = errors.New("sso user is not an employee")
+
+// New returns middleware which puts the authenticated user into request
+// context. Token is taken from Authorization: Bearer header, requests
+// without it pass anonymous and handlers decide whether it is allowed.
+// Bearer token is either issued by /auth/login or, if idp is not nil,
+// by OpenID Connect provider. Token issued by /auth/login is revoked
+// once password of its user is changed or reset.
+// API key from Authorization: ApiKey header is only put aside, it is
+// turned into the user by RequireScope of routes which accept keys.
+// If legacy is set, the user named by username param or by token issued
+// without password is put aside as well, it is marked as Legacy and
+// turned into the user by AllowLegacy of routes which accept it
+func New(log *slog.Logger, tokens *auth.Tokens, idp *oidc.Provider, employees Repository, legacy bool) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/auth"),
+		)
+
+		log.Info("auth middleware enabled", slog.Bool("legacy_username", legacy), slog.Bool("oidc", idp != nil))
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			if legacy {
+				ctx = auth.WithLegacy(ctx, nil)
+			}
+
+			header := r.Header.Get("Authorization")
+			switch {
+			case header != "":
+				scheme, token, ok := strings.Cut(header, " ")
+				if ok && strings.EqualFold(scheme, "ApiKey") {
+					owner, err := employees.GetAPIKeyOwner(ctx, auth.HashAPIKey(strings.TrimSpace(token)))
+					if errors.Is(err, storage.ErrNotFound) {
+						handlers.ReturnErrorResponse(http.StatusUnauthorized, "Ключ API недействителен или отозван.", w)
+						return
+					}
+					if err != nil {
+						log.Error("cannot get api key", slog.String("error", err.Error()))
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+
+					// claims in body are not trusted for API keys even in legacy mode
+					ctx = auth.WithAPIKey(r.Context(), auth.APIKey{
+						ID:     owner.ID,
+						Scopes: owner.Scopes,
+						Principal: auth.Principal{
+							UserID:         owner.UserID,
+							Username:       owner.Username,
+							OrganizationID: owner.OrganizationID,
+						},
+					})
+					break
+				}
+				if !ok || !strings.EqualFold(scheme, "Bearer") {
+					handlers.ReturnErrorResponse(http.StatusUnauthorized, "Неверный формат заголовка авторизации.", w)
+					return
+				}
+
+				token = strings.TrimSpace(token)
+				p, err := tokens.Parse(token)
+				local := err == nil
+				if err != nil && idp != nil {
+					p, err = ssoPrincipal(ctx, log, idp, employees, token)
+				}
+				switch {
+				case errors.Is(err, auth.ErrInvalidToken), errors.Is(err, oidc.ErrInvalidToken):
+					log.Debug("token rejected", slog.String("error", err.Error()))
+					handlers.ReturnErrorResponse(http.StatusUnauthorized, "Токен недействителен или истек.", w)
+					return
+				case errors.Is(err, oidc.ErrUnavailable):
+					log.Error("cannot verify sso token", slog.String("error", err.Error()))
+					handlers.ReturnErrorResponse(http.StatusServiceUnavailable, "Провайдер SSO недоступен, повторите попытку позже.", w)
+					return
+				case errors.Is(err, errUnknownEmployee):
+					handlers.ReturnErrorResponse(http.StatusUnauthorized, "Пользователь не существует или некорректен.", w)
+					return
+				case err != nil:
+					log.Error("cannot authenticate sso user", slog.String("error", err.Error()))
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				case p.Legacy && !legacy:
+					// issued without password while legacy mode was enabled
+					handlers.ReturnErrorResponse(http.StatusUnauthorized, "Токен недействителен или истек.", w)
+					return
+				}
+
+				exists, err := employees.UserExists(ctx, p.UserID)
+				if err != nil {
+					log.Error("cannot check user", slog.String("error", err.Error()))
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				if !exists {
+					handlers.ReturnErrorResponse(http.StatusUnauthorized, "Пользователь не существует или некорректен.", w)
+					return
+				}
+
+				if local {
+					version, err := credentialsVersion(ctx, employees, p.UserID)
+					if err != nil {
+						log.Error("cannot get credentials", slog.String("error", err.Error()))
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					if version != p.CredentialsVersion {
+						// password was changed or reset after token was issued
+						handlers.ReturnErrorResponse(http.StatusUnauthorized, "Токен недействителен или истек.", w)
+						return
+					}
+				}
+
+				if p.Legacy {
+					ctx = auth.WithLegacy(ctx, &p)
+					break
+				}
+				ctx = auth.WithPrincipal(ctx, p)
+			case legacy && r.URL.Query().Get("username") != "":
+				username := r.URL.Query().Get("username")
+				userID, err := employees.GetUserID(ctx, username)
+				if err != nil {
+					log.Error("cannot get user", slog.String("error", err.Error()))
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				if userID == "" {
+					handlers.ReturnErrorResponse(http.StatusUnauthorized, "Пользователь не существует или некорректен.", w)
+					return
+				}
+
+				ctx = auth.WithLegacy(ctx, &auth.Principal{UserID: userID, Username: username, Legacy: true})
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// credentialsVersion returns version of password of userID,
+// 0 if user has no password
+func credentialsVersion(ctx context.Context, employees Repository, userID string) (int, error) {
+	credentials, err := employees.GetCredentials(ctx, userID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return credentials.Version, nil
+}
+
+// RequireScope lets API key requests reach next only if key has scope,
+// the key then acts as its employee within its organization. Routes
+// without RequireScope see API key requests as anonymous
+func RequireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key, ok := auth.APIKeyFromContext(r.Context())
+		if !ok {
+			next(w, r)
+			return
+		}
+		if !key.Allows(scope) {
+			handlers.ReturnErrorResponse(http.StatusForbidden, "Ключ API не позволяет выполнить действие.", w)
+			return
+		}
+
+		next(w, r.WithContext(auth.WithPrincipal(r.Context(), key.Principal)))
+	}
+}
+
+// AllowLegacy lets next trust legacy identity: the user put aside by New
+// becomes the authenticated one and identity claimed in request body is
+// trusted for anonymous requests. It wraps only routes which existed
+// before token authentication, other routes see legacy requests as anonymous
+func AllowLegacy(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		p, ok := auth.LegacyFromContext(ctx)
+		if !ok {
+			next(w, r)
+			return
+		}
+
+		ctx = auth.WithLegacyTrusted(ctx)
+		if _, authenticated := auth.FromContext(ctx); !authenticated && p != nil {
+			ctx = auth.WithPrincipal(ctx, *p)
+		}
+
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// ssoPrincipal verifies token of OpenID Connect provider and finds employee
+// by username claim. Employee is created if provider allows it
+func ssoPrincipal(ctx context.Context, log *slog.Logger, idp *oidc.Provider, employees Repository, token string) (auth.Principal, error) {
+	identity, err := idp.Verify(ctx, token)
+	if err != nil {
+		return auth.Principal{}, err
+	}
+
+	userID, err := employees.GetUserID(ctx, identity.Username)
+	if err != nil {
+		return auth.Principal{}, err
+	}
+	if userID != "" {
+		return auth.Principal{UserID: userID, Username: identity.Username}, nil
+	}
+	if !idp.CreateEmployees() {
+		return auth.Principal{}, errUnknownEmployee
+	}
+
+	newEmployee := models.NewEmployeeRequest{
+		Username:  identity.Username,
+		FirstName: identity.FirstName,
+		LastName:  identity.LastName,
+	}
+	if err := newEmployee.Validate(); err != nil {
+		return auth.Principal{}, fmt.Errorf("%w: %s", errUnknownEmployee, err)
+	}
+
+	employee, err := employees.InsertEmployee(ctx, newEmployee, "")
+	if errors.Is(err, storage.ErrAlreadyExists) {
+		// created by concurrent request of the same user
+		userID, err = employees.GetUserID(ctx, identity.Username)
+		if err != nil {
+			return auth.Principal{}, err
+		}
+		// username belongs to deactivated employee
+		if userID == "" {
+			return auth.Principal{}, errUnknownEmployee
+		}
+
+		return auth.Principal{UserID: userID, Username: identity.Username}, nil
+	}
+	if err != nil {
+		return auth.Principal{}, err
+	}
+	log.Info("employee created on first sso sign in", slog.String("username", employee.Username))
+
+	return auth.Principal{UserID: employee.ID, Username: employee.Username}, nil
+}
@@ -0,0 +1,97 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"zadanie-6105/internal/server/handlers"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gorilla/mux"
+)
+
+// New returns middleware which checks path params, query params and body
+// of request against operation of doc before it reaches handler, request
+// which does not match is answered with 400 naming the failing field.
+// Routes which are absent from doc pass unchecked, their handlers
+// validate input themselves
+func New(log *slog.Logger, doc *openapi3.T) (mux.MiddlewareFunc, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build openapi router: %w", err)
+	}
+
+	options := &openapi3filter.Options{
+		// handlers apply defaults themselves, request stays as sent
+		SkipSettingDefaults: true,
+		AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/validation"),
+		)
+
+		log.Info("validation middleware enabled")
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// handlers decode body as JSON whatever the client declares
+			if r.Header.Get("Content-Type") == "" {
+				r.Header.Set("Content-Type", "application/json")
+			}
+
+			err = openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			})
+			if err != nil {
+				log.Debug("request does not match spec", slog.String("error", err.Error()))
+				handlers.ReturnErrorResponse(http.StatusBadRequest, reason(err), w)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// reason turns validation error into response reason which names
+// the param or body field that failed
+func reason(err error) string {
+	var requestErr *openapi3filter.RequestError
+	if !errors.As(err, &requestErr) {
+		return "Неверный формат запроса или его параметры."
+	}
+
+	detail := requestErr.Reason
+	var schemaErr *openapi3.SchemaError
+	switch {
+	case errors.As(requestErr.Err, &schemaErr):
+		detail = schemaErr.Reason
+	case requestErr.Err != nil && (detail == "" || detail == requestErr.Err.Error()):
+		detail = requestErr.Err.Error()
+	case requestErr.Err != nil:
+		detail += ": " + requestErr.Err.Error()
+	}
+
+	switch {
+	case requestErr.Parameter != nil:
+		return fmt.Sprintf("Неверный параметр %s: %s.", requestErr.Parameter.Name, detail)
+	case schemaErr != nil && len(schemaErr.JSONPointer()) > 0:
+		return fmt.Sprintf("Неверное поле %s: %s.", strings.Join(schemaErr.JSONPointer(), "."), detail)
+	default:
+		return fmt.Sprintf("Неверное тело запроса: %s.", detail)
+	}
+}
@@ -0,0 +1,132 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"zadanie-6105/api"
+	"zadanie-6105/internal/server/handlers"
+
+	"github.com/gorilla/mux"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	doc, err := api.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// handler echoes body to check that it is still readable after validation
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	}
+
+	r := mux.NewRouter()
+	api := r.PathPrefix("/api").Subrouter()
+	api.Use(validator)
+	api.HandleFunc("/tenders", echo).Methods(http.MethodGet)
+	api.HandleFunc("/tenders/new", echo).Methods(http.MethodPost)
+	api.HandleFunc("/tenders/{tenderID}/status", echo).Methods(http.MethodPut)
+	api.HandleFunc("/tenders/{tenderID}/edit", echo).Methods(http.MethodPatch)
+	api.HandleFunc("/tenders/{tenderID}/rollback/{version}", echo).Methods(http.MethodPut)
+	api.HandleFunc("/bids/{bidID}/feedback", echo).Methods(http.MethodPut)
+	api.HandleFunc("/employees/new", echo).Methods(http.MethodPost)
+	api.HandleFunc("/debug", echo).Methods(http.MethodPost)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestValidation(t *testing.T) {
+	srv := newServer(t)
+
+	newTender := `{"name":"tender","description":"","serviceType":"Delivery","organizationId":"org"}`
+	long := strings.Repeat("a", 101)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		field  string
+	}{
+		{"valid body", http.MethodPost, "/api/tenders/new", newTender, http.StatusOK, ""},
+		{"name too long", http.MethodPost, "/api/tenders/new",
+			`{"name":"` + long + `","description":"","serviceType":"Delivery","organizationId":"org"}`, http.StatusBadRequest, "name"},
+		{"unknown service type", http.MethodPost, "/api/tenders/new",
+			`{"name":"tender","description":"","serviceType":"Cooking","organizationId":"org"}`, http.StatusBadRequest, "serviceType"},
+		{"missing field", http.MethodPost, "/api/tenders/new",
+			`{"name":"tender","description":"","serviceType":"Delivery"}`, http.StatusBadRequest, "organizationId"},
+		{"unknown field", http.MethodPost, "/api/tenders/new",
+			`{"name":"tender","description":"","serviceType":"Delivery","organizationId":"org","budget":1}`, http.StatusBadRequest, "budget"},
+		{"empty body", http.MethodPost, "/api/tenders/new", "", http.StatusBadRequest, ""},
+		{"malformed body", http.MethodPost, "/api/tenders/new", `{"name":`, http.StatusBadRequest, ""},
+		{"null clears description", http.MethodPatch, "/api/tenders/t/edit", `{"description":null}`, http.StatusOK, ""},
+		{"null name", http.MethodPatch, "/api/tenders/t/edit", `{"name":null}`, http.StatusBadRequest, "name"},
+		{"limit in range", http.MethodGet, "/api/tenders?limit=50&offset=0", "", http.StatusOK, ""},
+		{"limit above maximum", http.MethodGet, "/api/tenders?limit=51", "", http.StatusBadRequest, "limit"},
+		{"limit is not a number", http.MethodGet, "/api/tenders?limit=five", "", http.StatusBadRequest, "limit"},
+		{"negative offset", http.MethodGet, "/api/tenders?offset=-1", "", http.StatusBadRequest, "offset"},
+		{"service type filter", http.MethodGet, "/api/tenders?service_type=Delivery&service_type=Construction", "", http.StatusOK, ""},
+		{"unknown service type filter", http.MethodGet, "/api/tenders?service_type=Cooking", "", http.StatusBadRequest, "service_type"},
+		{"valid status", http.MethodPut, "/api/tenders/t/status?status=Canceled", "", http.StatusOK, ""},
+		{"unknown status", http.MethodPut, "/api/tenders/t/status?status=Archived", "", http.StatusBadRequest, "status"},
+		{"missing status", http.MethodPut, "/api/tenders/t/status", "", http.StatusBadRequest, "status"},
+		{"path param too long", http.MethodPut, "/api/tenders/" + long + "/status?status=Closed", "", http.StatusBadRequest, "tenderId"},
+		{"zero version", http.MethodPut, "/api/tenders/t/rollback/0", "", http.StatusBadRequest, "version"},
+		{"feedback too long", http.MethodPut, "/api/bids/b/feedback?bidFeedback=" + strings.Repeat("a", 1001), "", http.StatusBadRequest, "bidFeedback"},
+		{"username is not a slug", http.MethodPost, "/api/employees/new", `{"username":"Test User"}`, http.StatusBadRequest, "username"},
+		{"route absent from spec", http.MethodPost, "/api/debug", `{"anything":1}`, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, srv.URL+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, resp.StatusCode, body)
+			}
+
+			if tt.status == http.StatusOK {
+				if string(body) != tt.body {
+					t.Errorf("handler got body %q, expected %q", body, tt.body)
+				}
+				return
+			}
+
+			var errResp handlers.ErrorResponse
+			if err := json.Unmarshal(body, &errResp); err != nil {
+				t.Fatalf("cannot decode error response %q: %v", body, err)
+			}
+			if !strings.Contains(errResp.Reason, tt.field) {
+				t.Errorf("expected reason to name %q, got %q", tt.field, errResp.Reason)
+			}
+		})
+	}
+}
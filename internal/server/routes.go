@@ -2,38 +2,86 @@ package server
 
 import (
 	"net/http"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/config"
 	"zadanie-6105/internal/server/handlers"
+	"zadanie-6105/internal/server/handlers/accounts"
+	"zadanie-6105/internal/server/handlers/apikeys"
 	"zadanie-6105/internal/server/handlers/bids"
+	"zadanie-6105/internal/server/handlers/docs"
+	"zadanie-6105/internal/server/handlers/organizations"
 	"zadanie-6105/internal/server/handlers/tenders"
-	"zadanie-6105/internal/storage/postgres"
+	authmw "zadanie-6105/internal/server/middleware/auth"
+	"zadanie-6105/internal/storage"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gorilla/mux"
 )
 
-// LoadRoutes initializes handlers for /tenders/*, /* and /bids/* endpoints
-// after initializing it register routes that this handler serves
-func LoadRoutes(r *mux.Router, storage *postgres.Storage) {
+// LoadRoutes initializes handlers for /tenders/*, /auth/*, /employees/*, /api_keys/*, /organizations/*, /docs/*, /* and /bids/* endpoints
+// after initializing it register routes that this handler serves. Only routes
+// wrapped with RequireScope can be called with API key. Only routes wrapped with
+// AllowLegacy, which existed before token authentication, trust username param
+// in legacy mode. Every route must be described in spec, which is served at
+// /openapi.yaml and /openapi.json
+func LoadRoutes(r *mux.Router, storage storage.Storage, tokens *auth.Tokens, notifier auth.ResetNotifier, spec *openapi3.T, cfg config.Config) {
 	defaultHandler := handlers.New()
 	r.HandleFunc("/ping", defaultHandler.PingHandler).Methods(http.MethodGet)
 
+	docsHandler := docs.New(spec, "/api/")
+	r.HandleFunc("/openapi.yaml", docsHandler.SpecYAMLHandler).Methods(http.MethodGet)
+	r.HandleFunc("/openapi.json", docsHandler.SpecJSONHandler).Methods(http.MethodGet)
+	r.PathPrefix("/docs/").HandlerFunc(docsHandler.UIHandler).Methods(http.MethodGet)
+
+	accountsHandler := accounts.New(storage, tokens, notifier, cfg)
+	r.HandleFunc("/auth/login", accountsHandler.LoginHandler).Methods(http.MethodPost)
+	r.HandleFunc("/auth/password", accountsHandler.ChangePasswordHandler).Methods(http.MethodPut)
+	r.HandleFunc("/auth/password/reset", accountsHandler.ResetPasswordHandler).Methods(http.MethodPost)
+	r.HandleFunc("/auth/password/reset/confirm", accountsHandler.ConfirmResetPasswordHandler).Methods(http.MethodPost)
+	r.HandleFunc("/employees", accountsHandler.EmployeesListHandler).Methods(http.MethodGet)
+	r.HandleFunc("/employees/new", accountsHandler.NewEmployeeHandler).Methods(http.MethodPost)
+	r.HandleFunc("/employees/{username}", accountsHandler.GetEmployeeHandler).Methods(http.MethodGet)
+	r.HandleFunc("/employees/{username}/edit", accountsHandler.EditEmployeeHandler).Methods(http.MethodPatch)
+	r.HandleFunc("/employees/{username}/deactivate", accountsHandler.DeactivateEmployeeHandler).Methods(http.MethodPut)
+
+	apiKeysHandler := apikeys.New(storage)
+	r.HandleFunc("/api_keys", apiKeysHandler.APIKeysListHandler).Methods(http.MethodGet)
+	r.HandleFunc("/api_keys/new", apiKeysHandler.NewAPIKeyHandler).Methods(http.MethodPost)
+	r.HandleFunc("/api_keys/{keyID}/rotate", apiKeysHandler.RotateAPIKeyHandler).Methods(http.MethodPut)
+	r.HandleFunc("/api_keys/{keyID}/revoke", apiKeysHandler.RevokeAPIKeyHandler).Methods(http.MethodPut)
+
+	organizationsHandler := organizations.New(storage)
+	r.HandleFunc("/organizations/new", organizationsHandler.NewOrganizationHandler).Methods(http.MethodPost)
+	r.HandleFunc("/organizations/my", organizationsHandler.MyOrganizationsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/organizations/{organizationID}", organizationsHandler.GetOrganizationHandler).Methods(http.MethodGet)
+	r.HandleFunc("/organizations/{organizationID}", organizationsHandler.DeleteOrganizationHandler).Methods(http.MethodDelete)
+	r.HandleFunc("/organizations/{organizationID}/edit", organizationsHandler.EditOrganizationHandler).Methods(http.MethodPatch)
+	r.HandleFunc("/organizations/{organizationID}/members", organizationsHandler.MembersListHandler).Methods(http.MethodGet)
+	r.HandleFunc("/organizations/{organizationID}/members/{username}/grant", organizationsHandler.GrantRoleHandler).Methods(http.MethodPut)
+	r.HandleFunc("/organizations/{organizationID}/members/{username}/revoke", organizationsHandler.RevokeRoleHandler).Methods(http.MethodPut)
+
 	tendersHandler := tenders.New(storage)
-	r.HandleFunc("/tenders", tendersHandler.TenderListHandler).Methods(http.MethodGet)
-	r.HandleFunc("/tenders/new", tendersHandler.NewTenderHandler).Methods(http.MethodPost)
-	r.HandleFunc("/tenders/my", tendersHandler.MyTendersListHandler).Methods(http.MethodGet)
-	r.HandleFunc("/tenders/{tenderID}/status", tendersHandler.TenderStatusHandler).Methods(http.MethodGet)
-	r.HandleFunc("/tenders/{tenderID}/status", tendersHandler.TenderChangeStatusHandler).Methods(http.MethodPut)
-	r.HandleFunc("/tenders/{tenderID}/edit", tendersHandler.EditTenderHandler).Methods(http.MethodPatch)
-	r.HandleFunc("/tenders/{tenderID}/rollback/{version}", tendersHandler.RollbackHandler).Methods(http.MethodPut)
+	r.HandleFunc("/tenders", authmw.RequireScope(auth.ScopeReadTenders, authmw.AllowLegacy(tendersHandler.TenderListHandler))).Methods(http.MethodGet)
+	r.HandleFunc("/tenders/new", authmw.AllowLegacy(tendersHandler.NewTenderHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/tenders/my", authmw.RequireScope(auth.ScopeReadTenders, authmw.AllowLegacy(tendersHandler.MyTendersListHandler))).Methods(http.MethodGet)
+	r.HandleFunc("/tenders/{tenderID}/status", authmw.RequireScope(auth.ScopeReadTenders, authmw.AllowLegacy(tendersHandler.TenderStatusHandler))).Methods(http.MethodGet)
+	r.HandleFunc("/tenders/{tenderID}/status", authmw.AllowLegacy(tendersHandler.TenderChangeStatusHandler)).Methods(http.MethodPut)
+	r.HandleFunc("/tenders/{tenderID}/edit", authmw.AllowLegacy(tendersHandler.EditTenderHandler)).Methods(http.MethodPatch)
+	r.HandleFunc("/tenders/{tenderID}/rollback/{version}", authmw.AllowLegacy(tendersHandler.RollbackHandler)).Methods(http.MethodPut)
+	r.HandleFunc("/tenders/{tenderID}/versions", authmw.RequireScope(auth.ScopeReadTenders, tendersHandler.TenderVersionsHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/tenders/{tenderID}/versions/{from}/diff/{to}", authmw.RequireScope(auth.ScopeReadTenders, tendersHandler.TenderVersionsDiffHandler)).Methods(http.MethodGet)
 
 	bidsHandler := bids.New(storage)
-	r.HandleFunc("/bids/new", bidsHandler.NewBidHandler).Methods(http.MethodPost)
-	r.HandleFunc("/bids/my", bidsHandler.MyBidsListHandler).Methods(http.MethodGet)
-	r.HandleFunc("/bids/{tenderID}/list", bidsHandler.GetBidsList).Methods(http.MethodGet)
-	r.HandleFunc("/bids/{bidID}/status", bidsHandler.GetBidStatus).Methods(http.MethodGet)
-	r.HandleFunc("/bids/{bidID}/status", bidsHandler.ChangeBidStatus).Methods(http.MethodPut)
-	r.HandleFunc("/bids/{bidID}/edit", bidsHandler.EditBidHandler).Methods(http.MethodPatch)
-	r.HandleFunc("/bids/{bidID}/submit_decision", bidsHandler.SubmitBidHandler).Methods(http.MethodPut)
-	r.HandleFunc("/bids/{bidID}/feedback", bidsHandler.SendFeedbackHandler).Methods(http.MethodPut)
-	r.HandleFunc("/bids/{bidID}/rollback/{version}", bidsHandler.RollbackHandler).Methods(http.MethodPut)
-	r.HandleFunc("/bids/{tenderID}/reviews", bidsHandler.ViewReviewsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/bids/new", authmw.RequireScope(auth.ScopeCreateBids, authmw.AllowLegacy(bidsHandler.NewBidHandler))).Methods(http.MethodPost)
+	r.HandleFunc("/bids/my", authmw.AllowLegacy(bidsHandler.MyBidsListHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/bids/{tenderID}/list", authmw.AllowLegacy(bidsHandler.GetBidsList)).Methods(http.MethodGet)
+	r.HandleFunc("/bids/{bidID}/status", authmw.AllowLegacy(bidsHandler.GetBidStatus)).Methods(http.MethodGet)
+	r.HandleFunc("/bids/{bidID}/status", authmw.AllowLegacy(bidsHandler.ChangeBidStatus)).Methods(http.MethodPut)
+	r.HandleFunc("/bids/{bidID}/edit", authmw.AllowLegacy(bidsHandler.EditBidHandler)).Methods(http.MethodPatch)
+	r.HandleFunc("/bids/{bidID}/submit_decision", authmw.RequireScope(auth.ScopeSubmitDecisions, authmw.AllowLegacy(bidsHandler.SubmitBidHandler))).Methods(http.MethodPut)
+	r.HandleFunc("/bids/{bidID}/feedback", authmw.AllowLegacy(bidsHandler.SendFeedbackHandler)).Methods(http.MethodPut)
+	r.HandleFunc("/bids/{bidID}/rollback/{version}", authmw.AllowLegacy(bidsHandler.RollbackHandler)).Methods(http.MethodPut)
+	r.HandleFunc("/bids/{bidID}/versions", bidsHandler.BidVersionsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/bids/{bidID}/versions/{from}/diff/{to}", bidsHandler.BidVersionsDiffHandler).Methods(http.MethodGet)
+	r.HandleFunc("/bids/{tenderID}/reviews", authmw.AllowLegacy(bidsHandler.ViewReviewsHandler)).Methods(http.MethodGet)
 }
\ No newline at end of file
@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"context"
+	"time"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+// InsertEmployee creates employee and sets his password if passwordHash
+// is not empty. Returns storage.ErrAlreadyExists if username is taken
+func (s *Storage) InsertEmployee(ctx context.Context, newEmployee models.NewEmployeeRequest, passwordHash string) (models.Employee, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.employees {
+		if e.Username == newEmployee.Username {
+			return models.Employee{}, storage.ErrAlreadyExists
+		}
+	}
+
+	e := &employee{
+		ID:        newID(),
+		Username:  newEmployee.Username,
+		FirstName: newEmployee.FirstName,
+		LastName:  newEmployee.LastName,
+		CreatedAt: now(),
+	}
+	s.employees[e.ID] = e
+	if passwordHash != "" {
+		s.setPassword(e.ID, passwordHash)
+	}
+
+	return e.model(), nil
+}
+
+// GetCredentials returns storage.ErrNotFound if user has no password
+func (s *Storage) GetCredentials(ctx context.Context, userID string) (models.Credentials, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.credentials[userID]
+	if !ok {
+		return models.Credentials{}, storage.ErrNotFound
+	}
+
+	return *c, nil
+}
+
+// SetPassword replaces password of user, unlocks account,
+// invalidates pending reset tokens and revokes issued tokens
+func (s *Storage) SetPassword(ctx context.Context, userID, passwordHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.employees[userID]; !ok {
+		return storage.ErrNotFound
+	}
+	s.setPassword(userID, passwordHash)
+
+	return nil
+}
+
+// setPassword does SetPassword and increments version of credentials,
+// caller must hold the lock
+func (s *Storage) setPassword(userID, passwordHash string) {
+	version := 1
+	if c, ok := s.credentials[userID]; ok {
+		version = c.Version + 1
+	}
+	s.credentials[userID] = &models.Credentials{
+		UserID:       userID,
+		PasswordHash: passwordHash,
+		Version:      version,
+	}
+	for hash, reset := range s.passwordResets {
+		if reset.UserID == userID {
+			delete(s.passwordResets, hash)
+		}
+	}
+}
+
+// RecordLoginFailure counts failed attempt, account is locked for
+// lockout once maxAttempts is reached and counter starts over
+func (s *Storage) RecordLoginFailure(ctx context.Context, userID string, maxAttempts int, lockout time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.credentials[userID]
+	if !ok {
+		return nil
+	}
+
+	c.FailedAttempts++
+	if c.FailedAttempts >= maxAttempts {
+		c.FailedAttempts = 0
+		c.LockedUntil = now().Add(lockout)
+	}
+
+	return nil
+}
+
+func (s *Storage) ResetLoginFailures(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.credentials[userID]; ok {
+		c.FailedAttempts = 0
+		c.LockedUntil = time.Time{}
+	}
+
+	return nil
+}
+
+// CreatePasswordReset saves reset token valid for ttl,
+// previous tokens of user are invalidated
+func (s *Storage) CreatePasswordReset(ctx context.Context, userID, tokenHash string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, reset := range s.passwordResets {
+		if reset.UserID == userID {
+			delete(s.passwordResets, hash)
+		}
+	}
+	s.passwordResets[tokenHash] = passwordReset{
+		UserID:    userID,
+		ExpiresAt: now().Add(ttl),
+	}
+
+	return nil
+}
+
+// ResetPassword consumes reset token and sets new password of its
+// user. Returns storage.ErrNotFound if token is unknown or expired
+func (s *Storage) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reset, ok := s.passwordResets[tokenHash]
+	if !ok {
+		return "", storage.ErrNotFound
+	}
+	delete(s.passwordResets, tokenHash)
+	if !now().Before(reset.ExpiresAt) {
+		return "", storage.ErrNotFound
+	}
+	if _, ok := s.employees[reset.UserID]; !ok {
+		return "", storage.ErrNotFound
+	}
+
+	s.setPassword(reset.UserID, passwordHash)
+
+	return reset.UserID, nil
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+func TestLoginFailures(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	userID := s.AddEmployee("user", "", "")
+
+	if _, err := s.GetCredentials(ctx, userID); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected no password, got %v", err)
+	}
+	if err := s.SetPassword(ctx, userID, "hash"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		if err := s.RecordLoginFailure(ctx, userID, 3, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		c, _ := s.GetCredentials(ctx, userID)
+		if c.FailedAttempts != i || !c.LockedUntil.IsZero() {
+			t.Fatalf("attempt %d: expected counted failure without lock, got %+v", i, c)
+		}
+	}
+
+	// the last allowed attempt locks account and starts counter over
+	if err := s.RecordLoginFailure(ctx, userID, 3, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	c, _ := s.GetCredentials(ctx, userID)
+	if c.FailedAttempts != 0 || !time.Now().Before(c.LockedUntil) {
+		t.Fatalf("expected account to be locked, got %+v", c)
+	}
+
+	if err := s.ResetLoginFailures(ctx, userID); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := s.GetCredentials(ctx, userID); c.FailedAttempts != 0 || !c.LockedUntil.IsZero() {
+		t.Fatalf("expected account to be unlocked, got %+v", c)
+	}
+
+	if err := s.SetPassword(ctx, "unknown", "hash"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrNotFound, err)
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	employee, err := s.InsertEmployee(ctx, models.NewEmployeeRequest{Username: "user"}, "old hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RecordLoginFailure(ctx, employee.ID, 1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	// new token invalidates previous one
+	for _, token := range []string{"first", "second"} {
+		if err := s.CreatePasswordReset(ctx, employee.ID, token, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.ResetPassword(ctx, "first", "new hash"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected replaced token to be rejected, got %v", err)
+	}
+
+	userID, err := s.ResetPassword(ctx, "second", "new hash")
+	if err != nil || userID != employee.ID {
+		t.Fatalf("expected password of %s to be reset, got %q, %v", employee.ID, userID, err)
+	}
+	c, _ := s.GetCredentials(ctx, employee.ID)
+	if c.PasswordHash != "new hash" || !c.LockedUntil.IsZero() || c.Version != 2 {
+		t.Fatalf("expected new unlocked password of version 2, got %+v", c)
+	}
+	if _, err := s.ResetPassword(ctx, "second", "another hash"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected used token to be rejected, got %v", err)
+	}
+
+	if err := s.CreatePasswordReset(ctx, employee.ID, "expired", -time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ResetPassword(ctx, "expired", "another hash"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected expired token to be rejected, got %v", err)
+	}
+
+	// setting password invalidates pending tokens
+	if err := s.CreatePasswordReset(ctx, employee.ID, "pending", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetPassword(ctx, employee.ID, "changed hash"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ResetPassword(ctx, "pending", "another hash"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected pending token to be rejected, got %v", err)
+	}
+	if c, _ := s.GetCredentials(ctx, employee.ID); c.Version != 3 {
+		t.Fatalf("expected password of version 3, got %+v", c)
+	}
+
+	if _, err := s.InsertEmployee(ctx, models.NewEmployeeRequest{Username: "user"}, ""); !errors.Is(err, storage.ErrAlreadyExists) {
+		t.Fatalf("expected %v, got %v", storage.ErrAlreadyExists, err)
+	}
+}
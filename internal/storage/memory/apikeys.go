@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+// apiKeyModel returns key with username of the employee it acts for,
+// caller must hold the lock
+func (s *Storage) apiKeyModel(k *apiKey) models.APIKey {
+	key := k.APIKey
+	key.Scopes = append([]string(nil), k.Scopes...)
+	if e, ok := s.employees[k.UserID]; ok {
+		key.CreatedBy = e.Username
+	}
+
+	return key
+}
+
+func (s *Storage) InsertAPIKey(ctx context.Context, organizationID, userID string, newKey models.NewAPIKeyRequest, keyHash, prefix string) (models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := &apiKey{
+		APIKey: models.APIKey{
+			ID:             newID(),
+			Name:           newKey.Name,
+			OrganizationID: organizationID,
+			Prefix:         prefix,
+			Scopes:         append([]string(nil), newKey.Scopes...),
+			CreatedAt:      now(),
+		},
+		UserID:  userID,
+		KeyHash: keyHash,
+	}
+	s.apiKeys[k.ID] = k
+
+	return s.apiKeyModel(k), nil
+}
+
+// GetAPIKeys returns keys of organization including revoked ones
+// ordered by creation time
+func (s *Storage) GetAPIKeys(ctx context.Context, organizationID string, limit, offset int) ([]models.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]models.APIKey, 0)
+	for _, k := range s.apiKeys {
+		if k.OrganizationID == organizationID {
+			keys = append(keys, s.apiKeyModel(k))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	start, end := paginate(len(keys), limit, offset)
+	return keys[start:end], nil
+}
+
+// RotateAPIKey replaces secret of active key, the key then acts on behalf
+// of userID. Returns storage.ErrNotFound if key is not found or revoked
+func (s *Storage) RotateAPIKey(ctx context.Context, keyID, organizationID, userID, keyHash, prefix string) (models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, ok := s.apiKeys[keyID]
+	if !ok || k.OrganizationID != organizationID || k.RevokedAt != nil {
+		return models.APIKey{}, storage.ErrNotFound
+	}
+
+	rotatedAt := now()
+	k.KeyHash = keyHash
+	k.Prefix = prefix
+	k.UserID = userID
+	k.RotatedAt = &rotatedAt
+
+	return s.apiKeyModel(k), nil
+}
+
+// RevokeAPIKey disables key, revoking revoked key changes nothing.
+// Returns storage.ErrNotFound if organization has no such key
+func (s *Storage) RevokeAPIKey(ctx context.Context, keyID, organizationID string) (models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, ok := s.apiKeys[keyID]
+	if !ok || k.OrganizationID != organizationID {
+		return models.APIKey{}, storage.ErrNotFound
+	}
+	if k.RevokedAt == nil {
+		revokedAt := now()
+		k.RevokedAt = &revokedAt
+	}
+
+	return s.apiKeyModel(k), nil
+}
+
+// GetAPIKeyOwner returns active key with hash. Key stops working once its
+// employee is no longer responsible for organization, storage.ErrNotFound
+// is returned then
+func (s *Storage) GetAPIKeyOwner(ctx context.Context, keyHash string) (models.APIKeyOwner, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, k := range s.apiKeys {
+		if k.KeyHash != keyHash || k.RevokedAt != nil {
+			continue
+		}
+		e, ok := s.employees[k.UserID]
+		if !ok || !s.isResponsible(k.OrganizationID, k.UserID) {
+			break
+		}
+
+		return models.APIKeyOwner{
+			ID:             k.ID,
+			OrganizationID: k.OrganizationID,
+			UserID:         k.UserID,
+			Username:       e.Username,
+			Scopes:         append([]string(nil), k.Scopes...),
+		}, nil
+	}
+
+	return models.APIKeyOwner{}, storage.ErrNotFound
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+func TestAPIKeys(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	partnerID := s.AddEmployee("partner", "", "")
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddResponsible(acme, ownerID)
+	s.AddResponsible(acme, partnerID)
+	bidco := s.AddOrganization("bidco", "", "IE")
+
+	newKey := models.NewAPIKeyRequest{Name: "ci", Scopes: []string{"tenders:read"}}
+	key, err := s.InsertAPIKey(ctx, acme, ownerID, newKey, "first hash", "first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.CreatedBy != "owner" {
+		t.Fatalf("expected key created by owner, got %+v", key)
+	}
+
+	owner, err := s.GetAPIKeyOwner(ctx, "first hash")
+	if err != nil || owner.ID != key.ID || owner.UserID != ownerID || owner.OrganizationID != acme {
+		t.Fatalf("expected key to act for owner in acme, got %+v, %v", owner, err)
+	}
+
+	// rotated key acts for employee who rotated it, old secret stops working
+	if _, err := s.RotateAPIKey(ctx, key.ID, bidco, partnerID, "second hash", "second"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected key of another organization to be not found, got %v", err)
+	}
+	rotated, err := s.RotateAPIKey(ctx, key.ID, acme, partnerID, "second hash", "second")
+	if err != nil || rotated.RotatedAt == nil || rotated.Prefix != "second" {
+		t.Fatalf("expected key to be rotated, got %+v, %v", rotated, err)
+	}
+	if _, err := s.GetAPIKeyOwner(ctx, "first hash"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected old secret to stop working, got %v", err)
+	}
+	if owner, err := s.GetAPIKeyOwner(ctx, "second hash"); err != nil || owner.Username != "partner" {
+		t.Fatalf("expected key to act for partner, got %+v, %v", owner, err)
+	}
+
+	revoked, err := s.RevokeAPIKey(ctx, key.ID, acme)
+	if err != nil || revoked.RevokedAt == nil {
+		t.Fatalf("expected key to be revoked, got %+v, %v", revoked, err)
+	}
+	if again, err := s.RevokeAPIKey(ctx, key.ID, acme); err != nil || !again.RevokedAt.Equal(*revoked.RevokedAt) {
+		t.Fatalf("expected repeated revoke to change nothing, got %+v, %v", again, err)
+	}
+	if _, err := s.GetAPIKeyOwner(ctx, "second hash"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected revoked key to stop working, got %v", err)
+	}
+	if _, err := s.RotateAPIKey(ctx, key.ID, acme, ownerID, "third hash", "third"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected revoked key not to be rotated, got %v", err)
+	}
+
+	keys, err := s.GetAPIKeys(ctx, acme, 10, 0)
+	if err != nil || len(keys) != 1 || keys[0].RevokedAt == nil {
+		t.Fatalf("expected revoked key to be listed, got %+v, %v", keys, err)
+	}
+	if keys, _ := s.GetAPIKeys(ctx, bidco, 10, 0); len(keys) != 0 {
+		t.Fatalf("expected no keys of bidco, got %+v", keys)
+	}
+}
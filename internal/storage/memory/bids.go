@@ -0,0 +1,380 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+func sortBids(bids []models.Bid) {
+	sort.Slice(bids, func(i, j int) bool {
+		return bids[i].Name < bids[j].Name
+	})
+}
+
+// InsertBid creates bid on tender which accepts bids, returns
+// storage.ErrNotFound if there is no such tender and
+// domain.ErrTenderClosed if it does not accept bids
+func (s *Storage) InsertBid(ctx context.Context, newBid models.BidRequest, organizationID string) (models.Bid, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tenders[newBid.TenderID]
+	if !ok {
+		return models.Bid{}, fmt.Errorf("cannot insert new bid: tender %s %w", newBid.TenderID, storage.ErrNotFound)
+	}
+	if !domain.TenderAcceptsBids(t.Status) {
+		return models.Bid{}, domain.ErrTenderClosed
+	}
+
+	b := &bid{
+		ID:             newID(),
+		TenderID:       newBid.TenderID,
+		OrganizationID: organizationID,
+		Name:           newBid.Name,
+		Description:    newBid.Description,
+		Status:         domain.BidCreated,
+		AuthorType:     newBid.AuthorType,
+		AuthorID:       newBid.AuthorID,
+		Version:        1,
+		CreatedAt:      now(),
+		UpdatedBy:      newBid.AuthorID,
+	}
+	b.UpdatedAt = b.CreatedAt
+	s.bids[b.ID] = b
+	s.bidsHistory[b.ID] = map[int]bid{b.Version: *b}
+	s.decisions[b.ID] = make(map[string]string)
+
+	return b.model(), nil
+}
+
+// GetMyBidsList returns page of bids made by userID or by organizations
+// he is responsible for, only bids of organizationID if it is not empty
+func (s *Storage) GetMyBidsList(ctx context.Context, limit, offset int, userID, organizationID string) ([]models.Bid, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bids := make([]models.Bid, 0, limit)
+	for _, b := range s.bids {
+		if s.bidViewer(b, userID).Owner && (organizationID == "" || b.OrganizationID == organizationID) {
+			bids = append(bids, b.model())
+		}
+	}
+	sortBids(bids)
+
+	start, end := paginate(len(bids), limit, offset)
+	return bids[start:end], nil
+}
+
+// GetTenderBids returns page of bids on tender which userID can see
+func (s *Storage) GetTenderBids(ctx context.Context, limit, offset int, tenderID, userID string) ([]models.Bid, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bids := make([]models.Bid, 0, limit)
+	for _, b := range s.bids {
+		if b.TenderID == tenderID && domain.BidVisible(b.Status, s.bidViewer(b, userID)) {
+			bids = append(bids, b.model())
+		}
+	}
+	sortBids(bids)
+
+	start, end := paginate(len(bids), limit, offset)
+	return bids[start:end], nil
+}
+
+// BidVisible reports whether userID can see the bid
+func (s *Storage) BidVisible(ctx context.Context, bidID, userID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, ok := s.bids[bidID]
+	return ok && domain.BidVisible(b.Status, s.bidViewer(b, userID)), nil
+}
+
+func (s *Storage) bidViewer(b *bid, userID string) domain.BidViewer {
+	viewer := domain.BidViewer{
+		Owner: b.AuthorID == userID || s.isResponsible(b.OrganizationID, userID),
+	}
+	if t, ok := s.tenders[b.TenderID]; ok {
+		viewer.TenderOwner = s.isResponsible(t.OrganizationID, userID)
+	}
+
+	return viewer
+}
+
+func (s *Storage) GetBidStatus(ctx context.Context, bidID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, ok := s.bids[bidID]
+	if !ok {
+		return "", nil
+	}
+
+	return b.Status, nil
+}
+
+// ChangeBitStatus moves bid to status if domain allows such transition
+func (s *Storage) ChangeBitStatus(ctx context.Context, bidID, status string) (models.Bid, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.bids[bidID]
+	if !ok {
+		return models.Bid{}, fmt.Errorf("cannot update row: bid %s %w", bidID, storage.ErrNotFound)
+	}
+	if err := domain.BidTransition(b.Status, status); err != nil {
+		return models.Bid{}, err
+	}
+	b.Status = status
+
+	return b.model(), nil
+}
+
+// EditBid applies patch to bid and increments version,
+// new version is saved to history like bid_version_trigger does.
+// Bids in final status cannot be edited
+func (s *Storage) EditBid(ctx context.Context, bidID, userID string, patch models.EditBidRequest) (models.Bid, error) {
+	if err := patch.Validate(); err != nil {
+		return models.Bid{}, fmt.Errorf("cannot edit row: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.bids[bidID]
+	if !ok {
+		return models.Bid{}, fmt.Errorf("cannot edit row: bid %s %w", bidID, storage.ErrNotFound)
+	}
+	if domain.BidFinal(b.Status) {
+		return models.Bid{}, domain.ErrFinalStatus
+	}
+
+	updated := *b
+	if patch.Name.Set {
+		updated.Name = patch.Name.Value
+	}
+	if patch.Description.Set {
+		updated.Description = patch.Description.Value
+	}
+
+	updated.Version = b.Version + 1
+	updated.UpdatedBy = userID
+	updated.UpdatedAt = now()
+	*b = updated
+	s.bidsHistory[bidID][b.Version] = *b
+
+	return b.model(), nil
+}
+
+// BidDecision saves decision of userID on bid. Single rejection rejects
+// the bid, approval is reached when number of approvals reaches quorum
+// and then the tender is closed
+func (s *Storage) BidDecision(ctx context.Context, bidID, userID, decision string) (models.Bid, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.bids[bidID]
+	if !ok {
+		return models.Bid{}, storage.ErrNotFound
+	}
+	t := s.tenders[b.TenderID]
+
+	if !domain.TenderAcceptsBids(t.Status) || !domain.BidAcceptsDecisions(b.Status) {
+		return models.Bid{}, storage.ErrDecisionClosed
+	}
+
+	s.decisions[bidID][userID] = decision
+	if decision == models.DecisionRejected {
+		b.Status = domain.BidRejected
+		return b.model(), nil
+	}
+
+	approvals := 0
+	for _, d := range s.decisions[bidID] {
+		if d == models.DecisionApproved {
+			approvals++
+		}
+	}
+
+	responsibles := 0
+	for _, r := range s.responsibles {
+		if r.OrganizationID == t.OrganizationID && domain.RoleAllows(r.Role, domain.ActionDecideBid) {
+			responsibles++
+		}
+	}
+
+	if approvals >= models.DecisionQuorum(responsibles) {
+		b.Status = domain.BidApproved
+		t.Status = domain.TenderClosed
+	}
+
+	return b.model(), nil
+}
+
+// RollbackBid copies editable fields of the requested version
+// forward as a new version. Returns storage.ErrNotFound if bid
+// or version does not exist and domain.ErrFinalStatus if bid
+// is in final status
+func (s *Storage) RollbackBid(ctx context.Context, bidID, userID string, version int) (models.Bid, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.bids[bidID]
+	if !ok {
+		return models.Bid{}, storage.ErrNotFound
+	}
+	if domain.BidFinal(b.Status) {
+		return models.Bid{}, domain.ErrFinalStatus
+	}
+	old, ok := s.bidsHistory[bidID][version]
+	if !ok {
+		return models.Bid{}, storage.ErrNotFound
+	}
+
+	b.Name = old.Name
+	b.Description = old.Description
+	b.Version++
+	b.UpdatedBy = userID
+	b.UpdatedAt = now()
+	s.bidsHistory[bidID][b.Version] = *b
+
+	return b.model(), nil
+}
+
+// GetBidVersions returns page of bid versions starting from the newest
+func (s *Storage) GetBidVersions(ctx context.Context, bidID string, limit, offset int) ([]models.BidVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	history := s.bidsHistory[bidID]
+	versions := make([]models.BidVersion, 0, len(history))
+	for _, snapshot := range history {
+		versions = append(versions, s.bidVersion(snapshot))
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version > versions[j].Version
+	})
+
+	start, end := paginate(len(versions), limit, offset)
+	return versions[start:end], nil
+}
+
+// GetBidVersion returns single version of bid
+// or storage.ErrNotFound if it does not exist
+func (s *Storage) GetBidVersion(ctx context.Context, bidID string, version int) (models.BidVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, ok := s.bidsHistory[bidID][version]
+	if !ok {
+		return models.BidVersion{}, storage.ErrNotFound
+	}
+
+	return s.bidVersion(snapshot), nil
+}
+
+func (s *Storage) bidVersion(snapshot bid) models.BidVersion {
+	v := models.BidVersion{
+		Version:     snapshot.Version,
+		Name:        snapshot.Name,
+		Description: snapshot.Description,
+		Status:      snapshot.Status,
+		ChangedAt:   snapshot.UpdatedAt,
+	}
+	if e, ok := s.employees[snapshot.UpdatedBy]; ok {
+		v.ChangedBy = e.Username
+	}
+
+	return v
+}
+
+func (s *Storage) GetBidTenderID(ctx context.Context, bidID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, ok := s.bids[bidID]
+	if !ok {
+		return "", nil
+	}
+
+	return b.TenderID, nil
+}
+
+func (s *Storage) GetBidOrganizationID(ctx context.Context, bidID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, ok := s.bids[bidID]
+	if !ok {
+		return "", nil
+	}
+
+	return b.OrganizationID, nil
+}
+
+func (s *Storage) GetBidByID(ctx context.Context, bidID string) (models.Bid, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, ok := s.bids[bidID]
+	if !ok {
+		return models.Bid{}, fmt.Errorf("cannot get bid: %s %w", bidID, storage.ErrNotFound)
+	}
+
+	return b.model(), nil
+}
+
+func (s *Storage) AuthorBidExist(ctx context.Context, authorID, tenderID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, b := range s.bids {
+		if b.TenderID == tenderID && b.AuthorID == authorID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (s *Storage) SendFeedback(ctx context.Context, bidID, userID, description string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.bids[bidID]; !ok {
+		return fmt.Errorf("cannot send feedback: bid %s %w", bidID, storage.ErrNotFound)
+	}
+
+	s.feedback = append(s.feedback, feedback{
+		Feedback: models.Feedback{
+			ID:          newID(),
+			Description: description,
+			CreatedAt:   now(),
+		},
+		BidID:     bidID,
+		CreatorID: userID,
+	})
+
+	return nil
+}
+
+func (s *Storage) GetFeedback(ctx context.Context, authorUserID string, limit, offset int) ([]models.Feedback, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	feedbackList := make([]models.Feedback, 0, limit)
+	for _, f := range s.feedback {
+		b, ok := s.bids[f.BidID]
+		if ok && b.AuthorID == authorUserID {
+			feedbackList = append(feedbackList, f.Feedback)
+		}
+	}
+
+	start, end := paginate(len(feedbackList), limit, offset)
+	return feedbackList[start:end], nil
+}
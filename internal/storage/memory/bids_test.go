@@ -0,0 +1,410 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+func TestBidStatus(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	bidderID := s.AddEmployee("bidder", "", "")
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddResponsible(acme, ownerID)
+	bidco := s.AddOrganization("bidco", "", "IE")
+	s.AddResponsible(bidco, bidderID)
+
+	bidID := newPublishedBid(t, s, newPublishedTender(t, s, acme, ownerID), bidco, bidderID)
+
+	steps := []struct {
+		status string
+		err    error
+	}{
+		{domain.BidCreated, domain.ErrIllegalTransition},
+		{domain.BidApproved, nil},
+		{domain.BidCanceled, domain.ErrIllegalTransition},
+	}
+	for _, step := range steps {
+		if _, err := s.ChangeBitStatus(ctx, bidID, step.status); !errors.Is(err, step.err) {
+			t.Fatalf("%s: expected %v, got %v", step.status, step.err, err)
+		}
+	}
+
+	if status, err := s.GetBidStatus(ctx, bidID); err != nil || status != domain.BidApproved {
+		t.Fatalf("expected stored status %s, got %q, %v", domain.BidApproved, status, err)
+	}
+	if _, err := s.ChangeBitStatus(ctx, "unknown", domain.BidCanceled); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrNotFound, err)
+	}
+}
+
+func TestInsertBid(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	bidderID := s.AddEmployee("bidder", "", "")
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddResponsible(acme, ownerID)
+
+	tender, err := s.InsertTender(ctx, &models.NewTenderRequest{
+		Name:           "Доставка",
+		ServiceType:    "Delivery",
+		OrganizationID: acme,
+	}, ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newBid := models.BidRequest{Name: "Доставим", TenderID: tender.ID, AuthorType: "User", AuthorID: bidderID}
+
+	steps := []struct {
+		status string
+		err    error
+	}{
+		{domain.TenderCreated, domain.ErrTenderClosed},
+		{domain.TenderPublished, nil},
+		{domain.TenderClosed, domain.ErrTenderClosed},
+	}
+	for _, step := range steps {
+		if _, err := s.ChangeTenderStatus(ctx, tender.ID, step.status); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.InsertBid(ctx, newBid, ""); !errors.Is(err, step.err) {
+			t.Fatalf("%s: expected %v, got %v", step.status, step.err, err)
+		}
+	}
+
+	newBid.TenderID = "unknown"
+	if _, err := s.InsertBid(ctx, newBid, ""); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrNotFound, err)
+	}
+}
+
+func TestEditBid(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	bidderID := s.AddEmployee("bidder", "", "")
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddResponsible(acme, ownerID)
+	bidco := s.AddOrganization("bidco", "", "IE")
+	s.AddResponsible(bidco, bidderID)
+
+	bidID := newPublishedBid(t, s, newPublishedTender(t, s, acme, ownerID), bidco, bidderID)
+
+	steps := []struct {
+		patch       string
+		err         error
+		name        string
+		description string
+		version     int
+	}{
+		{`{"description":"За два дня"}`, nil, "Доставим", "За два дня", 2},
+		{`{"name":"Доставим быстро","description":null}`, nil, "Доставим быстро", "", 3},
+		{`{"name":null}`, models.ErrNullNotAllowed, "", "", 0},
+		{`{}`, models.ErrEmptyPatch, "", "", 0},
+	}
+	for _, step := range steps {
+		var patch models.EditBidRequest
+		if err := json.Unmarshal([]byte(step.patch), &patch); err != nil {
+			t.Fatal(err)
+		}
+
+		bid, err := s.EditBid(ctx, bidID, bidderID, patch)
+		if !errors.Is(err, step.err) {
+			t.Fatalf("%s: expected %v, got %v", step.patch, step.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		if bid.Name != step.name || bid.Description != step.description || bid.Version != step.version {
+			t.Fatalf("%s: expected %q, %q, version %d, got %+v", step.patch, step.name, step.description, step.version, bid)
+		}
+	}
+
+	if _, err := s.ChangeBitStatus(ctx, bidID, domain.BidCanceled); err != nil {
+		t.Fatal(err)
+	}
+	patch := models.EditBidRequest{Name: models.Optional[string]{Set: true, Value: "Передумали"}}
+	if _, err := s.EditBid(ctx, bidID, bidderID, patch); !errors.Is(err, domain.ErrFinalStatus) {
+		t.Fatalf("expected %v, got %v", domain.ErrFinalStatus, err)
+	}
+}
+
+func TestRollbackBid(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	bidderID := s.AddEmployee("bidder", "", "")
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddResponsible(acme, ownerID)
+	bidco := s.AddOrganization("bidco", "", "IE")
+	s.AddResponsible(bidco, bidderID)
+
+	bidID := newPublishedBid(t, s, newPublishedTender(t, s, acme, ownerID), bidco, bidderID)
+	for _, description := range []string{"За два дня", "За один день"} {
+		patch := models.EditBidRequest{Description: models.Optional[string]{Set: true, Value: description}}
+		if _, err := s.EditBid(ctx, bidID, bidderID, patch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bid, err := s.RollbackBid(ctx, bidID, bidderID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bid.Version != 4 || bid.Description != "За два дня" || bid.Status != domain.BidPublished {
+		t.Fatalf("expected version 4 copying version 2 with status kept, got %+v", bid)
+	}
+
+	// rollback adds a version and keeps history untouched
+	descriptions := map[int]string{1: "", 2: "За два дня", 3: "За один день", 4: "За два дня"}
+	for version, description := range descriptions {
+		v, err := s.GetBidVersion(ctx, bidID, version)
+		if err != nil || v.Description != description {
+			t.Fatalf("version %d: expected %q, got %+v, %v", version, description, v, err)
+		}
+	}
+
+	if _, err := s.RollbackBid(ctx, bidID, bidderID, 99); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrNotFound, err)
+	}
+
+	if _, err := s.ChangeBitStatus(ctx, bidID, domain.BidCanceled); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RollbackBid(ctx, bidID, bidderID, 1); !errors.Is(err, domain.ErrFinalStatus) {
+		t.Fatalf("expected %v, got %v", domain.ErrFinalStatus, err)
+	}
+}
+
+func TestBidVersions(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	bidderID := s.AddEmployee("bidder", "", "")
+	partnerID := s.AddEmployee("partner", "", "")
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddResponsible(acme, ownerID)
+	bidco := s.AddOrganization("bidco", "", "IE")
+	s.AddResponsible(bidco, bidderID)
+	s.AddResponsible(bidco, partnerID)
+
+	bidID := newPublishedBid(t, s, newPublishedTender(t, s, acme, ownerID), bidco, bidderID)
+	patch := models.EditBidRequest{
+		Name:        models.Optional[string]{Set: true, Value: "Доставим быстро"},
+		Description: models.Optional[string]{Set: true, Value: "За один день"},
+	}
+	if _, err := s.EditBid(ctx, bidID, partnerID, patch); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RollbackBid(ctx, bidID, bidderID, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := s.GetBidVersions(ctx, bidID, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0].Version != 3 || versions[1].Version != 2 {
+		t.Fatalf("expected versions 3 and 2 newest first, got %+v", versions)
+	}
+	if versions[0].ChangedBy != "bidder" || versions[1].ChangedBy != "partner" {
+		t.Fatalf("expected versions changed by bidder and partner, got %+v", versions)
+	}
+	if versions, _ := s.GetBidVersions(ctx, bidID, 2, 2); len(versions) != 1 || versions[0].Version != 1 {
+		t.Fatalf("expected version 1 on second page, got %+v", versions)
+	}
+
+	from, err := s.GetBidVersion(ctx, bidID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := s.GetBidVersion(ctx, bidID, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := models.DiffBidVersions(from, to)
+	want := []models.FieldChange{
+		{Field: "name", From: "Доставим быстро", To: "Доставим"},
+		{Field: "description", From: "За один день", To: ""},
+	}
+	if diff.From != 2 || diff.To != 3 || !slices.Equal(diff.Changes, want) {
+		t.Fatalf("expected changes %+v, got %+v", want, diff)
+	}
+
+	if _, err := s.GetBidVersion(ctx, bidID, 99); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrNotFound, err)
+	}
+}
+
+func TestBidDecisionQuorum(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	acme := s.AddOrganization("acme", "", "LLC")
+	ownerIDs := make([]string, 4)
+	for i := range ownerIDs {
+		ownerIDs[i] = s.AddEmployee(fmt.Sprintf("owner%d", i), "", "")
+		s.AddResponsible(acme, ownerIDs[i])
+	}
+	bidderID := s.AddEmployee("bidder", "", "")
+	bidco := s.AddOrganization("bidco", "", "IE")
+	s.AddResponsible(bidco, bidderID)
+
+	tenderID := newPublishedTender(t, s, acme, ownerIDs[0])
+	approvedID := newPublishedBid(t, s, tenderID, bidco, bidderID)
+	rejectedID := newPublishedBid(t, s, tenderID, bidco, bidderID)
+
+	// four responsibles need three approvals, repeated approval counts once
+	for _, ownerID := range []string{ownerIDs[0], ownerIDs[0], ownerIDs[1]} {
+		bid, err := s.BidDecision(ctx, approvedID, ownerID, models.DecisionApproved)
+		if err != nil || bid.Status != domain.BidPublished {
+			t.Fatalf("expected bid to wait for quorum, got %+v, %v", bid, err)
+		}
+	}
+
+	bid, err := s.BidDecision(ctx, rejectedID, ownerIDs[3], models.DecisionRejected)
+	if err != nil || bid.Status != domain.BidRejected {
+		t.Fatalf("expected single rejection to reject bid, got %+v, %v", bid, err)
+	}
+	if status, _ := s.GetTenderStatus(ctx, tenderID); status != StatusPublished {
+		t.Fatalf("expected rejection to keep tender open, got %s", status)
+	}
+
+	bid, err = s.BidDecision(ctx, approvedID, ownerIDs[2], models.DecisionApproved)
+	if err != nil || bid.Status != domain.BidApproved {
+		t.Fatalf("expected bid to be approved on quorum, got %+v, %v", bid, err)
+	}
+	if status, _ := s.GetTenderStatus(ctx, tenderID); status != StatusClosed {
+		t.Fatalf("expected tender to be closed on approval, got %s", status)
+	}
+
+	if _, err := s.BidDecision(ctx, approvedID, ownerIDs[3], models.DecisionRejected); !errors.Is(err, storage.ErrDecisionClosed) {
+		t.Fatalf("expected %v, got %v", storage.ErrDecisionClosed, err)
+	}
+	if _, err := s.BidDecision(ctx, "unknown", ownerIDs[3], models.DecisionApproved); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrNotFound, err)
+	}
+}
+
+// TestConcurrentBidDecisions approves two bids of the same tender by all
+// responsibles at once, exactly one bid must win
+func TestConcurrentBidDecisions(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	acme := s.AddOrganization("acme", "", "LLC")
+	ownerIDs := make([]string, 3)
+	for i := range ownerIDs {
+		ownerIDs[i] = s.AddEmployee(fmt.Sprintf("owner%d", i), "", "")
+		s.AddResponsible(acme, ownerIDs[i])
+	}
+	bidderID := s.AddEmployee("bidder", "", "")
+	bidco := s.AddOrganization("bidco", "", "IE")
+	s.AddResponsible(bidco, bidderID)
+
+	tenderID := newPublishedTender(t, s, acme, ownerIDs[0])
+	bidIDs := []string{
+		newPublishedBid(t, s, tenderID, bidco, bidderID),
+		newPublishedBid(t, s, tenderID, bidco, bidderID),
+	}
+
+	var wg sync.WaitGroup
+	for _, bidID := range bidIDs {
+		for _, ownerID := range ownerIDs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := s.BidDecision(ctx, bidID, ownerID, models.DecisionApproved)
+				if err != nil && !errors.Is(err, storage.ErrDecisionClosed) {
+					t.Error(err)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	approved := 0
+	for _, bidID := range bidIDs {
+		if status, _ := s.GetBidStatus(ctx, bidID); status == domain.BidApproved {
+			approved++
+		}
+	}
+	if approved != 1 {
+		t.Fatalf("expected exactly one approved bid, got %d", approved)
+	}
+}
+
+func TestBidVisibility(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	bidderID := s.AddEmployee("bidder", "", "")
+	partnerID := s.AddEmployee("partner", "", "")
+	strangerID := s.AddEmployee("stranger", "", "")
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddResponsible(acme, ownerID)
+	bidco := s.AddOrganization("bidco", "", "IE")
+	s.AddResponsible(bidco, bidderID)
+	s.AddResponsible(bidco, partnerID)
+
+	tenderID := newPublishedTender(t, s, acme, ownerID)
+	draft, err := s.InsertBid(ctx, models.BidRequest{
+		Name:       "Черновик",
+		TenderID:   tenderID,
+		AuthorType: "User",
+		AuthorID:   bidderID,
+	}, bidco)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publishedID := newPublishedBid(t, s, tenderID, bidco, bidderID)
+	canceledID := newPublishedBid(t, s, tenderID, bidco, bidderID)
+	if _, err := s.ChangeBitStatus(ctx, canceledID, domain.BidCanceled); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		userID  string
+		visible map[string]bool
+	}{
+		{"author", bidderID, map[string]bool{draft.ID: true, publishedID: true, canceledID: true}},
+		{"responsible of author organization", partnerID, map[string]bool{draft.ID: true, publishedID: true, canceledID: true}},
+		{"tender owner", ownerID, map[string]bool{draft.ID: false, publishedID: true, canceledID: false}},
+		{"stranger", strangerID, map[string]bool{draft.ID: false, publishedID: false, canceledID: false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bids, err := s.GetTenderBids(ctx, 10, 0, tenderID, tt.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			listed := make(map[string]bool)
+			for _, bid := range bids {
+				listed[bid.ID] = true
+			}
+
+			for bidID, visible := range tt.visible {
+				if listed[bidID] != visible {
+					t.Errorf("bid %s: expected listed %v", bidID, visible)
+				}
+				if ok, _ := s.BidVisible(ctx, bidID, tt.userID); ok != visible {
+					t.Errorf("bid %s: expected visible %v", bidID, visible)
+				}
+			}
+		})
+	}
+
+	my, err := s.GetMyBidsList(ctx, 10, 0, partnerID, "")
+	if err != nil || len(my) != 3 {
+		t.Fatalf("expected bids of partner organization, got %v, %v", my, err)
+	}
+}
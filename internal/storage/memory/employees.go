@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+func (e employee) model() models.Employee {
+	return models.Employee{
+		ID:            e.ID,
+		Username:      e.Username,
+		FirstName:     e.FirstName,
+		LastName:      e.LastName,
+		CreatedAt:     e.CreatedAt,
+		DeactivatedAt: e.DeactivatedAt,
+	}
+}
+
+// GetEmployees returns page of employees including deactivated
+// ones ordered by username
+func (s *Storage) GetEmployees(ctx context.Context, limit, offset int) ([]models.Employee, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	employees := make([]models.Employee, 0, len(s.employees))
+	for _, e := range s.employees {
+		employees = append(employees, e.model())
+	}
+	sort.Slice(employees, func(i, j int) bool {
+		return employees[i].Username < employees[j].Username
+	})
+
+	start, end := paginate(len(employees), limit, offset)
+	return employees[start:end], nil
+}
+
+// GetEmployee returns employee even if he is deactivated,
+// storage.ErrNotFound if there is no such employee
+func (s *Storage) GetEmployee(ctx context.Context, username string) (models.Employee, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, e := range s.employees {
+		if e.Username == username {
+			return e.model(), nil
+		}
+	}
+
+	return models.Employee{}, fmt.Errorf("cannot get employee: %s %w", username, storage.ErrNotFound)
+}
+
+// EditEmployee applies patch to active employee,
+// returns storage.ErrNotFound if there is no such employee
+func (s *Storage) EditEmployee(ctx context.Context, userID string, patch models.EditEmployeeRequest) (models.Employee, error) {
+	if err := patch.Validate(); err != nil {
+		return models.Employee{}, fmt.Errorf("cannot change employee: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.employees[userID]
+	if !ok || e.DeactivatedAt != nil {
+		return models.Employee{}, fmt.Errorf("cannot change employee: %s %w", userID, storage.ErrNotFound)
+	}
+	if patch.FirstName.Set {
+		e.FirstName = patch.FirstName.Value
+	}
+	if patch.LastName.Set {
+		e.LastName = patch.LastName.Value
+	}
+
+	return e.model(), nil
+}
+
+// DeactivateEmployee marks employee deactivated and removes him from
+// responsibles of organizations, his tenders, bids and feedback stay.
+// Returns storage.ErrNotFound if there is no such active employee and
+// domain.ErrLastOwner if he is the only owner of some organization
+func (s *Storage) DeactivateEmployee(ctx context.Context, userID string) (models.Employee, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.employees[userID]
+	if !ok || e.DeactivatedAt != nil {
+		return models.Employee{}, fmt.Errorf("cannot deactivate employee: %s %w", userID, storage.ErrNotFound)
+	}
+	for _, r := range s.responsibles {
+		if r.UserID == userID && r.Role == domain.RoleOwner && s.lastOwner(r.OrganizationID, userID) {
+			return models.Employee{}, domain.ErrLastOwner
+		}
+	}
+
+	s.responsibles = slices.DeleteFunc(s.responsibles, func(r responsible) bool {
+		return r.UserID == userID
+	})
+	deactivatedAt := now()
+	e.DeactivatedAt = &deactivatedAt
+
+	return e.model(), nil
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+func TestEditEmployee(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	userID := s.AddEmployee("user", "Ivan", "Ivanov")
+
+	var patch models.EditEmployeeRequest
+	if err := json.Unmarshal([]byte(`{"firstName":"Petr","lastName":null}`), &patch); err != nil {
+		t.Fatal(err)
+	}
+	edited, err := s.EditEmployee(ctx, userID, patch)
+	if err != nil || edited.Username != "user" || edited.FirstName != "Petr" || edited.LastName != "" {
+		t.Fatalf("expected first name changed and last name cleared, got %+v, %v", edited, err)
+	}
+
+	if _, err := s.EditEmployee(ctx, userID, models.EditEmployeeRequest{}); !errors.Is(err, models.ErrEmptyPatch) {
+		t.Fatalf("expected %v, got %v", models.ErrEmptyPatch, err)
+	}
+	if _, err := s.EditEmployee(ctx, "unknown", patch); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrNotFound, err)
+	}
+}
+
+func TestDeactivateEmployee(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	userID := s.AddEmployee("user", "", "")
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddResponsible(acme, ownerID)
+	s.AddMember(acme, userID, domain.RoleApprover)
+
+	if _, err := s.DeactivateEmployee(ctx, ownerID); !errors.Is(err, domain.ErrLastOwner) {
+		t.Fatalf("expected %v, got %v", domain.ErrLastOwner, err)
+	}
+
+	_, err := s.InsertAPIKey(ctx, acme, userID, models.NewAPIKeyRequest{Name: "ci", Scopes: []string{"tenders:read"}}, "hash", "prefix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetAPIKeyOwner(ctx, "hash"); err != nil {
+		t.Fatalf("expected key to work, got %v", err)
+	}
+
+	deactivated, err := s.DeactivateEmployee(ctx, userID)
+	if err != nil || deactivated.DeactivatedAt == nil {
+		t.Fatalf("expected employee to be deactivated, got %+v, %v", deactivated, err)
+	}
+
+	// deactivated employee loses organizations and his key stops working
+	if s.isResponsible(acme, userID) {
+		t.Error("deactivated employee is still responsible")
+	}
+	if _, err := s.GetAPIKeyOwner(ctx, "hash"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("expected key of deactivated employee to stop working, got %v", err)
+	}
+
+	// he cannot sign in, but stays in directory
+	if exists, _ := s.UserExists(ctx, userID); exists {
+		t.Error("deactivated employee exists")
+	}
+	if id, _ := s.GetUserID(ctx, "user"); id != "" {
+		t.Errorf("expected no id of deactivated employee, got %q", id)
+	}
+	if e, err := s.GetEmployee(ctx, "user"); err != nil || e.DeactivatedAt == nil {
+		t.Errorf("expected deactivated employee in directory, got %+v, %v", e, err)
+	}
+	employees, err := s.GetEmployees(ctx, 5, 0)
+	if err != nil || len(employees) != 2 || employees[0].Username != "owner" || employees[1].Username != "user" {
+		t.Fatalf("expected employees ordered by username, got %+v, %v", employees, err)
+	}
+
+	patch := models.EditEmployeeRequest{FirstName: models.Optional[string]{Set: true, Value: "Petr"}}
+	if _, err := s.EditEmployee(ctx, userID, patch); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected deactivated employee to be not found, got %v", err)
+	}
+	if _, err := s.DeactivateEmployee(ctx, userID); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected repeated deactivation to be not found, got %v", err)
+	}
+}
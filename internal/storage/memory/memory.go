@@ -0,0 +1,318 @@
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+const (
+	StatusCreated   = domain.TenderCreated
+	StatusPublished = domain.TenderPublished
+	StatusClosed    = domain.TenderClosed
+	StatusCanceled  = domain.TenderCanceled
+)
+
+type employee struct {
+	ID            string
+	Username      string
+	FirstName     string
+	LastName      string
+	CreatedAt     time.Time
+	DeactivatedAt *time.Time
+}
+
+type passwordReset struct {
+	UserID    string
+	ExpiresAt time.Time
+}
+
+type apiKey struct {
+	models.APIKey
+	UserID  string
+	KeyHash string
+}
+
+type organization struct {
+	ID          string
+	Name        string
+	Description string
+	Type        string
+	CreatedAt   time.Time
+}
+
+type responsible struct {
+	OrganizationID string
+	UserID         string
+	Role           string
+}
+
+type tender struct {
+	models.Tender
+	CreatorID string
+	UpdatedBy string
+	UpdatedAt time.Time
+}
+
+type bid struct {
+	ID             string
+	TenderID       string
+	OrganizationID string
+	Name           string
+	Description    string
+	Status         string
+	AuthorType     string
+	AuthorID       string
+	Version        int
+	CreatedAt      time.Time
+	UpdatedBy      string
+	UpdatedAt      time.Time
+}
+
+func (b bid) model() models.Bid {
+	return models.Bid{
+		ID:          b.ID,
+		Name:        b.Name,
+		Description: b.Description,
+		Status:      b.Status,
+		TenderID:    b.TenderID,
+		AuthorType:  b.AuthorType,
+		AuthorID:    b.AuthorID,
+		Version:     b.Version,
+		CreatedAt:   b.CreatedAt,
+	}
+}
+
+type feedback struct {
+	models.Feedback
+	BidID     string
+	CreatorID string
+}
+
+// Storage keeps all entities in process memory. It mirrors
+// the behaviour of postgres.Storage including version history
+// and is meant for tests and local demos
+type Storage struct {
+	mu sync.RWMutex
+
+	employees      map[string]*employee
+	organizations  map[string]*organization
+	responsibles   []responsible
+	tenders        map[string]*tender
+	tendersHistory map[string]map[int]tender
+	bids           map[string]*bid
+	bidsHistory    map[string]map[int]bid
+	decisions      map[string]map[string]string
+	feedback       []feedback
+	credentials    map[string]*models.Credentials
+	passwordResets map[string]passwordReset
+	apiKeys        map[string]*apiKey
+}
+
+var _ storage.Storage = (*Storage)(nil)
+
+// New returns empty in-memory storage
+func New() *Storage {
+	return &Storage{
+		employees:      make(map[string]*employee),
+		organizations:  make(map[string]*organization),
+		tenders:        make(map[string]*tender),
+		tendersHistory: make(map[string]map[int]tender),
+		bids:           make(map[string]*bid),
+		bidsHistory:    make(map[string]map[int]bid),
+		decisions:      make(map[string]map[string]string),
+		credentials:    make(map[string]*models.Credentials),
+		passwordResets: make(map[string]passwordReset),
+		apiKeys:        make(map[string]*apiKey),
+	}
+}
+
+// AddEmployee creates employee with provided username and returns his id
+func (s *Storage) AddEmployee(username, firstName, lastName string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := &employee{
+		ID:        newID(),
+		Username:  username,
+		FirstName: firstName,
+		LastName:  lastName,
+		CreatedAt: now(),
+	}
+	s.employees[e.ID] = e
+
+	return e.ID
+}
+
+// AddOrganization creates organization and returns its id
+func (s *Storage) AddOrganization(name, description, organizationType string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := &organization{
+		ID:          newID(),
+		Name:        name,
+		Description: description,
+		Type:        organizationType,
+		CreatedAt:   now(),
+	}
+	s.organizations[o.ID] = o
+
+	return o.ID
+}
+
+// AddResponsible makes user owner of organization
+func (s *Storage) AddResponsible(organizationID, userID string) {
+	s.AddMember(organizationID, userID, domain.RoleOwner)
+}
+
+// AddMember makes user responsible for organization with role
+func (s *Storage) AddMember(organizationID, userID, role string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.setRole(organizationID, userID, role)
+}
+
+// Fixtures describes employees and organizations loaded with Seed
+type Fixtures struct {
+	Organizations []struct {
+		Name         string   `json:"name"`
+		Description  string   `json:"description"`
+		Type         string   `json:"type"`
+		Responsibles []string `json:"responsibles"`
+	} `json:"organizations"`
+	Employees []struct {
+		Username  string `json:"username"`
+		FirstName string `json:"firstName"`
+		LastName  string `json:"lastName"`
+	} `json:"employees"`
+}
+
+// SeedFile reads Fixtures from json file and loads them into storage
+func (s *Storage) SeedFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read fixtures: %w", err)
+	}
+
+	var fixtures Fixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return fmt.Errorf("cannot parse fixtures: %w", err)
+	}
+
+	return s.Seed(fixtures)
+}
+
+// Seed loads employees and organizations. Responsibles are
+// referenced by username and must be listed in Employees
+func (s *Storage) Seed(fixtures Fixtures) error {
+	usernames := make(map[string]string, len(fixtures.Employees))
+	for _, e := range fixtures.Employees {
+		usernames[e.Username] = s.AddEmployee(e.Username, e.FirstName, e.LastName)
+	}
+
+	for _, o := range fixtures.Organizations {
+		organizationID := s.AddOrganization(o.Name, o.Description, o.Type)
+		for _, username := range o.Responsibles {
+			userID, ok := usernames[username]
+			if !ok {
+				return fmt.Errorf("unknown responsible %q of organization %q", username, o.Name)
+			}
+			s.AddResponsible(organizationID, userID)
+		}
+	}
+
+	return nil
+}
+
+// GetUserID returns id of employee with provided username
+// or empty string if there is no such active employee
+func (s *Storage) GetUserID(ctx context.Context, username string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, e := range s.employees {
+		if e.Username == username && e.DeactivatedAt == nil {
+			return e.ID, nil
+		}
+	}
+
+	return "", nil
+}
+
+// GetUsername returns empty string for unknown or deactivated employee
+func (s *Storage) GetUsername(ctx context.Context, userID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.employees[userID]
+	if !ok || e.DeactivatedAt != nil {
+		return "", nil
+	}
+
+	return e.Username, nil
+}
+
+// UserExists reports whether active employee with userID exists
+func (s *Storage) UserExists(ctx context.Context, userID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.employees[userID]
+	return ok && e.DeactivatedAt == nil, nil
+}
+
+// isResponsible reports whether userID is responsible for organization,
+// caller must hold the lock
+func (s *Storage) isResponsible(organizationID, userID string) bool {
+	return s.member(organizationID, userID) >= 0
+}
+
+// paginate returns bounds of page inside slice with length n
+func paginate(n, limit, offset int) (int, int) {
+	if limit < 0 {
+		limit = 0
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > n {
+		offset = n
+	}
+	end := offset + limit
+	if end > n {
+		end = n
+	}
+
+	return offset, end
+}
+
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("cannot generate id: %s", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+func sortTenders(tenders []models.Tender) {
+	sort.Slice(tenders, func(i, j int) bool {
+		return tenders[i].Name < tenders[j].Name
+	})
+}
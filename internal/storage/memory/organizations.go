@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+func (o organization) model() models.Organization {
+	return models.Organization{
+		ID:          o.ID,
+		Name:        o.Name,
+		Description: o.Description,
+		Type:        o.Type,
+		CreatedAt:   o.CreatedAt,
+	}
+}
+
+// InsertOrganization creates organization and makes ownerID its owner
+func (s *Storage) InsertOrganization(ctx context.Context, newOrganization models.NewOrganizationRequest, ownerID string) (models.Organization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := &organization{
+		ID:          newID(),
+		Name:        newOrganization.Name,
+		Description: newOrganization.Description,
+		Type:        newOrganization.Type,
+		CreatedAt:   now(),
+	}
+	s.organizations[o.ID] = o
+	s.setRole(o.ID, ownerID, domain.RoleOwner)
+
+	return o.model(), nil
+}
+
+// GetOrganization returns storage.ErrNotFound if organization does not exist
+func (s *Storage) GetOrganization(ctx context.Context, organizationID string) (models.Organization, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	o, ok := s.organizations[organizationID]
+	if !ok {
+		return models.Organization{}, fmt.Errorf("cannot get organization: %s %w", organizationID, storage.ErrNotFound)
+	}
+
+	return o.model(), nil
+}
+
+// EditOrganization applies patch, returns storage.ErrNotFound
+// if organization does not exist
+func (s *Storage) EditOrganization(ctx context.Context, organizationID string, patch models.EditOrganizationRequest) (models.Organization, error) {
+	if err := patch.Validate(); err != nil {
+		return models.Organization{}, fmt.Errorf("cannot change organization: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.organizations[organizationID]
+	if !ok {
+		return models.Organization{}, fmt.Errorf("cannot change organization: %s %w", organizationID, storage.ErrNotFound)
+	}
+
+	if patch.Name.Set {
+		o.Name = patch.Name.Value
+	}
+	if patch.Description.Set {
+		o.Description = patch.Description.Value
+	}
+	if patch.Type.Set {
+		o.Type = patch.Type.Value
+	}
+
+	return o.model(), nil
+}
+
+// DeleteOrganization removes organization with its tenders, bids,
+// responsibles and API keys like ON DELETE CASCADE does. Returns
+// storage.ErrNotFound if organization does not exist and
+// domain.ErrOrganizationInUse if domain.OrganizationRemovable refuses
+func (s *Storage) DeleteOrganization(ctx context.Context, organizationID string) (models.Organization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.organizations[organizationID]
+	if !ok {
+		return models.Organization{}, fmt.Errorf("cannot delete organization: %s %w", organizationID, storage.ErrNotFound)
+	}
+
+	var tenderStatuses, bidStatuses, receivedBidStatuses []string
+	for _, t := range s.tenders {
+		if t.OrganizationID == organizationID {
+			tenderStatuses = append(tenderStatuses, t.Status)
+		}
+	}
+	for _, b := range s.bids {
+		if b.OrganizationID == organizationID {
+			bidStatuses = append(bidStatuses, b.Status)
+		}
+		if t, ok := s.tenders[b.TenderID]; ok && t.OrganizationID == organizationID {
+			receivedBidStatuses = append(receivedBidStatuses, b.Status)
+		}
+	}
+	if !domain.OrganizationRemovable(tenderStatuses, bidStatuses, receivedBidStatuses) {
+		return models.Organization{}, domain.ErrOrganizationInUse
+	}
+
+	for id, t := range s.tenders {
+		if t.OrganizationID == organizationID {
+			delete(s.tenders, id)
+			delete(s.tendersHistory, id)
+		}
+	}
+	for id, b := range s.bids {
+		_, tenderExists := s.tenders[b.TenderID]
+		if b.OrganizationID == organizationID || !tenderExists {
+			s.deleteBid(id)
+		}
+	}
+	for id, k := range s.apiKeys {
+		if k.OrganizationID == organizationID {
+			delete(s.apiKeys, id)
+		}
+	}
+	s.responsibles = slices.DeleteFunc(s.responsibles, func(r responsible) bool {
+		return r.OrganizationID == organizationID
+	})
+	delete(s.organizations, organizationID)
+
+	return o.model(), nil
+}
+
+// deleteBid removes bid with its history, decisions and feedback,
+// caller must hold the lock
+func (s *Storage) deleteBid(bidID string) {
+	delete(s.bids, bidID)
+	delete(s.bidsHistory, bidID)
+	delete(s.decisions, bidID)
+	s.feedback = slices.DeleteFunc(s.feedback, func(f feedback) bool {
+		return f.BidID == bidID
+	})
+}
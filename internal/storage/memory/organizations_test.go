@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+// newPublishedTender creates tender of organization on behalf of
+// creator and publishes it
+func newPublishedTender(t *testing.T, s *Storage, organizationID, creatorID string) string {
+	t.Helper()
+
+	tender, err := s.InsertTender(context.Background(), &models.NewTenderRequest{
+		Name:           "Доставка",
+		ServiceType:    "Delivery",
+		OrganizationID: organizationID,
+	}, creatorID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ChangeTenderStatus(context.Background(), tender.ID, StatusPublished); err != nil {
+		t.Fatal(err)
+	}
+
+	return tender.ID
+}
+
+// newPublishedBid creates bid of author on behalf of organization
+// and publishes it
+func newPublishedBid(t *testing.T, s *Storage, tenderID, organizationID, authorID string) string {
+	t.Helper()
+
+	bid, err := s.InsertBid(context.Background(), models.BidRequest{
+		Name:       "Доставим",
+		TenderID:   tenderID,
+		AuthorType: "User",
+		AuthorID:   authorID,
+	}, organizationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ChangeBitStatus(context.Background(), bid.ID, domain.BidPublished); err != nil {
+		t.Fatal(err)
+	}
+
+	return bid.ID
+}
+
+func TestOrganizations(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+
+	created, err := s.InsertOrganization(ctx, models.NewOrganizationRequest{
+		Name:        "acme",
+		Description: "Поставки оборудования",
+		Type:        domain.OrganizationLLC,
+	}, ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if role, _ := s.GetRole(ctx, created.ID, ownerID); role != domain.RoleOwner {
+		t.Fatalf("expected creator to be owner, got %q", role)
+	}
+
+	var patch models.EditOrganizationRequest
+	if err := json.Unmarshal([]byte(`{"description":null,"type":"JSC"}`), &patch); err != nil {
+		t.Fatal(err)
+	}
+	edited, err := s.EditOrganization(ctx, created.ID, patch)
+	if err != nil || edited.Name != "acme" || edited.Description != "" || edited.Type != domain.OrganizationJSC {
+		t.Fatalf("expected description cleared and type changed, got %+v, %v", edited, err)
+	}
+	if got, err := s.GetOrganization(ctx, created.ID); err != nil || got != edited {
+		t.Fatalf("expected %+v, got %+v, %v", edited, got, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"type":null}`), &patch); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.EditOrganization(ctx, created.ID, patch); !errors.Is(err, models.ErrNullNotAllowed) {
+		t.Fatalf("expected %v, got %v", models.ErrNullNotAllowed, err)
+	}
+	patch = models.EditOrganizationRequest{Name: models.Optional[string]{Set: true, Value: "acme"}}
+	if _, err := s.EditOrganization(ctx, "unknown", patch); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrNotFound, err)
+	}
+	if _, err := s.GetOrganization(ctx, "unknown"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrNotFound, err)
+	}
+}
+
+func TestDeleteOrganization(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	bidderID := s.AddEmployee("bidder", "", "")
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddResponsible(acme, ownerID)
+	bidco := s.AddOrganization("bidco", "", "IE")
+	s.AddResponsible(bidco, bidderID)
+	holding := s.AddOrganization("acme holding", "", "JSC")
+	s.AddResponsible(holding, ownerID)
+
+	tenderID := newPublishedTender(t, s, acme, ownerID)
+	bidID := newPublishedBid(t, s, tenderID, bidco, bidderID)
+	if _, err := s.BidDecision(ctx, bidID, ownerID, models.DecisionApproved); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SendFeedback(ctx, bidID, ownerID, "good"); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := s.GetTenderStatus(ctx, tenderID); status != StatusClosed {
+		t.Fatalf("expected tender to be closed, got %s", status)
+	}
+
+	// closed tender with approved bid of bidco keeps acme, approved bid keeps bidco
+	for _, id := range []string{acme, bidco} {
+		if _, err := s.DeleteOrganization(ctx, id); !errors.Is(err, domain.ErrOrganizationInUse) {
+			t.Fatalf("expected %v, got %v", domain.ErrOrganizationInUse, err)
+		}
+	}
+	if _, err := s.GetBidByID(ctx, bidID); err != nil {
+		t.Fatalf("approved bid was deleted: %v", err)
+	}
+	if feedback, err := s.GetFeedback(ctx, bidderID, 5, 0); err != nil || len(feedback) != 1 {
+		t.Fatalf("feedback on approved bid was deleted: %v, %v", feedback, err)
+	}
+
+	// closed tender without approved bids does not keep organization
+	closedID := newPublishedTender(t, s, holding, ownerID)
+	rejectedID := newPublishedBid(t, s, closedID, bidco, bidderID)
+	if _, err := s.BidDecision(ctx, rejectedID, ownerID, models.DecisionRejected); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ChangeTenderStatus(ctx, closedID, StatusClosed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DeleteOrganization(ctx, holding); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetBidByID(ctx, rejectedID); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected bid on deleted tender to be deleted, got %v", err)
+	}
+	if _, err := s.DeleteOrganization(ctx, holding); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrNotFound, err)
+	}
+}
@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+// member returns index of responsible in s.responsibles or -1,
+// caller must hold the lock
+func (s *Storage) member(organizationID, userID string) int {
+	for i, r := range s.responsibles {
+		if r.OrganizationID == organizationID && r.UserID == userID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// setRole grants role to user replacing the previous one,
+// caller must hold the lock
+func (s *Storage) setRole(organizationID, userID, role string) {
+	if i := s.member(organizationID, userID); i >= 0 {
+		s.responsibles[i].Role = role
+		return
+	}
+
+	s.responsibles = append(s.responsibles, responsible{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		Role:           role,
+	})
+}
+
+// lastOwner reports whether user is the only owner of organization,
+// caller must hold the lock
+func (s *Storage) lastOwner(organizationID, userID string) bool {
+	for _, r := range s.responsibles {
+		if r.OrganizationID == organizationID && r.Role == domain.RoleOwner && r.UserID != userID {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Storage) memberModel(r responsible) models.Member {
+	member := models.Member{UserID: r.UserID, Role: r.Role}
+	if e, ok := s.employees[r.UserID]; ok {
+		member.Username = e.Username
+	}
+
+	return member
+}
+
+// GetRole returns role of user in organization
+// or empty string if he is not responsible for it
+func (s *Storage) GetRole(ctx context.Context, organizationID, userID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if i := s.member(organizationID, userID); i >= 0 {
+		return s.responsibles[i].Role, nil
+	}
+
+	return "", nil
+}
+
+// GetMembers returns responsibles of organization ordered by username
+func (s *Storage) GetMembers(ctx context.Context, organizationID string) ([]models.Member, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	members := make([]models.Member, 0)
+	for _, r := range s.responsibles {
+		if r.OrganizationID == organizationID {
+			members = append(members, s.memberModel(r))
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Username < members[j].Username
+	})
+
+	return members, nil
+}
+
+// SetRole grants role to user, returns domain.ErrLastOwner
+// if the only owner of organization would be demoted
+func (s *Storage) SetRole(ctx context.Context, organizationID, userID, role string) (models.Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i := s.member(organizationID, userID); i >= 0 && s.responsibles[i].Role == domain.RoleOwner &&
+		role != domain.RoleOwner && s.lastOwner(organizationID, userID) {
+		return models.Member{}, domain.ErrLastOwner
+	}
+
+	s.setRole(organizationID, userID, role)
+
+	return s.memberModel(s.responsibles[s.member(organizationID, userID)]), nil
+}
+
+// RevokeRole removes user from responsibles of organization. Returns
+// storage.ErrNotFound if he is not responsible for it and
+// domain.ErrLastOwner if he is the only owner
+func (s *Storage) RevokeRole(ctx context.Context, organizationID, userID string) (models.Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.member(organizationID, userID)
+	if i < 0 {
+		return models.Member{}, storage.ErrNotFound
+	}
+	if s.responsibles[i].Role == domain.RoleOwner && s.lastOwner(organizationID, userID) {
+		return models.Member{}, domain.ErrLastOwner
+	}
+
+	member := s.memberModel(s.responsibles[i])
+	s.responsibles = append(s.responsibles[:i], s.responsibles[i+1:]...)
+
+	return member, nil
+}
+
+// GetMemberships returns organizations user is responsible for
+// and his roles in them ordered by organization name
+func (s *Storage) GetMemberships(ctx context.Context, userID string) ([]models.Membership, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	memberships := make([]models.Membership, 0)
+	for _, r := range s.responsibles {
+		if r.UserID != userID {
+			continue
+		}
+
+		membership := models.Membership{OrganizationID: r.OrganizationID, Role: r.Role}
+		if o, ok := s.organizations[r.OrganizationID]; ok {
+			membership.Name = o.Name
+			membership.Type = o.Type
+		}
+		memberships = append(memberships, membership)
+	}
+	sort.Slice(memberships, func(i, j int) bool {
+		return memberships[i].Name < memberships[j].Name
+	})
+
+	return memberships, nil
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+func TestRoles(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	approverID := s.AddEmployee("approver", "", "")
+	viewerID := s.AddEmployee("viewer", "", "")
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddResponsible(acme, ownerID)
+
+	if _, err := s.SetRole(ctx, acme, approverID, domain.RoleApprover); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SetRole(ctx, acme, viewerID, domain.RoleViewer); err != nil {
+		t.Fatal(err)
+	}
+	members, err := s.GetMembers(ctx, acme)
+	if err != nil || len(members) != 3 || members[0].Username != "approver" || members[2].Role != domain.RoleViewer {
+		t.Fatalf("expected members ordered by username, got %+v, %v", members, err)
+	}
+
+	// the only owner can be neither demoted nor revoked
+	if _, err := s.SetRole(ctx, acme, ownerID, domain.RoleViewer); !errors.Is(err, domain.ErrLastOwner) {
+		t.Fatalf("expected %v, got %v", domain.ErrLastOwner, err)
+	}
+	if _, err := s.RevokeRole(ctx, acme, ownerID); !errors.Is(err, domain.ErrLastOwner) {
+		t.Fatalf("expected %v, got %v", domain.ErrLastOwner, err)
+	}
+	if _, err := s.SetRole(ctx, acme, approverID, domain.RoleOwner); err != nil {
+		t.Fatal(err)
+	}
+	if member, err := s.SetRole(ctx, acme, ownerID, domain.RoleApprover); err != nil || member.Role != domain.RoleApprover {
+		t.Fatalf("expected owner to be demoted once another owner exists, got %+v, %v", member, err)
+	}
+
+	// key of revoked member stops working
+	newKey := models.NewAPIKeyRequest{Name: "ci", Scopes: []string{"tenders:read"}}
+	if _, err := s.InsertAPIKey(ctx, acme, viewerID, newKey, "viewer hash", "viewer"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RevokeRole(ctx, acme, viewerID); err != nil {
+		t.Fatal(err)
+	}
+	if role, _ := s.GetRole(ctx, acme, viewerID); role != "" {
+		t.Fatalf("expected no role after revoke, got %q", role)
+	}
+	if _, err := s.GetAPIKeyOwner(ctx, "viewer hash"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected key of revoked member to stop working, got %v", err)
+	}
+	if _, err := s.RevokeRole(ctx, acme, viewerID); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrNotFound, err)
+	}
+}
+
+// TestQuorumCountsDecidingRoles checks that viewers
+// do not count towards decision quorum
+func TestQuorumCountsDecidingRoles(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddResponsible(acme, ownerID)
+	for _, username := range []string{"viewer1", "viewer2"} {
+		s.AddMember(acme, s.AddEmployee(username, "", ""), domain.RoleViewer)
+	}
+	bidderID := s.AddEmployee("bidder", "", "")
+	bidco := s.AddOrganization("bidco", "", "IE")
+	s.AddResponsible(bidco, bidderID)
+
+	bidID := newPublishedBid(t, s, newPublishedTender(t, s, acme, ownerID), bidco, bidderID)
+	bid, err := s.BidDecision(ctx, bidID, ownerID, models.DecisionApproved)
+	if err != nil || bid.Status != domain.BidApproved {
+		t.Fatalf("expected approval of the only owner to be enough, got %+v, %v", bid, err)
+	}
+}
+
+func TestSeveralOrganizations(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	holding := s.AddOrganization("acme holding", "", "JSC")
+	s.AddResponsible(holding, ownerID)
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddMember(acme, ownerID, domain.RoleProcurementManager)
+
+	memberships, err := s.GetMemberships(ctx, ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.Membership{
+		{OrganizationID: acme, Name: "acme", Type: "LLC", Role: domain.RoleProcurementManager},
+		{OrganizationID: holding, Name: "acme holding", Type: "JSC", Role: domain.RoleOwner},
+	}
+	if len(memberships) != len(want) || memberships[0] != want[0] || memberships[1] != want[1] {
+		t.Fatalf("expected %+v, got %+v", want, memberships)
+	}
+
+	acmeTender := newPublishedTender(t, s, acme, ownerID)
+	holdingTender := newPublishedTender(t, s, holding, ownerID)
+	tests := []struct {
+		organizationID string
+		tenderIDs      []string
+	}{
+		{"", []string{acmeTender, holdingTender}},
+		{acme, []string{acmeTender}},
+		{holding, []string{holdingTender}},
+	}
+	for _, tt := range tests {
+		tenders, err := s.GetMyTendersList(ctx, 10, 0, ownerID, tt.organizationID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make(map[string]bool)
+		for _, tender := range tenders {
+			ids[tender.ID] = true
+		}
+		if len(ids) != len(tt.tenderIDs) {
+			t.Fatalf("organization %q: expected %v, got %+v", tt.organizationID, tt.tenderIDs, tenders)
+		}
+		for _, id := range tt.tenderIDs {
+			if !ids[id] {
+				t.Fatalf("organization %q: expected %v, got %+v", tt.organizationID, tt.tenderIDs, tenders)
+			}
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+// GetTenderList returns page of tenders which userID can see,
+// empty userID sees only public tenders
+func (s *Storage) GetTenderList(ctx context.Context, limit, offset int, serviceType, userID string) ([]models.Tender, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenders := make([]models.Tender, 0, len(s.tenders))
+	for _, t := range s.tenders {
+		if serviceType != "" && t.ServiceType != serviceType {
+			continue
+		}
+		if !s.tenderVisible(t, userID) {
+			continue
+		}
+		tenders = append(tenders, t.Tender)
+	}
+	sortTenders(tenders)
+
+	start, end := paginate(len(tenders), limit, offset)
+	return tenders[start:end], nil
+}
+
+func (s *Storage) InsertTender(ctx context.Context, newTender *models.NewTenderRequest, creatorID string) (models.Tender, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := &tender{
+		Tender: models.Tender{
+			ID:             newID(),
+			Name:           newTender.Name,
+			Description:    newTender.Description,
+			Status:         StatusCreated,
+			ServiceType:    newTender.ServiceType,
+			OrganizationID: newTender.OrganizationID,
+			Version:        1,
+			CreatedAt:      now(),
+		},
+		CreatorID: creatorID,
+		UpdatedBy: creatorID,
+	}
+	t.UpdatedAt = t.CreatedAt
+	s.tenders[t.ID] = t
+	s.tendersHistory[t.ID] = map[int]tender{t.Version: *t}
+
+	return t.Tender, nil
+}
+
+func (s *Storage) GetMyTendersList(ctx context.Context, limit, offset int, userID, organizationID string) ([]models.Tender, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenders := make([]models.Tender, 0)
+	for _, t := range s.tenders {
+		if t.CreatorID == userID && (organizationID == "" || t.OrganizationID == organizationID) {
+			tenders = append(tenders, t.Tender)
+		}
+	}
+	sortTenders(tenders)
+
+	start, end := paginate(len(tenders), limit, offset)
+	return tenders[start:end], nil
+}
+
+func (s *Storage) GetTenderStatus(ctx context.Context, tenderID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.tenders[tenderID]
+	if !ok {
+		return "", nil
+	}
+
+	return t.Status, nil
+}
+
+// ChangeTenderStatus moves tender to status if domain allows such transition
+func (s *Storage) ChangeTenderStatus(ctx context.Context, tenderID, status string) (models.Tender, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tenders[tenderID]
+	if !ok {
+		return models.Tender{}, storage.ErrNotFound
+	}
+	if err := domain.TenderTransition(t.Status, status); err != nil {
+		return models.Tender{}, err
+	}
+	t.Status = status
+
+	return t.Tender, nil
+}
+
+// EditTender applies patch to tender and increments version,
+// new version is saved to history like tender_version_trigger does.
+// Returns storage.ErrNotFound if there is no such tender
+func (s *Storage) EditTender(ctx context.Context, tenderID, userID string, patch models.EditTenderRequest) (models.Tender, error) {
+	if err := patch.Validate(); err != nil {
+		return models.Tender{}, fmt.Errorf("cannot change tender: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tenders[tenderID]
+	if !ok {
+		return models.Tender{}, fmt.Errorf("cannot change tender: %s %w", tenderID, storage.ErrNotFound)
+	}
+
+	updated := *t
+	if patch.Name.Set {
+		updated.Name = patch.Name.Value
+	}
+	if patch.Description.Set {
+		updated.Description = patch.Description.Value
+	}
+	if patch.ServiceType.Set {
+		updated.ServiceType = patch.ServiceType.Value
+	}
+
+	updated.Version = t.Version + 1
+	updated.UpdatedBy = userID
+	updated.UpdatedAt = now()
+	*t = updated
+	s.tendersHistory[tenderID][t.Version] = *t
+
+	return t.Tender, nil
+}
+
+// RollbackTender copies editable fields of the requested version
+// forward as a new version. Returns storage.ErrNotFound if tender
+// or version does not exist
+func (s *Storage) RollbackTender(ctx context.Context, tenderID, userID string, version int) (models.Tender, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tenders[tenderID]
+	if !ok {
+		return models.Tender{}, storage.ErrNotFound
+	}
+	old, ok := s.tendersHistory[tenderID][version]
+	if !ok {
+		return models.Tender{}, storage.ErrNotFound
+	}
+
+	t.Name = old.Name
+	t.Description = old.Description
+	t.ServiceType = old.ServiceType
+	t.Version++
+	t.UpdatedBy = userID
+	t.UpdatedAt = now()
+	s.tendersHistory[tenderID][t.Version] = *t
+
+	return t.Tender, nil
+}
+
+// GetTenderVersions returns page of tender versions starting from the newest
+func (s *Storage) GetTenderVersions(ctx context.Context, tenderID string, limit, offset int) ([]models.TenderVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	history := s.tendersHistory[tenderID]
+	versions := make([]models.TenderVersion, 0, len(history))
+	for _, snapshot := range history {
+		versions = append(versions, s.tenderVersion(snapshot))
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version > versions[j].Version
+	})
+
+	start, end := paginate(len(versions), limit, offset)
+	return versions[start:end], nil
+}
+
+// GetTenderVersion returns single version of tender
+// or storage.ErrNotFound if it does not exist
+func (s *Storage) GetTenderVersion(ctx context.Context, tenderID string, version int) (models.TenderVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, ok := s.tendersHistory[tenderID][version]
+	if !ok {
+		return models.TenderVersion{}, storage.ErrNotFound
+	}
+
+	return s.tenderVersion(snapshot), nil
+}
+
+func (s *Storage) tenderVersion(snapshot tender) models.TenderVersion {
+	v := models.TenderVersion{
+		Version:     snapshot.Version,
+		Name:        snapshot.Name,
+		Description: snapshot.Description,
+		ServiceType: snapshot.ServiceType,
+		Status:      snapshot.Status,
+		ChangedAt:   snapshot.UpdatedAt,
+	}
+	if e, ok := s.employees[snapshot.UpdatedBy]; ok {
+		v.ChangedBy = e.Username
+	}
+
+	return v
+}
+
+func (s *Storage) TenderExists(ctx context.Context, tenderID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.tenders[tenderID]
+	return ok, nil
+}
+
+// TenderVisible reports whether userID can see the tender
+func (s *Storage) TenderVisible(ctx context.Context, tenderID, userID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.tenders[tenderID]
+	return ok && s.tenderVisible(t, userID), nil
+}
+
+func (s *Storage) tenderVisible(t *tender, userID string) bool {
+	return domain.TenderPublic(t.Status) || s.isResponsible(t.OrganizationID, userID)
+}
+
+func (s *Storage) GetOrganizationIDByTender(ctx context.Context, tenderID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.tenders[tenderID]
+	if !ok {
+		return "", nil
+	}
+
+	return t.OrganizationID, nil
+}
@@ -0,0 +1,251 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+func TestTenderStatus(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddResponsible(acme, ownerID)
+
+	tenderID := newPublishedTender(t, s, acme, ownerID)
+
+	steps := []struct {
+		status string
+		err    error
+	}{
+		{StatusCreated, domain.ErrIllegalTransition},
+		{"Archived", domain.ErrInvalidStatus},
+		{StatusClosed, nil},
+		{StatusPublished, domain.ErrIllegalTransition},
+		{StatusCanceled, domain.ErrIllegalTransition},
+	}
+	for _, step := range steps {
+		if _, err := s.ChangeTenderStatus(ctx, tenderID, step.status); !errors.Is(err, step.err) {
+			t.Fatalf("%s: expected %v, got %v", step.status, step.err, err)
+		}
+	}
+
+	if status, err := s.GetTenderStatus(ctx, tenderID); err != nil || status != StatusClosed {
+		t.Fatalf("expected status %s, got %q, %v", StatusClosed, status, err)
+	}
+	if _, err := s.ChangeTenderStatus(ctx, "unknown", StatusClosed); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrNotFound, err)
+	}
+}
+
+func TestEditTender(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddResponsible(acme, ownerID)
+
+	created, err := s.InsertTender(ctx, &models.NewTenderRequest{
+		Name:           "Доставка",
+		Description:    "Казань - Москва",
+		ServiceType:    "Delivery",
+		OrganizationID: acme,
+	}, ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		patch string
+		err   error
+		want  models.Tender
+	}{
+		{`{"name":"Доставка оборудования"}`, nil, models.Tender{Name: "Доставка оборудования", Description: "Казань - Москва", ServiceType: "Delivery", Version: 2}},
+		{`{"description":null,"serviceType":"Construction"}`, nil, models.Tender{Name: "Доставка оборудования", ServiceType: "Construction", Version: 3}},
+		{`{"name":null}`, models.ErrNullNotAllowed, models.Tender{}},
+		{`{"serviceType":null}`, models.ErrNullNotAllowed, models.Tender{}},
+		{`{}`, models.ErrEmptyPatch, models.Tender{}},
+	}
+	for _, step := range steps {
+		var patch models.EditTenderRequest
+		if err := json.Unmarshal([]byte(step.patch), &patch); err != nil {
+			t.Fatal(err)
+		}
+
+		tender, err := s.EditTender(ctx, created.ID, ownerID, patch)
+		if !errors.Is(err, step.err) {
+			t.Fatalf("%s: expected %v, got %v", step.patch, step.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		if tender.Name != step.want.Name || tender.Description != step.want.Description ||
+			tender.ServiceType != step.want.ServiceType || tender.Version != step.want.Version {
+			t.Fatalf("%s: expected %+v, got %+v", step.patch, step.want, tender)
+		}
+	}
+
+	patch := models.EditTenderRequest{Name: models.Optional[string]{Set: true, Value: "Доставка"}}
+	if _, err := s.EditTender(ctx, "unknown", ownerID, patch); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrNotFound, err)
+	}
+}
+
+func TestRollbackTender(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddResponsible(acme, ownerID)
+
+	tenderID := newPublishedTender(t, s, acme, ownerID)
+	for _, name := range []string{"Доставка оборудования", "Доставка мебели"} {
+		patch := models.EditTenderRequest{Name: models.Optional[string]{Set: true, Value: name}}
+		if _, err := s.EditTender(ctx, tenderID, ownerID, patch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tender, err := s.RollbackTender(ctx, tenderID, ownerID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tender.Version != 4 || tender.Name != "Доставка" || tender.Status != StatusPublished {
+		t.Fatalf("expected version 4 copying version 1 with status kept, got %+v", tender)
+	}
+
+	// rollback adds a version and keeps history untouched
+	names := map[int]string{1: "Доставка", 2: "Доставка оборудования", 3: "Доставка мебели", 4: "Доставка"}
+	for version, name := range names {
+		v, err := s.GetTenderVersion(ctx, tenderID, version)
+		if err != nil || v.Name != name {
+			t.Fatalf("version %d: expected %q, got %+v, %v", version, name, v, err)
+		}
+	}
+
+	if _, err := s.RollbackTender(ctx, tenderID, ownerID, 99); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrNotFound, err)
+	}
+	if _, err := s.RollbackTender(ctx, "unknown", ownerID, 1); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrNotFound, err)
+	}
+}
+
+func TestTenderVersions(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	editorID := s.AddEmployee("editor", "", "")
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddResponsible(acme, ownerID)
+	s.AddResponsible(acme, editorID)
+
+	tenderID := newPublishedTender(t, s, acme, ownerID)
+	patch := models.EditTenderRequest{
+		Name:        models.Optional[string]{Set: true, Value: "Доставка оборудования"},
+		ServiceType: models.Optional[string]{Set: true, Value: "Construction"},
+	}
+	if _, err := s.EditTender(ctx, tenderID, editorID, patch); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RollbackTender(ctx, tenderID, ownerID, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := s.GetTenderVersions(ctx, tenderID, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0].Version != 3 || versions[1].Version != 2 {
+		t.Fatalf("expected versions 3 and 2 newest first, got %+v", versions)
+	}
+	if versions[0].ChangedBy != "owner" || versions[1].ChangedBy != "editor" {
+		t.Fatalf("expected versions changed by owner and editor, got %+v", versions)
+	}
+	if versions, _ := s.GetTenderVersions(ctx, tenderID, 2, 2); len(versions) != 1 || versions[0].Version != 1 {
+		t.Fatalf("expected version 1 on second page, got %+v", versions)
+	}
+
+	from, err := s.GetTenderVersion(ctx, tenderID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := s.GetTenderVersion(ctx, tenderID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// status is recorded as it was when the version was made
+	diff := models.DiffTenderVersions(from, to)
+	want := []models.FieldChange{
+		{Field: "name", From: "Доставка", To: "Доставка оборудования"},
+		{Field: "serviceType", From: "Delivery", To: "Construction"},
+		{Field: "status", From: StatusCreated, To: StatusPublished},
+	}
+	if diff.From != 1 || diff.To != 2 || !slices.Equal(diff.Changes, want) {
+		t.Fatalf("expected changes %+v, got %+v", want, diff)
+	}
+
+	if _, err := s.GetTenderVersion(ctx, tenderID, 99); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrNotFound, err)
+	}
+}
+
+func TestTenderVisibility(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	ownerID := s.AddEmployee("owner", "", "")
+	strangerID := s.AddEmployee("stranger", "", "")
+	acme := s.AddOrganization("acme", "", "LLC")
+	s.AddResponsible(acme, ownerID)
+
+	created, err := s.InsertTender(ctx, &models.NewTenderRequest{
+		Name:           "Черновик",
+		ServiceType:    "Delivery",
+		OrganizationID: acme,
+	}, ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publishedID := newPublishedTender(t, s, acme, ownerID)
+	closedID := newPublishedTender(t, s, acme, ownerID)
+	if _, err := s.ChangeTenderStatus(ctx, closedID, StatusClosed); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		userID  string
+		visible map[string]bool
+	}{
+		{"anonymous", "", map[string]bool{created.ID: false, publishedID: true, closedID: false}},
+		{"stranger", strangerID, map[string]bool{created.ID: false, publishedID: true, closedID: false}},
+		{"responsible", ownerID, map[string]bool{created.ID: true, publishedID: true, closedID: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenders, err := s.GetTenderList(ctx, 10, 0, "", tt.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			listed := make(map[string]bool)
+			for _, tender := range tenders {
+				listed[tender.ID] = true
+			}
+
+			for tenderID, visible := range tt.visible {
+				if listed[tenderID] != visible {
+					t.Errorf("tender %s: expected listed %v", tenderID, visible)
+				}
+				if ok, _ := s.TenderVisible(ctx, tenderID, tt.userID); ok != visible {
+					t.Errorf("tender %s: expected visible %v", tenderID, visible)
+				}
+			}
+		})
+	}
+}
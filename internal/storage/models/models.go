@@ -1,15 +1,63 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrEmptyPatch     = errors.New("patch does not contain any field")
+	ErrNullNotAllowed = errors.New("field cannot be null")
+	ErrWeakPassword   = errors.New("password is too short or too long")
+)
+
+// Password length limits, upper one keeps hashing cheap
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 256
+)
+
+// Optional is a field of PATCH request body. It tells apart
+// an absent field, an explicit null and a provided value
+type Optional[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+// UnmarshalJSON is called only for fields present in body,
+// so Set is true for both values and null
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Null = true
+		return nil
+	}
+
+	return json.Unmarshal(data, &o.Value)
+}
+
+// MarshalJSON encodes null for explicit null and absent fields
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Set || o.Null {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(o.Value)
+}
 
 type Tender struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
-	ServiceType string    `json:"serviceType"`
-	Version     int       `json:"version"`
-	CreatedAt   time.Time `json:"createdAt"`
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Status         string    `json:"status"`
+	ServiceType    string    `json:"serviceType"`
+	OrganizationID string    `json:"organizationId"`
+	Version        int       `json:"version"`
+	CreatedAt      time.Time `json:"createdAt"`
 }
 
 type NewTenderRequest struct {
@@ -20,20 +68,104 @@ type NewTenderRequest struct {
 	CreatorUsername string `json:"creatorUsername"`
 }
 
+// EditTenderRequest is a partial update of tender. Absent fields stay
+// unchanged, null clears description while name and serviceType
+// cannot be cleared
 type EditTenderRequest struct {
-	Name        string `json:"name,omitempty"`
-	Description string `json:"description,omitempty"`
-	ServiceType string `json:"serviceType,omitempty"`
+	Name        Optional[string] `json:"name"`
+	Description Optional[string] `json:"description"`
+	ServiceType Optional[string] `json:"serviceType"`
+}
+
+// Validate returns ErrEmptyPatch if no fields are provided or
+// ErrNullNotAllowed if required field is set to null
+func (r EditTenderRequest) Validate() error {
+	if !r.Name.Set && !r.Description.Set && !r.ServiceType.Set {
+		return ErrEmptyPatch
+	}
+	if r.Name.Null {
+		return fmt.Errorf("name: %w", ErrNullNotAllowed)
+	}
+	if r.ServiceType.Null {
+		return fmt.Errorf("serviceType: %w", ErrNullNotAllowed)
+	}
+
+	return nil
+}
+
+// TenderVersion is a snapshot of tender saved in history
+// together with the user who made it
+type TenderVersion struct {
+	Version     int       `json:"version"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	ServiceType string    `json:"serviceType"`
+	Status      string    `json:"status"`
+	ChangedBy   string    `json:"changedBy"`
+	ChangedAt   time.Time `json:"changedAt"`
+}
+
+// FieldChange describes a single field which differs between two versions
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// VersionDiff lists fields which differ between two versions
+type VersionDiff struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+func (d *VersionDiff) compare(field, from, to string) {
+	if from != to {
+		d.Changes = append(d.Changes, FieldChange{Field: field, From: from, To: to})
+	}
+}
+
+// DiffTenderVersions returns fields of tender which differ between from and to
+func DiffTenderVersions(from, to TenderVersion) VersionDiff {
+	diff := VersionDiff{
+		From:    from.Version,
+		To:      to.Version,
+		Changes: make([]FieldChange, 0),
+	}
+	diff.compare("name", from.Name, to.Name)
+	diff.compare("description", from.Description, to.Description)
+	diff.compare("serviceType", from.ServiceType, to.ServiceType)
+	diff.compare("status", from.Status, to.Status)
+
+	return diff
 }
 
 type Bid struct {
-	ID         string    `json:"id"`
-	Name       string    `json:"name"`
-	Status     string    `json:"status"`
-	AuthorType string    `json:"authorType"`
-	AuthorID   string    `json:"authorId"`
-	Version    string    `json:"version"`
-	CreatedAt  time.Time `json:"createdAt"`
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	TenderID    string    `json:"tenderId"`
+	AuthorType  string    `json:"authorType"`
+	AuthorID    string    `json:"authorId"`
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Decisions which responsibles of tender organization make on a bid
+const (
+	DecisionApproved = "Approved"
+	DecisionRejected = "Rejected"
+)
+
+// MaxDecisionQuorum is the number of approvals enough
+// for any organization regardless of its size
+const MaxDecisionQuorum = 3
+
+// DecisionQuorum returns number of approvals needed to approve a bid
+// for organization with the given number of responsibles
+func DecisionQuorum(responsibles int) int {
+	return min(MaxDecisionQuorum, responsibles)
 }
 
 type BidRequest struct {
@@ -42,11 +174,272 @@ type BidRequest struct {
 	TenderID    string `json:"tenderId"`
 	AuthorType  string `json:"authorType"`
 	AuthorID    string `json:"authorId"`
+	// OrganizationID is an organization bid is made on behalf of,
+	// it may be omitted if author is responsible for only one
+	OrganizationID string `json:"organizationId,omitempty"`
 }
 
+// EditBidRequest is a partial update of bid. Absent fields stay
+// unchanged, null clears description while name cannot be cleared
 type EditBidRequest struct {
-	Name        string `json:"name,omitempty"`
-	Description string `json:"description,omitempty"`
+	Name        Optional[string] `json:"name"`
+	Description Optional[string] `json:"description"`
+}
+
+// Validate returns ErrEmptyPatch if no fields are provided or
+// ErrNullNotAllowed if required field is set to null
+func (r EditBidRequest) Validate() error {
+	if !r.Name.Set && !r.Description.Set {
+		return ErrEmptyPatch
+	}
+	if r.Name.Null {
+		return fmt.Errorf("name: %w", ErrNullNotAllowed)
+	}
+
+	return nil
+}
+
+// BidVersion is a snapshot of bid saved in history
+// together with the user who made it
+type BidVersion struct {
+	Version     int       `json:"version"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	ChangedBy   string    `json:"changedBy"`
+	ChangedAt   time.Time `json:"changedAt"`
+}
+
+// DiffBidVersions returns fields of bid which differ between from and to
+func DiffBidVersions(from, to BidVersion) VersionDiff {
+	diff := VersionDiff{
+		From:    from.Version,
+		To:      to.Version,
+		Changes: make([]FieldChange, 0),
+	}
+	diff.compare("name", from.Name, to.Name)
+	diff.compare("description", from.Description, to.Description)
+	diff.compare("status", from.Status, to.Status)
+
+	return diff
+}
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// TokenResponse is returned on login, token is sent
+// back in Authorization: Bearer header
+type TokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ValidatePassword returns ErrWeakPassword if password
+// length is out of allowed bounds
+func ValidatePassword(password string) error {
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return ErrWeakPassword
+	}
+
+	return nil
+}
+
+// Credentials is a password of employee with state of login lockout.
+// LockedUntil is zero if account is not locked. Version is incremented
+// whenever password is set, tokens issued for another version are revoked
+type Credentials struct {
+	UserID         string
+	PasswordHash   string
+	FailedAttempts int
+	LockedUntil    time.Time
+	Version        int
+}
+
+// ChangePasswordRequest sets password of the authenticated user,
+// current password is required only if it was set before
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+type PasswordResetRequest struct {
+	Username string `json:"username"`
+}
+
+type PasswordResetConfirmRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"newPassword"`
+}
+
+// Employee is a user of the service, deactivated employee
+// cannot act but his tenders, bids and feedback are kept
+type Employee struct {
+	ID            string     `json:"id"`
+	Username      string     `json:"username"`
+	FirstName     string     `json:"firstName"`
+	LastName      string     `json:"lastName"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	DeactivatedAt *time.Time `json:"deactivatedAt,omitempty"`
+}
+
+// EmployeeProfile is an employee together with
+// organizations he is responsible for
+type EmployeeProfile struct {
+	Employee
+	Organizations []Membership `json:"organizations"`
+}
+
+// usernamePattern is a slug of lowercase latin letters and digits,
+// words may be separated by single ".", "_" or "-"
+var usernamePattern = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*$`)
+
+// ValidateUsername checks username format and length against employee table
+func ValidateUsername(username string) error {
+	if username == "" || len(username) > 50 {
+		return errors.New("username: length must be from 1 to 50")
+	}
+	if !usernamePattern.MatchString(username) {
+		return errors.New("username: must be a slug of lowercase latin letters and digits")
+	}
+
+	return nil
+}
+
+// NewEmployeeRequest creates employee, password is optional
+// and can be set later by the employee himself
+type NewEmployeeRequest struct {
+	Username  string `json:"username"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Password  string `json:"password"`
+}
+
+// Validate checks username and names against employee table
+// and password if it is provided
+func (r NewEmployeeRequest) Validate() error {
+	if err := ValidateUsername(r.Username); err != nil {
+		return err
+	}
+	if len(r.FirstName) > 50 || len(r.LastName) > 50 {
+		return errors.New("name: length must be at most 50")
+	}
+	if r.Password != "" {
+		return ValidatePassword(r.Password)
+	}
+
+	return nil
+}
+
+// EditEmployeeRequest is a partial update of employee. Absent fields stay
+// unchanged, null clears names. Username identifies employee in tokens
+// and requests, so it cannot be changed
+type EditEmployeeRequest struct {
+	FirstName Optional[string] `json:"firstName"`
+	LastName  Optional[string] `json:"lastName"`
+}
+
+// Validate returns ErrEmptyPatch if no fields are provided
+// or error if names do not fit employee table
+func (r EditEmployeeRequest) Validate() error {
+	if !r.FirstName.Set && !r.LastName.Set {
+		return ErrEmptyPatch
+	}
+	if len(r.FirstName.Value) > 50 || len(r.LastName.Value) > 50 {
+		return errors.New("name: length must be at most 50")
+	}
+
+	return nil
+}
+
+// APIKey describes key of organization, the key itself is shown
+// only once on creation or rotation and is never stored
+type APIKey struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	OrganizationID string     `json:"organizationId"`
+	Prefix         string     `json:"prefix"`
+	Scopes         []string   `json:"scopes"`
+	CreatedBy      string     `json:"createdBy"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	RotatedAt      *time.Time `json:"rotatedAt,omitempty"`
+	RevokedAt      *time.Time `json:"revokedAt,omitempty"`
+}
+
+type NewAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// APIKeyResponse is returned on creation and rotation of key
+type APIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyOwner is an active key resolved by its hash together
+// with the employee on whose behalf it acts
+type APIKeyOwner struct {
+	ID             string
+	OrganizationID string
+	UserID         string
+	Username       string
+	Scopes         []string
+}
+
+type Organization struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Type        string    `json:"type"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type NewOrganizationRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+}
+
+// EditOrganizationRequest is a partial update of organization. Absent
+// fields stay unchanged, null clears description while name and type
+// cannot be cleared
+type EditOrganizationRequest struct {
+	Name        Optional[string] `json:"name"`
+	Description Optional[string] `json:"description"`
+	Type        Optional[string] `json:"type"`
+}
+
+// Validate returns ErrEmptyPatch if no fields are provided or
+// ErrNullNotAllowed if required field is set to null
+func (r EditOrganizationRequest) Validate() error {
+	if !r.Name.Set && !r.Description.Set && !r.Type.Set {
+		return ErrEmptyPatch
+	}
+	if r.Name.Null {
+		return fmt.Errorf("name: %w", ErrNullNotAllowed)
+	}
+	if r.Type.Null {
+		return fmt.Errorf("type: %w", ErrNullNotAllowed)
+	}
+
+	return nil
+}
+
+// Member is an employee responsible for organization and his role
+type Member struct {
+	UserID   string `json:"userId"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// Membership is an organization employee is responsible for and his role in it
+type Membership struct {
+	OrganizationID string `json:"organizationId"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	Role           string `json:"role"`
 }
 
 type Feedback struct {
@@ -0,0 +1,212 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// isViolation reports whether err is violation of constraint with code,
+// e.g. 23505 for unique and 23503 for foreign key
+func isViolation(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
+
+// InsertEmployee creates employee and sets his password if passwordHash
+// is not empty. Returns storage.ErrAlreadyExists if username is taken
+func (s *Storage) InsertEmployee(ctx context.Context, newEmployee models.NewEmployeeRequest, passwordHash string) (models.Employee, error) {
+	var employee models.Employee
+	err := s.inTx(ctx, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO public.employee (username, first_name, last_name)
+			VALUES ($1, NULLIF($2, ''), NULLIF($3, ''))
+			RETURNING id, username, COALESCE(first_name, ''), COALESCE(last_name, ''), created_at;
+		`
+		err := tx.QueryRow(ctx, query, newEmployee.Username, newEmployee.FirstName, newEmployee.LastName).Scan(
+			&employee.ID, &employee.Username, &employee.FirstName, &employee.LastName, &employee.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		if passwordHash == "" {
+			return nil
+		}
+
+		return setPassword(ctx, tx, employee.ID, passwordHash)
+	})
+	if err != nil {
+		if isViolation(err, "23505") {
+			return models.Employee{}, storage.ErrAlreadyExists
+		}
+
+		return models.Employee{}, fmt.Errorf("cannot insert employee: %w", err)
+	}
+
+	return employee, nil
+}
+
+// GetCredentials returns storage.ErrNotFound if user has no password
+func (s *Storage) GetCredentials(ctx context.Context, userID string) (models.Credentials, error) {
+	query := `
+		SELECT user_id, password_hash, failed_attempts, locked_until, version
+		FROM public.employee_credentials
+		WHERE user_id = $1;
+	`
+
+	var credentials models.Credentials
+	var lockedUntil *time.Time
+	err := s.Pool.QueryRow(ctx, query, userID).Scan(
+		&credentials.UserID, &credentials.PasswordHash, &credentials.FailedAttempts, &lockedUntil,
+		&credentials.Version,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Credentials{}, storage.ErrNotFound
+		}
+
+		return models.Credentials{}, fmt.Errorf("cannot get credentials: %w", err)
+	}
+	if lockedUntil != nil {
+		credentials.LockedUntil = *lockedUntil
+	}
+
+	return credentials, nil
+}
+
+// SetPassword replaces password of user, unlocks account,
+// invalidates pending reset tokens and revokes issued tokens
+func (s *Storage) SetPassword(ctx context.Context, userID, passwordHash string) error {
+	err := s.inTx(ctx, func(tx pgx.Tx) error {
+		return setPassword(ctx, tx, userID, passwordHash)
+	})
+	if err != nil {
+		if isViolation(err, "23503") {
+			return storage.ErrNotFound
+		}
+
+		return fmt.Errorf("cannot set password: %w", err)
+	}
+
+	return nil
+}
+
+func setPassword(ctx context.Context, tx pgx.Tx, userID, passwordHash string) error {
+	query := `
+		INSERT INTO public.employee_credentials (user_id, password_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET password_hash = EXCLUDED.password_hash,
+			failed_attempts = 0,
+			locked_until = NULL,
+			version = employee_credentials.version + 1,
+			updated_at = CURRENT_TIMESTAMP;
+	`
+	if _, err := tx.Exec(ctx, query, userID, passwordHash); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, `DELETE FROM public.password_resets WHERE user_id = $1;`, userID)
+	return err
+}
+
+// RecordLoginFailure counts failed attempt, account is locked for
+// lockout once maxAttempts is reached and counter starts over
+func (s *Storage) RecordLoginFailure(ctx context.Context, userID string, maxAttempts int, lockout time.Duration) error {
+	query := `
+		UPDATE public.employee_credentials
+		SET failed_attempts = CASE WHEN failed_attempts + 1 >= $2 THEN 0 ELSE failed_attempts + 1 END,
+			locked_until = CASE
+				WHEN failed_attempts + 1 >= $2 THEN CURRENT_TIMESTAMP + make_interval(secs => $3)
+				ELSE locked_until
+			END
+		WHERE user_id = $1;
+	`
+	_, err := s.Pool.Exec(ctx, query, userID, maxAttempts, lockout.Seconds())
+	if err != nil {
+		return fmt.Errorf("cannot record login failure: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) ResetLoginFailures(ctx context.Context, userID string) error {
+	query := `
+		UPDATE public.employee_credentials
+		SET failed_attempts = 0, locked_until = NULL
+		WHERE user_id = $1;
+	`
+	_, err := s.Pool.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("cannot reset login failures: %w", err)
+	}
+
+	return nil
+}
+
+// CreatePasswordReset saves reset token valid for ttl,
+// previous tokens of user are invalidated
+func (s *Storage) CreatePasswordReset(ctx context.Context, userID, tokenHash string, ttl time.Duration) error {
+	err := s.inTx(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `DELETE FROM public.password_resets WHERE user_id = $1;`, userID)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO public.password_resets (token_hash, user_id, expires_at)
+			VALUES ($1, $2, CURRENT_TIMESTAMP + make_interval(secs => $3));
+		`
+		_, err = tx.Exec(ctx, query, tokenHash, userID, ttl.Seconds())
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create password reset: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword consumes reset token and sets new password of its
+// user. Returns storage.ErrNotFound if token is unknown or expired
+func (s *Storage) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (string, error) {
+	var userID string
+	var valid bool
+	err := s.inTx(ctx, func(tx pgx.Tx) error {
+		query := `
+			DELETE FROM public.password_resets
+			WHERE token_hash = $1
+			RETURNING user_id, expires_at > CURRENT_TIMESTAMP;
+		`
+		err := tx.QueryRow(ctx, query, tokenHash).Scan(&userID, &valid)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				valid = false
+				return nil
+			}
+
+			return err
+		}
+		// expired token is deleted anyway
+		if !valid {
+			return nil
+		}
+
+		return setPassword(ctx, tx, userID, passwordHash)
+	})
+	if err != nil {
+		return "", fmt.Errorf("cannot reset password: %w", err)
+	}
+	if !valid {
+		return "", storage.ErrNotFound
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// apiKeyColumns are selected from api_keys k joined with employee e
+// of the user on whose behalf key acts
+const apiKeyColumns = `k.id, k.name, k.organization_id, k.prefix, k.scopes,
+	e.username, k.created_at, k.rotated_at, k.revoked_at`
+
+func scanAPIKey(row pgx.Row) (models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan(
+		&key.ID, &key.Name, &key.OrganizationID, &key.Prefix, &key.Scopes,
+		&key.CreatedBy, &key.CreatedAt, &key.RotatedAt, &key.RevokedAt,
+	)
+
+	return key, err
+}
+
+func (s *Storage) InsertAPIKey(ctx context.Context, organizationID, userID string, newKey models.NewAPIKeyRequest, keyHash, prefix string) (models.APIKey, error) {
+	query := `
+		WITH k AS (
+			INSERT INTO public.api_keys (organization_id, user_id, name, key_hash, prefix, scopes)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING *
+		)
+		SELECT ` + apiKeyColumns + `
+		FROM k JOIN public.employee e ON e.id = k.user_id;
+	`
+	key, err := scanAPIKey(s.Pool.QueryRow(ctx, query, organizationID, userID, newKey.Name, keyHash, prefix, newKey.Scopes))
+	if err != nil {
+		return models.APIKey{}, fmt.Errorf("cannot insert api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetAPIKeys returns keys of organization including revoked ones
+// ordered by creation time
+func (s *Storage) GetAPIKeys(ctx context.Context, organizationID string, limit, offset int) ([]models.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM public.api_keys k JOIN public.employee e ON e.id = k.user_id
+		WHERE k.organization_id = $1
+		ORDER BY k.created_at, k.id
+		LIMIT $2 OFFSET $3;
+	`
+	rows, err := s.Pool.Query(ctx, query, organizationID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("cannot select api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]models.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("cannot scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RotateAPIKey replaces secret of active key, the key then acts on behalf
+// of userID. Returns storage.ErrNotFound if key is not found or revoked
+func (s *Storage) RotateAPIKey(ctx context.Context, keyID, organizationID, userID, keyHash, prefix string) (models.APIKey, error) {
+	query := `
+		WITH k AS (
+			UPDATE public.api_keys
+			SET key_hash = $4, prefix = $5, user_id = $3, rotated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND organization_id = $2 AND revoked_at IS NULL
+			RETURNING *
+		)
+		SELECT ` + apiKeyColumns + `
+		FROM k JOIN public.employee e ON e.id = k.user_id;
+	`
+	key, err := scanAPIKey(s.Pool.QueryRow(ctx, query, keyID, organizationID, userID, keyHash, prefix))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.APIKey{}, storage.ErrNotFound
+		}
+
+		return models.APIKey{}, fmt.Errorf("cannot rotate api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// RevokeAPIKey disables key, revoking revoked key changes nothing.
+// Returns storage.ErrNotFound if organization has no such key
+func (s *Storage) RevokeAPIKey(ctx context.Context, keyID, organizationID string) (models.APIKey, error) {
+	query := `
+		WITH k AS (
+			UPDATE public.api_keys
+			SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP)
+			WHERE id = $1 AND organization_id = $2
+			RETURNING *
+		)
+		SELECT ` + apiKeyColumns + `
+		FROM k JOIN public.employee e ON e.id = k.user_id;
+	`
+	key, err := scanAPIKey(s.Pool.QueryRow(ctx, query, keyID, organizationID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.APIKey{}, storage.ErrNotFound
+		}
+
+		return models.APIKey{}, fmt.Errorf("cannot revoke api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetAPIKeyOwner returns active key with hash. Key stops working once its
+// employee is no longer responsible for organization, storage.ErrNotFound
+// is returned then
+func (s *Storage) GetAPIKeyOwner(ctx context.Context, keyHash string) (models.APIKeyOwner, error) {
+	query := `
+		SELECT k.id, k.organization_id, k.user_id, e.username, k.scopes
+		FROM public.api_keys k JOIN public.employee e ON e.id = k.user_id
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL AND EXISTS (
+			SELECT 1 FROM public.organization_responsible r
+			WHERE r.organization_id = k.organization_id AND r.user_id = k.user_id
+		);
+	`
+	var owner models.APIKeyOwner
+	err := s.Pool.QueryRow(ctx, query, keyHash).Scan(
+		&owner.ID, &owner.OrganizationID, &owner.UserID, &owner.Username, &owner.Scopes,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.APIKeyOwner{}, storage.ErrNotFound
+		}
+
+		return models.APIKeyOwner{}, fmt.Errorf("cannot get api key: %w", err)
+	}
+
+	return owner, nil
+}
@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+	"zadanie-6105/internal/config"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+)
+
+// TestConcurrentBidDecisions needs a disposable database,
+// set POSTGRES_TEST_CONN to run it
+func TestConcurrentBidDecisions(t *testing.T) {
+	conn := os.Getenv("POSTGRES_TEST_CONN")
+	if conn == "" {
+		t.Skip("POSTGRES_TEST_CONN is not set")
+	}
+
+	ctx := context.Background()
+	s, err := New(ctx, config.Config{POSTGRES_CONN: conn, MIGRATE_ON_START: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Pool.Close()
+
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	var organizationID string
+	err = s.Pool.QueryRow(ctx, "INSERT INTO organization (name, type) VALUES ($1, 'LLC') RETURNING id", "org "+suffix).Scan(&organizationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userIDs := make([]string, 3)
+	for i := range userIDs {
+		err = s.Pool.QueryRow(ctx, "INSERT INTO employee (username) VALUES ($1) RETURNING id", fmt.Sprintf("owner%d_%s", i, suffix)).Scan(&userIDs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = s.Pool.Exec(ctx, "INSERT INTO organization_responsible (organization_id, user_id) VALUES ($1, $2)", organizationID, userIDs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tender, err := s.InsertTender(ctx, &models.NewTenderRequest{
+		Name:           "tender",
+		ServiceType:    "Construction",
+		OrganizationID: organizationID,
+	}, userIDs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ChangeTenderStatus(ctx, tender.ID, StatusPublished); err != nil {
+		t.Fatal(err)
+	}
+
+	bidIDs := make([]string, 2)
+	for i := range bidIDs {
+		bid, err := s.InsertBid(ctx, models.BidRequest{
+			Name:       fmt.Sprintf("bid %d", i),
+			TenderID:   tender.ID,
+			AuthorType: "User",
+			AuthorID:   userIDs[i],
+		}, organizationID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.ChangeBitStatus(ctx, bid.ID, domain.BidPublished); err != nil {
+			t.Fatal(err)
+		}
+		bidIDs[i] = bid.ID
+	}
+
+	var mu sync.Mutex
+	approvals := make(map[string]int)
+	var wg sync.WaitGroup
+	for _, bidID := range bidIDs {
+		for _, userID := range userIDs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				_, err := s.BidDecision(ctx, bidID, userID, models.DecisionApproved)
+				switch {
+				case err == nil:
+					mu.Lock()
+					approvals[bidID]++
+					mu.Unlock()
+				case errors.Is(err, storage.ErrDecisionClosed):
+				default:
+					t.Errorf("unexpected error: %v", err)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	winners := 0
+	for _, bidID := range bidIDs {
+		status, err := s.GetBidStatus(ctx, bidID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status == domain.BidApproved {
+			winners++
+		}
+		if (status == domain.BidApproved) != (approvals[bidID] == len(userIDs)) {
+			t.Errorf("bid %s is %s after %d approvals", bidID, status, approvals[bidID])
+		}
+	}
+	if winners != 1 {
+		t.Errorf("expected exactly one approved bid, got %d: %v", winners, approvals)
+	}
+
+	status, err := s.GetTenderStatus(ctx, tender.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != StatusClosed {
+		t.Errorf("expected tender to be closed, got %s", status)
+	}
+}
@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// employeeColumns are selected from employee e
+const employeeColumns = `e.id, e.username, COALESCE(e.first_name, ''), COALESCE(e.last_name, ''),
+	e.created_at, e.deactivated_at`
+
+func scanEmployee(row pgx.Row) (models.Employee, error) {
+	var e models.Employee
+	err := row.Scan(&e.ID, &e.Username, &e.FirstName, &e.LastName, &e.CreatedAt, &e.DeactivatedAt)
+
+	return e, err
+}
+
+// GetEmployees returns page of employees including deactivated
+// ones ordered by username
+func (s *Storage) GetEmployees(ctx context.Context, limit, offset int) ([]models.Employee, error) {
+	query := `
+		SELECT ` + employeeColumns + `
+		FROM public.employee e
+		ORDER BY e.username
+		LIMIT $1 OFFSET $2;
+	`
+
+	rows, err := s.Pool.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get employees: %w", err)
+	}
+	defer rows.Close()
+
+	employees := make([]models.Employee, 0, limit)
+	for rows.Next() {
+		e, err := scanEmployee(rows)
+		if err != nil {
+			return nil, fmt.Errorf("cannot scan employee: %w", err)
+		}
+		employees = append(employees, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot get employees: %w", err)
+	}
+
+	return employees, nil
+}
+
+// GetEmployee returns employee even if he is deactivated,
+// storage.ErrNotFound if there is no such employee
+func (s *Storage) GetEmployee(ctx context.Context, username string) (models.Employee, error) {
+	query := `
+		SELECT ` + employeeColumns + `
+		FROM public.employee e
+		WHERE e.username = $1;
+	`
+
+	employee, err := scanEmployee(s.Pool.QueryRow(ctx, query, username))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Employee{}, fmt.Errorf("cannot get employee: %s %w", username, storage.ErrNotFound)
+		}
+
+		return models.Employee{}, fmt.Errorf("cannot get employee: %w", err)
+	}
+
+	return employee, nil
+}
+
+// EditEmployee applies patch to active employee,
+// returns storage.ErrNotFound if there is no such employee
+func (s *Storage) EditEmployee(ctx context.Context, userID string, patch models.EditEmployeeRequest) (models.Employee, error) {
+	if err := patch.Validate(); err != nil {
+		return models.Employee{}, fmt.Errorf("cannot change employee: %w", err)
+	}
+
+	set := newSetClause(userID)
+	if patch.FirstName.Set {
+		set.add("first_name", nullIfEmpty(patch.FirstName.Value))
+	}
+	if patch.LastName.Set {
+		set.add("last_name", nullIfEmpty(patch.LastName.Value))
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE public.employee e
+		SET %s, updated_at = CURRENT_TIMESTAMP
+		WHERE e.id = $1 AND e.deactivated_at IS NULL
+		RETURNING %s;
+	`, set, employeeColumns)
+
+	employee, err := scanEmployee(s.Pool.QueryRow(ctx, query, set.args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Employee{}, fmt.Errorf("cannot change employee: %s %w", userID, storage.ErrNotFound)
+		}
+
+		return models.Employee{}, fmt.Errorf("cannot change employee: %w", err)
+	}
+
+	return employee, nil
+}
+
+// DeactivateEmployee marks employee deactivated and removes him from
+// responsibles of organizations, his tenders, bids and feedback stay.
+// Returns storage.ErrNotFound if there is no such active employee and
+// domain.ErrLastOwner if he is the only owner of some organization
+func (s *Storage) DeactivateEmployee(ctx context.Context, userID string) (models.Employee, error) {
+	var employee models.Employee
+	err := s.inTx(ctx, func(tx pgx.Tx) error {
+		query := `
+			SELECT organization_id FROM public.organization_responsible
+			WHERE user_id = $1 AND role = $2;
+		`
+		rows, err := tx.Query(ctx, query, userID, domain.RoleOwner)
+		if err != nil {
+			return err
+		}
+		owned, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return err
+		}
+		for _, organizationID := range owned {
+			if err := checkLastOwner(ctx, tx, organizationID, userID); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.Exec(ctx, `DELETE FROM public.organization_responsible WHERE user_id = $1;`, userID)
+		if err != nil {
+			return err
+		}
+
+		query = `
+			UPDATE public.employee e
+			SET deactivated_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE e.id = $1 AND e.deactivated_at IS NULL
+			RETURNING ` + employeeColumns + `;
+		`
+		employee, err = scanEmployee(tx.QueryRow(ctx, query, userID))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.ErrNotFound
+		}
+
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, domain.ErrLastOwner) {
+			return models.Employee{}, err
+		}
+
+		return models.Employee{}, fmt.Errorf("cannot deactivate employee: %w", err)
+	}
+
+	return employee, nil
+}
+
+// nullIfEmpty turns empty optional column value into NULL
+func nullIfEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationLockID is a key of advisory lock which guarantees
+// that only one replica applies migrations at a time
+const migrationLockID = 6105
+
+// Migration is a single versioned change of database schema
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migrations returns embedded migrations ordered by version.
+// File names must look like 0001_name.up.sql and 0001_name.down.sql
+func Migrations() ([]Migration, error) {
+	files, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("cannot read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, f := range files {
+		name := f.Name()
+		base, direction, ok := strings.Cut(strings.TrimSuffix(name, ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("invalid migration file name: %s", name)
+		}
+
+		versionStr, title, _ := strings.Cut(base, "_")
+		version, err := strconv.Atoi(versionStr)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version: %s", name)
+		}
+
+		body, err := migrationsFS.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read migration %s: %w", name, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: title}
+			byVersion[version] = m
+		}
+		if m.Name != title {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, m.Name, title)
+		}
+
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d must have both up and down files", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// SchemaVersion returns version of the last applied migration
+// or 0 if no migrations were applied
+func (s *Storage) SchemaVersion(ctx context.Context) (int, error) {
+	conn, err := s.Pool.Acquire(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("cannot acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if err := ensureSchemaMigrations(ctx, conn); err != nil {
+		return 0, err
+	}
+
+	return schemaVersion(ctx, conn)
+}
+
+// MigrateUp applies all migrations which were not applied yet
+func (s *Storage) MigrateUp(ctx context.Context) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+
+	return s.withMigrationLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := schemaVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if m.Version <= current {
+				continue
+			}
+
+			err := applyMigration(ctx, conn, m.Up, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("cannot apply migration %d_%s: %w", m.Version, m.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// MigrateDown reverts steps last applied migrations
+func (s *Storage) MigrateDown(ctx context.Context, steps int) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+
+	return s.withMigrationLock(ctx, func(conn *pgxpool.Conn) error {
+		for i := 0; i < steps; i++ {
+			current, err := schemaVersion(ctx, conn)
+			if err != nil {
+				return err
+			}
+			if current == 0 {
+				return nil
+			}
+
+			idx := sort.Search(len(migrations), func(i int) bool {
+				return migrations[i].Version >= current
+			})
+			if idx == len(migrations) || migrations[idx].Version != current {
+				return fmt.Errorf("migration %d is applied but unknown to this build", current)
+			}
+			m := migrations[idx]
+
+			err = applyMigration(ctx, conn, m.Down, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", m.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("cannot revert migration %d_%s: %w", m.Version, m.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// withMigrationLock runs fn holding session level advisory lock,
+// other replicas wait until migrations are finished
+func (s *Storage) withMigrationLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := s.Pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockID)
+	if err != nil {
+		return fmt.Errorf("cannot take migration lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	if err := ensureSchemaMigrations(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func ensureSchemaMigrations(ctx context.Context, conn *pgxpool.Conn) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+	`
+	_, err := conn.Exec(ctx, query)
+	if err != nil {
+		return fmt.Errorf("cannot create schema_migrations: %w", err)
+	}
+
+	return nil
+}
+
+func schemaVersion(ctx context.Context, conn *pgxpool.Conn) (int, error) {
+	var version int
+	err := conn.QueryRow(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("cannot get schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+// applyMigration executes migration body and bookkeeping in one transaction
+func applyMigration(ctx context.Context, conn *pgxpool.Conn, body string, record func(tx pgx.Tx) error) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, body); err != nil {
+		return err
+	}
+	if err := record(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
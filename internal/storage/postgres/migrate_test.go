@@ -0,0 +1,224 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// handMadeSchema is what databases looked like before migrations were
+// introduced: platform tables from the task and application tables
+// created by hand without history constraints and indexes
+const handMadeSchema = `
+	CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+	CREATE TABLE employee (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		username VARCHAR(50) UNIQUE NOT NULL,
+		first_name VARCHAR(50),
+		last_name VARCHAR(50),
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TYPE organization_type AS ENUM ('IE', 'LLC', 'JSC');
+
+	CREATE TABLE organization (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		name VARCHAR(100) NOT NULL,
+		description TEXT,
+		type organization_type,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE organization_responsible (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		organization_id UUID REFERENCES organization(id) ON DELETE CASCADE,
+		user_id UUID REFERENCES employee(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE tenders (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		organization_id UUID NOT NULL REFERENCES organization(id),
+		creator_id UUID NOT NULL REFERENCES employee(id),
+		name VARCHAR(100) NOT NULL,
+		description VARCHAR(500) NOT NULL DEFAULT '',
+		status VARCHAR(20) NOT NULL DEFAULT 'Created',
+		service_type VARCHAR(20) NOT NULL,
+		version INTEGER NOT NULL DEFAULT 1,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE tenders_history (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		tender_id UUID NOT NULL REFERENCES tenders(id) ON DELETE CASCADE,
+		organization_id UUID NOT NULL,
+		creator_id UUID NOT NULL,
+		name VARCHAR(100) NOT NULL,
+		description VARCHAR(500) NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		service_type VARCHAR(20) NOT NULL,
+		version INTEGER NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL
+	);
+
+	CREATE TABLE bids (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		tender_id UUID NOT NULL REFERENCES tenders(id) ON DELETE CASCADE,
+		organization_id UUID,
+		name VARCHAR(100) NOT NULL,
+		description VARCHAR(500) NOT NULL DEFAULT '',
+		status VARCHAR(20) NOT NULL DEFAULT 'Created',
+		author_type VARCHAR(20) NOT NULL,
+		author_id UUID NOT NULL,
+		version INTEGER NOT NULL DEFAULT 1,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE bids_history (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		bid_id UUID NOT NULL REFERENCES bids(id) ON DELETE CASCADE,
+		tender_id UUID NOT NULL,
+		organization_id UUID,
+		name VARCHAR(100) NOT NULL,
+		description VARCHAR(500) NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		author_type VARCHAR(20) NOT NULL,
+		author_id UUID NOT NULL,
+		version INTEGER NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL
+	);
+
+	CREATE TABLE submissions (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		bid_id UUID NOT NULL REFERENCES bids(id) ON DELETE CASCADE,
+		accept_rate INTEGER NOT NULL DEFAULT 0,
+		rejected BOOLEAN NOT NULL DEFAULT false
+	);
+
+	CREATE TABLE feedback (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		bid_id UUID NOT NULL REFERENCES bids(id) ON DELETE CASCADE,
+		feedback VARCHAR(1000) NOT NULL,
+		creator_id UUID NOT NULL REFERENCES employee(id),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	INSERT INTO employee (username) VALUES ('user1');
+	INSERT INTO organization (name, type) VALUES ('acme', 'LLC');
+	INSERT INTO tenders (organization_id, creator_id, name, service_type)
+	SELECT o.id, e.id, 'tender', 'Construction' FROM organization o, employee e;
+	INSERT INTO bids (tender_id, organization_id, name, author_type, author_id)
+	SELECT t.id, t.organization_id, 'bid', 'User', t.creator_id FROM tenders t;
+	INSERT INTO submissions (bid_id) SELECT id FROM bids;
+`
+
+// newDatabase creates empty database for a single test,
+// it is dropped when the test finishes
+func newDatabase(t *testing.T, conn string) *Storage {
+	t.Helper()
+	ctx := context.Background()
+
+	admin, err := pgx.Connect(ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close(ctx)
+
+	name := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(ctx, "CREATE DATABASE "+name); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := pgxpool.ParseConfig(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.ConnConfig.Database = name
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		pool.Close()
+
+		admin, err := pgx.Connect(context.Background(), conn)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer admin.Close(context.Background())
+		if _, err := admin.Exec(context.Background(), "DROP DATABASE "+name); err != nil {
+			t.Error(err)
+		}
+	})
+
+	return &Storage{Pool: pool}
+}
+
+// TestMigrateHandMadeSchema needs a role allowed to create databases,
+// set POSTGRES_TEST_CONN to run it
+func TestMigrateHandMadeSchema(t *testing.T) {
+	conn := os.Getenv("POSTGRES_TEST_CONN")
+	if conn == "" {
+		t.Skip("POSTGRES_TEST_CONN is not set")
+	}
+
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := migrations[len(migrations)-1].Version
+	ctx := context.Background()
+
+	t.Run("tables created by hand are adopted", func(t *testing.T) {
+		s := newDatabase(t, conn)
+		if _, err := s.Pool.Exec(ctx, handMadeSchema); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.MigrateUp(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if version, err := s.SchemaVersion(ctx); err != nil || version != latest {
+			t.Fatalf("expected schema version %d, got %d, %v", latest, version, err)
+		}
+
+		// existing rows got their first version in history
+		var tenders, bids int
+		err := s.Pool.QueryRow(ctx, `
+			SELECT
+				(SELECT COUNT(*) FROM tenders_history WHERE version = 1),
+				(SELECT COUNT(*) FROM bids_history WHERE version = 1)
+		`).Scan(&tenders, &bids)
+		if err != nil || tenders != 1 || bids != 1 {
+			t.Fatalf("expected first versions in history, got %d tenders, %d bids, %v", tenders, bids, err)
+		}
+	})
+
+	t.Run("mismatched table is rejected", func(t *testing.T) {
+		s := newDatabase(t, conn)
+		if _, err := s.Pool.Exec(ctx, handMadeSchema); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Pool.Exec(ctx, "ALTER TABLE tenders DROP COLUMN service_type"); err != nil {
+			t.Fatal(err)
+		}
+
+		err := s.MigrateUp(ctx)
+		if err == nil || !strings.Contains(err.Error(), "service_type") {
+			t.Fatalf("expected missing service_type to be reported, got %v", err)
+		}
+		if version, err := s.SchemaVersion(ctx); err != nil || version != 1 {
+			t.Fatalf("expected migration to stop after 0001, got %d, %v", version, err)
+		}
+	})
+}
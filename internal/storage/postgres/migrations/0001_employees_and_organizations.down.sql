@@ -0,0 +1,3 @@
+-- Employees and organizations are owned by the platform and are
+-- intentionally kept when this migration is reverted.
+SELECT 1;
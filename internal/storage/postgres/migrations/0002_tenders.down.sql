@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS tender_version_trigger ON tenders;
+DROP FUNCTION IF EXISTS tender_version();
+DROP TABLE IF EXISTS tenders_history;
+DROP TABLE IF EXISTS tenders;
@@ -0,0 +1,97 @@
+-- Objects are created only if missing so that databases whose tables were
+-- created by hand before migrations were introduced can be migrated too.
+
+-- require_columns fails the migration if a table created by hand lacks
+-- columns the application relies on, instead of accepting it silently.
+-- It lives in pg_temp and disappears with the session.
+CREATE OR REPLACE FUNCTION pg_temp.require_columns(tbl TEXT, columns TEXT[]) RETURNS VOID AS $$
+DECLARE
+    missing TEXT;
+BEGIN
+    SELECT string_agg(c, ', ') INTO missing
+    FROM unnest(columns) AS c
+    WHERE NOT EXISTS (
+        SELECT 1 FROM information_schema.columns ic
+        WHERE ic.table_schema = current_schema()
+            AND ic.table_name = tbl
+            AND ic.column_name = c
+    );
+    IF missing IS NOT NULL THEN
+        RAISE EXCEPTION 'table % exists but lacks columns: %', tbl, missing;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TABLE IF NOT EXISTS tenders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organization(id) ON DELETE CASCADE,
+    creator_id UUID NOT NULL REFERENCES employee(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(500) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'Created',
+    service_type VARCHAR(20) NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+SELECT pg_temp.require_columns('tenders', ARRAY[
+    'id', 'organization_id', 'creator_id', 'name', 'description',
+    'status', 'service_type', 'version', 'created_at'
+]);
+
+CREATE INDEX IF NOT EXISTS tenders_organization_id_idx ON tenders (organization_id);
+CREATE INDEX IF NOT EXISTS tenders_creator_id_idx ON tenders (creator_id);
+
+-- tenders_history keeps previous versions of tenders,
+-- the current version lives only in tenders.
+CREATE TABLE IF NOT EXISTS tenders_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tender_id UUID NOT NULL REFERENCES tenders(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL,
+    creator_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(500) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    service_type VARCHAR(20) NOT NULL,
+    version INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (tender_id, version)
+);
+
+SELECT pg_temp.require_columns('tenders_history', ARRAY[
+    'id', 'tender_id', 'organization_id', 'creator_id', 'name', 'description',
+    'status', 'service_type', 'version', 'created_at'
+]);
+-- Later migrations upsert history ON CONFLICT (tender_id, version),
+-- a table created by hand may lack the constraint. The name matches
+-- the one generated for UNIQUE above, so fresh databases skip it.
+CREATE UNIQUE INDEX IF NOT EXISTS tenders_history_tender_id_version_key
+    ON tenders_history (tender_id, version);
+
+-- tender_version archives the old row and increments version
+-- whenever editable fields of a tender change.
+CREATE OR REPLACE FUNCTION tender_version() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.name IS DISTINCT FROM OLD.name
+        OR NEW.description IS DISTINCT FROM OLD.description
+        OR NEW.service_type IS DISTINCT FROM OLD.service_type THEN
+        INSERT INTO tenders_history (
+            tender_id, organization_id, creator_id, name, description,
+            status, service_type, version, created_at
+        )
+        VALUES (
+            OLD.id, OLD.organization_id, OLD.creator_id, OLD.name, OLD.description,
+            OLD.status, OLD.service_type, OLD.version, OLD.created_at
+        );
+        NEW.version := OLD.version + 1;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS tender_version_trigger ON tenders;
+CREATE TRIGGER tender_version_trigger
+    BEFORE UPDATE ON tenders
+    FOR EACH ROW
+    EXECUTE FUNCTION tender_version();
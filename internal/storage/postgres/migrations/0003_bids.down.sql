@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS bid_submission_trigger ON bids;
+DROP FUNCTION IF EXISTS bid_submission();
+DROP TABLE IF EXISTS submissions;
+DROP TRIGGER IF EXISTS bid_version_trigger ON bids;
+DROP FUNCTION IF EXISTS bid_version();
+DROP TABLE IF EXISTS bids_history;
+DROP TABLE IF EXISTS bids;
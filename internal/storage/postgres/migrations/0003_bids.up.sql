@@ -0,0 +1,118 @@
+-- Objects are created only if missing so that databases whose tables were
+-- created by hand before migrations were introduced can be migrated too.
+
+-- require_columns is the same check as in 0002_tenders.
+CREATE OR REPLACE FUNCTION pg_temp.require_columns(tbl TEXT, columns TEXT[]) RETURNS VOID AS $$
+DECLARE
+    missing TEXT;
+BEGIN
+    SELECT string_agg(c, ', ') INTO missing
+    FROM unnest(columns) AS c
+    WHERE NOT EXISTS (
+        SELECT 1 FROM information_schema.columns ic
+        WHERE ic.table_schema = current_schema()
+            AND ic.table_name = tbl
+            AND ic.column_name = c
+    );
+    IF missing IS NOT NULL THEN
+        RAISE EXCEPTION 'table % exists but lacks columns: %', tbl, missing;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TABLE IF NOT EXISTS bids (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tender_id UUID NOT NULL REFERENCES tenders(id) ON DELETE CASCADE,
+    organization_id UUID REFERENCES organization(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(500) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'Created',
+    author_type VARCHAR(20) NOT NULL,
+    author_id UUID NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+SELECT pg_temp.require_columns('bids', ARRAY[
+    'id', 'tender_id', 'organization_id', 'name', 'description',
+    'status', 'author_type', 'author_id', 'version', 'created_at'
+]);
+
+CREATE INDEX IF NOT EXISTS bids_tender_id_idx ON bids (tender_id);
+CREATE INDEX IF NOT EXISTS bids_author_id_idx ON bids (author_id);
+
+-- bids_history keeps previous versions of bids,
+-- the current version lives only in bids.
+CREATE TABLE IF NOT EXISTS bids_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    bid_id UUID NOT NULL REFERENCES bids(id) ON DELETE CASCADE,
+    tender_id UUID NOT NULL,
+    organization_id UUID,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(500) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    author_type VARCHAR(20) NOT NULL,
+    author_id UUID NOT NULL,
+    version INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (bid_id, version)
+);
+
+SELECT pg_temp.require_columns('bids_history', ARRAY[
+    'id', 'bid_id', 'tender_id', 'organization_id', 'name', 'description',
+    'status', 'author_type', 'author_id', 'version', 'created_at'
+]);
+-- Later migrations upsert history ON CONFLICT (bid_id, version),
+-- a table created by hand may lack the constraint.
+CREATE UNIQUE INDEX IF NOT EXISTS bids_history_bid_id_version_key
+    ON bids_history (bid_id, version);
+
+-- bid_version archives the old row and increments version
+-- whenever editable fields of a bid change.
+CREATE OR REPLACE FUNCTION bid_version() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.name IS DISTINCT FROM OLD.name
+        OR NEW.description IS DISTINCT FROM OLD.description THEN
+        INSERT INTO bids_history (
+            bid_id, tender_id, organization_id, name, description,
+            status, author_type, author_id, version, created_at
+        )
+        VALUES (
+            OLD.id, OLD.tender_id, OLD.organization_id, OLD.name, OLD.description,
+            OLD.status, OLD.author_type, OLD.author_id, OLD.version, OLD.created_at
+        );
+        NEW.version := OLD.version + 1;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS bid_version_trigger ON bids;
+CREATE TRIGGER bid_version_trigger
+    BEFORE UPDATE ON bids
+    FOR EACH ROW
+    EXECUTE FUNCTION bid_version();
+
+-- submissions accumulates decisions made on a bid.
+CREATE TABLE IF NOT EXISTS submissions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    bid_id UUID NOT NULL UNIQUE REFERENCES bids(id) ON DELETE CASCADE,
+    accept_rate INTEGER NOT NULL DEFAULT 0,
+    rejected BOOLEAN NOT NULL DEFAULT false
+);
+
+SELECT pg_temp.require_columns('submissions', ARRAY['bid_id', 'accept_rate', 'rejected']);
+
+CREATE OR REPLACE FUNCTION bid_submission() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO submissions (bid_id) VALUES (NEW.id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS bid_submission_trigger ON bids;
+CREATE TRIGGER bid_submission_trigger
+    AFTER INSERT ON bids
+    FOR EACH ROW
+    EXECUTE FUNCTION bid_submission();
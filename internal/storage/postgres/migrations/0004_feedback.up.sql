@@ -0,0 +1,33 @@
+-- Objects are created only if missing so that databases whose tables were
+-- created by hand before migrations were introduced can be migrated too.
+
+-- require_columns is the same check as in 0002_tenders.
+CREATE OR REPLACE FUNCTION pg_temp.require_columns(tbl TEXT, columns TEXT[]) RETURNS VOID AS $$
+DECLARE
+    missing TEXT;
+BEGIN
+    SELECT string_agg(c, ', ') INTO missing
+    FROM unnest(columns) AS c
+    WHERE NOT EXISTS (
+        SELECT 1 FROM information_schema.columns ic
+        WHERE ic.table_schema = current_schema()
+            AND ic.table_name = tbl
+            AND ic.column_name = c
+    );
+    IF missing IS NOT NULL THEN
+        RAISE EXCEPTION 'table % exists but lacks columns: %', tbl, missing;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TABLE IF NOT EXISTS feedback (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    bid_id UUID NOT NULL REFERENCES bids(id) ON DELETE CASCADE,
+    feedback VARCHAR(1000) NOT NULL,
+    creator_id UUID NOT NULL REFERENCES employee(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS feedback_bid_id_idx ON feedback (bid_id);
+
+SELECT pg_temp.require_columns('feedback', ARRAY['id', 'bid_id', 'feedback', 'creator_id', 'created_at']);
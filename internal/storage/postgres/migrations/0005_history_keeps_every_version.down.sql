@@ -0,0 +1,66 @@
+DROP TRIGGER tender_version_trigger ON tenders;
+DROP FUNCTION tender_version();
+DROP TRIGGER bid_version_trigger ON bids;
+DROP FUNCTION bid_version();
+
+-- Previous schema keeps only old versions in history.
+DELETE FROM tenders_history th
+USING tenders t
+WHERE th.tender_id = t.id AND th.version = t.version;
+
+DELETE FROM bids_history bh
+USING bids b
+WHERE bh.bid_id = b.id AND bh.version = b.version;
+
+-- tender_version archives the old row and increments version
+-- whenever editable fields of a tender change.
+CREATE FUNCTION tender_version() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.name IS DISTINCT FROM OLD.name
+        OR NEW.description IS DISTINCT FROM OLD.description
+        OR NEW.service_type IS DISTINCT FROM OLD.service_type THEN
+        INSERT INTO tenders_history (
+            tender_id, organization_id, creator_id, name, description,
+            status, service_type, version, created_at
+        )
+        VALUES (
+            OLD.id, OLD.organization_id, OLD.creator_id, OLD.name, OLD.description,
+            OLD.status, OLD.service_type, OLD.version, OLD.created_at
+        );
+        NEW.version := OLD.version + 1;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tender_version_trigger
+    BEFORE UPDATE ON tenders
+    FOR EACH ROW
+    EXECUTE FUNCTION tender_version();
+
+-- bid_version archives the old row and increments version
+-- whenever editable fields of a bid change.
+CREATE FUNCTION bid_version() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.name IS DISTINCT FROM OLD.name
+        OR NEW.description IS DISTINCT FROM OLD.description THEN
+        INSERT INTO bids_history (
+            bid_id, tender_id, organization_id, name, description,
+            status, author_type, author_id, version, created_at
+        )
+        VALUES (
+            OLD.id, OLD.tender_id, OLD.organization_id, OLD.name, OLD.description,
+            OLD.status, OLD.author_type, OLD.author_id, OLD.version, OLD.created_at
+        );
+        NEW.version := OLD.version + 1;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER bid_version_trigger
+    BEFORE UPDATE ON bids
+    FOR EACH ROW
+    EXECUTE FUNCTION bid_version();
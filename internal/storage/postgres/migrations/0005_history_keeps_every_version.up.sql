@@ -0,0 +1,65 @@
+-- History now keeps every version including the current one. Versions are
+-- incremented explicitly by the application, so rollback can copy an old
+-- snapshot forward as a new version without disabling triggers.
+DROP TRIGGER tender_version_trigger ON tenders;
+DROP FUNCTION tender_version();
+DROP TRIGGER bid_version_trigger ON bids;
+DROP FUNCTION bid_version();
+
+INSERT INTO tenders_history (
+    tender_id, organization_id, creator_id, name, description,
+    status, service_type, version, created_at
+)
+SELECT id, organization_id, creator_id, name, description,
+    status, service_type, version, created_at
+FROM tenders
+ON CONFLICT (tender_id, version) DO NOTHING;
+
+INSERT INTO bids_history (
+    bid_id, tender_id, organization_id, name, description,
+    status, author_type, author_id, version, created_at
+)
+SELECT id, tender_id, organization_id, name, description,
+    status, author_type, author_id, version, created_at
+FROM bids
+ON CONFLICT (bid_id, version) DO NOTHING;
+
+CREATE FUNCTION tender_version() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO tenders_history (
+        tender_id, organization_id, creator_id, name, description,
+        status, service_type, version, created_at
+    )
+    VALUES (
+        NEW.id, NEW.organization_id, NEW.creator_id, NEW.name, NEW.description,
+        NEW.status, NEW.service_type, NEW.version, NEW.created_at
+    );
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tender_version_trigger
+    AFTER INSERT OR UPDATE OF version ON tenders
+    FOR EACH ROW
+    EXECUTE FUNCTION tender_version();
+
+CREATE FUNCTION bid_version() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO bids_history (
+        bid_id, tender_id, organization_id, name, description,
+        status, author_type, author_id, version, created_at
+    )
+    VALUES (
+        NEW.id, NEW.tender_id, NEW.organization_id, NEW.name, NEW.description,
+        NEW.status, NEW.author_type, NEW.author_id, NEW.version, NEW.created_at
+    );
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER bid_version_trigger
+    AFTER INSERT OR UPDATE OF version ON bids
+    FOR EACH ROW
+    EXECUTE FUNCTION bid_version();
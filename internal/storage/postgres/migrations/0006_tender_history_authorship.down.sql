@@ -0,0 +1,21 @@
+CREATE OR REPLACE FUNCTION tender_version() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO tenders_history (
+        tender_id, organization_id, creator_id, name, description,
+        status, service_type, version, created_at
+    )
+    VALUES (
+        NEW.id, NEW.organization_id, NEW.creator_id, NEW.name, NEW.description,
+        NEW.status, NEW.service_type, NEW.version, NEW.created_at
+    );
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE tenders_history
+    DROP COLUMN changed_by,
+    DROP COLUMN changed_at;
+
+ALTER TABLE tenders
+    DROP COLUMN updated_by;
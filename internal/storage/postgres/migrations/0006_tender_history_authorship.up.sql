@@ -0,0 +1,31 @@
+-- Track who created every version of a tender and when.
+ALTER TABLE tenders
+    ADD COLUMN updated_by UUID REFERENCES employee(id) ON DELETE SET NULL;
+
+ALTER TABLE tenders_history
+    ADD COLUMN changed_by UUID REFERENCES employee(id) ON DELETE SET NULL,
+    ADD COLUMN changed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE tenders_history
+SET changed_by = creator_id
+WHERE changed_by IS NULL;
+
+UPDATE tenders_history
+SET changed_at = created_at
+WHERE version = 1;
+
+CREATE OR REPLACE FUNCTION tender_version() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO tenders_history (
+        tender_id, organization_id, creator_id, name, description,
+        status, service_type, version, created_at, changed_by
+    )
+    VALUES (
+        NEW.id, NEW.organization_id, NEW.creator_id, NEW.name, NEW.description,
+        NEW.status, NEW.service_type, NEW.version, NEW.created_at,
+        COALESCE(NEW.updated_by, NEW.creator_id)
+    );
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,21 @@
+CREATE OR REPLACE FUNCTION bid_version() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO bids_history (
+        bid_id, tender_id, organization_id, name, description,
+        status, author_type, author_id, version, created_at
+    )
+    VALUES (
+        NEW.id, NEW.tender_id, NEW.organization_id, NEW.name, NEW.description,
+        NEW.status, NEW.author_type, NEW.author_id, NEW.version, NEW.created_at
+    );
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE bids_history
+    DROP COLUMN changed_by,
+    DROP COLUMN changed_at;
+
+ALTER TABLE bids
+    DROP COLUMN updated_by;
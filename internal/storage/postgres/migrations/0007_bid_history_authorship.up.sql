@@ -0,0 +1,32 @@
+-- Track who created every version of a bid and when.
+ALTER TABLE bids
+    ADD COLUMN updated_by UUID REFERENCES employee(id) ON DELETE SET NULL;
+
+ALTER TABLE bids_history
+    ADD COLUMN changed_by UUID REFERENCES employee(id) ON DELETE SET NULL,
+    ADD COLUMN changed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE bids_history bh
+SET changed_by = bh.author_id
+WHERE bh.changed_by IS NULL
+    AND EXISTS (SELECT 1 FROM employee e WHERE e.id = bh.author_id);
+
+UPDATE bids_history
+SET changed_at = created_at
+WHERE version = 1;
+
+CREATE OR REPLACE FUNCTION bid_version() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO bids_history (
+        bid_id, tender_id, organization_id, name, description,
+        status, author_type, author_id, version, created_at, changed_by
+    )
+    VALUES (
+        NEW.id, NEW.tender_id, NEW.organization_id, NEW.name, NEW.description,
+        NEW.status, NEW.author_type, NEW.author_id, NEW.version, NEW.created_at,
+        NEW.updated_by
+    );
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
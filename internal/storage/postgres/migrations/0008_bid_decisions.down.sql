@@ -0,0 +1,4 @@
+ALTER TABLE submissions
+    DROP COLUMN approved;
+
+DROP TABLE bid_decisions;
@@ -0,0 +1,13 @@
+-- bid_decisions keeps one decision of every responsible user, so the same
+-- person cannot vote twice. submissions.approved marks bids that reached
+-- quorum; accept_rate is kept as the number of approvals.
+CREATE TABLE bid_decisions (
+    bid_id UUID NOT NULL REFERENCES bids(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES employee(id) ON DELETE CASCADE,
+    decision VARCHAR(20) NOT NULL CHECK (decision IN ('Approved', 'Rejected')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (bid_id, user_id)
+);
+
+ALTER TABLE submissions
+    ADD COLUMN approved BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,34 @@
+ALTER TABLE bids
+    DROP CONSTRAINT bids_status_check;
+
+CREATE TABLE submissions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    bid_id UUID NOT NULL UNIQUE REFERENCES bids(id) ON DELETE CASCADE,
+    accept_rate INTEGER NOT NULL DEFAULT 0,
+    rejected BOOLEAN NOT NULL DEFAULT false,
+    approved BOOLEAN NOT NULL DEFAULT false
+);
+
+INSERT INTO submissions (bid_id, accept_rate, rejected, approved)
+SELECT b.id,
+    (SELECT COUNT(*) FROM bid_decisions d
+        WHERE d.bid_id = b.id AND d.decision = 'Approved'),
+    b.status = 'Rejected',
+    b.status = 'Approved'
+FROM bids b;
+
+UPDATE bids
+SET status = 'Published'
+WHERE status IN ('Approved', 'Rejected');
+
+CREATE FUNCTION bid_submission() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO submissions (bid_id) VALUES (NEW.id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER bid_submission_trigger
+    AFTER INSERT ON bids
+    FOR EACH ROW
+    EXECUTE FUNCTION bid_submission();
@@ -0,0 +1,22 @@
+-- Decision results become bid statuses, so submissions are not needed
+-- anymore: approvals are counted from bid_decisions.
+UPDATE bids b
+SET status = 'Approved'
+FROM submissions s
+WHERE s.bid_id = b.id AND s.approved;
+
+UPDATE bids b
+SET status = 'Rejected'
+FROM submissions s
+WHERE s.bid_id = b.id AND s.rejected AND NOT s.approved;
+
+DROP TRIGGER bid_submission_trigger ON bids;
+DROP FUNCTION bid_submission();
+DROP TABLE submissions;
+
+-- NOT VALID keeps rows written before statuses were checked,
+-- every new write is validated.
+ALTER TABLE bids
+    ADD CONSTRAINT bids_status_check
+    CHECK (status IN ('Created', 'Published', 'Canceled', 'Approved', 'Rejected'))
+    NOT VALID;
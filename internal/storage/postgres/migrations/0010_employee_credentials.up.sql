@@ -0,0 +1,20 @@
+-- Passwords of employees are stored as argon2id hashes. failed_attempts
+-- counts wrong passwords in a row, account is locked until locked_until
+-- once the limit is reached.
+CREATE TABLE employee_credentials (
+    user_id UUID PRIMARY KEY REFERENCES employee(id) ON DELETE CASCADE,
+    password_hash TEXT NOT NULL,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Reset tokens are kept as sha256 hashes, a token is deleted once used.
+CREATE TABLE password_resets (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES employee(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX password_resets_user_id_idx ON password_resets (user_id);
@@ -0,0 +1,3 @@
+ALTER TABLE organization_responsible
+    DROP CONSTRAINT organization_responsible_member_key,
+    DROP COLUMN role;
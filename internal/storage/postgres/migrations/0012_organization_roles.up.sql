@@ -0,0 +1,12 @@
+-- Responsibles of organization get a role which limits actions they may
+-- perform. Existing responsibles keep full power and become owners.
+DELETE FROM organization_responsible a
+USING organization_responsible b
+WHERE a.organization_id = b.organization_id
+  AND a.user_id = b.user_id
+  AND a.id > b.id;
+
+ALTER TABLE organization_responsible
+    ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'owner'
+        CHECK (role IN ('owner', 'procurement_manager', 'approver', 'viewer')),
+    ADD CONSTRAINT organization_responsible_member_key UNIQUE (organization_id, user_id);
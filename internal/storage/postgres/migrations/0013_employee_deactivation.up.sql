@@ -0,0 +1,4 @@
+-- Deactivated employees are kept so that their tenders, bids, feedback
+-- and history still refer to them, but they cannot act anymore.
+ALTER TABLE employee
+    ADD COLUMN deactivated_at TIMESTAMPTZ;
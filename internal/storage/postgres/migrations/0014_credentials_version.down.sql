@@ -0,0 +1,2 @@
+ALTER TABLE employee_credentials
+    DROP COLUMN version;
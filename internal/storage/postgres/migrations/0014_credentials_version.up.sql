@@ -0,0 +1,5 @@
+-- version of credentials is incremented whenever password is set, tokens
+-- carry the version they were issued for and are rejected once it changes.
+-- Tokens issued before this migration carry no version and are revoked.
+ALTER TABLE employee_credentials
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
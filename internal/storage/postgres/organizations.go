@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// organizationColumns are selected from organization o
+const organizationColumns = `o.id, o.name, COALESCE(o.description, ''), COALESCE(o.type::text, ''), o.created_at`
+
+func scanOrganization(row pgx.Row) (models.Organization, error) {
+	var o models.Organization
+	err := row.Scan(&o.ID, &o.Name, &o.Description, &o.Type, &o.CreatedAt)
+
+	return o, err
+}
+
+// InsertOrganization creates organization and makes ownerID its owner
+func (s *Storage) InsertOrganization(ctx context.Context, newOrganization models.NewOrganizationRequest, ownerID string) (models.Organization, error) {
+	var organization models.Organization
+	err := s.inTx(ctx, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO public.organization AS o (name, description, type)
+			VALUES ($1, $2, $3)
+			RETURNING ` + organizationColumns + `;
+		`
+		var err error
+		organization, err = scanOrganization(tx.QueryRow(ctx, query,
+			newOrganization.Name, newOrganization.Description, newOrganization.Type))
+		if err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO public.organization_responsible (organization_id, user_id, role)
+			VALUES ($1, $2, $3);
+		`
+		_, err = tx.Exec(ctx, query, organization.ID, ownerID, domain.RoleOwner)
+		return err
+	})
+	if err != nil {
+		return models.Organization{}, fmt.Errorf("cannot insert organization: %w", err)
+	}
+
+	return organization, nil
+}
+
+// GetOrganization returns storage.ErrNotFound if organization does not exist
+func (s *Storage) GetOrganization(ctx context.Context, organizationID string) (models.Organization, error) {
+	query := `
+		SELECT ` + organizationColumns + `
+		FROM public.organization o
+		WHERE o.id = $1;
+	`
+
+	organization, err := scanOrganization(s.Pool.QueryRow(ctx, query, organizationID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Organization{}, fmt.Errorf("cannot get organization: %s %w", organizationID, storage.ErrNotFound)
+		}
+
+		return models.Organization{}, fmt.Errorf("cannot get organization: %w", err)
+	}
+
+	return organization, nil
+}
+
+// EditOrganization applies patch, returns storage.ErrNotFound
+// if organization does not exist
+func (s *Storage) EditOrganization(ctx context.Context, organizationID string, patch models.EditOrganizationRequest) (models.Organization, error) {
+	if err := patch.Validate(); err != nil {
+		return models.Organization{}, fmt.Errorf("cannot change organization: %w", err)
+	}
+
+	set := newSetClause(organizationID)
+	if patch.Name.Set {
+		set.add("name", patch.Name.Value)
+	}
+	if patch.Description.Set {
+		set.add("description", patch.Description.Value)
+	}
+	if patch.Type.Set {
+		set.add("type", patch.Type.Value)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE public.organization o
+		SET %s, updated_at = CURRENT_TIMESTAMP
+		WHERE o.id = $1
+		RETURNING %s;
+	`, set, organizationColumns)
+
+	organization, err := scanOrganization(s.Pool.QueryRow(ctx, query, set.args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Organization{}, fmt.Errorf("cannot change organization: %s %w", organizationID, storage.ErrNotFound)
+		}
+
+		return models.Organization{}, fmt.Errorf("cannot change organization: %w", err)
+	}
+
+	return organization, nil
+}
+
+// DeleteOrganization removes organization, ON DELETE CASCADE removes its
+// tenders, bids, responsibles and API keys. Returns storage.ErrNotFound if
+// organization does not exist and domain.ErrOrganizationInUse if any of
+// its tenders or bids has a status from domain.TenderStatusesBlockingDeletion
+// or domain.BidStatusesBlockingDeletion, or any bid on its tenders has a
+// status from domain.ReceivedBidStatusesBlockingDeletion. Organization row
+// is locked, so tenders and bids cannot be added to it while the check runs
+func (s *Storage) DeleteOrganization(ctx context.Context, organizationID string) (models.Organization, error) {
+	var organization models.Organization
+	err := s.inTx(ctx, func(tx pgx.Tx) error {
+		query := `
+			SELECT ` + organizationColumns + `
+			FROM public.organization o
+			WHERE o.id = $1
+			FOR UPDATE;
+		`
+		var err error
+		organization, err = scanOrganization(tx.QueryRow(ctx, query, organizationID))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		query = `
+			SELECT EXISTS (
+				SELECT 1 FROM public.tenders
+				WHERE organization_id = $1 AND status = ANY($2)
+			) OR EXISTS (
+				SELECT 1 FROM public.bids
+				WHERE organization_id = $1 AND status = ANY($3)
+			) OR EXISTS (
+				SELECT 1 FROM public.bids b
+				JOIN public.tenders t ON t.id = b.tender_id
+				WHERE t.organization_id = $1 AND b.status = ANY($4)
+			);
+		`
+		var inUse bool
+		err = tx.QueryRow(ctx, query, organizationID, domain.TenderStatusesBlockingDeletion,
+			domain.BidStatusesBlockingDeletion, domain.ReceivedBidStatusesBlockingDeletion).Scan(&inUse)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return domain.ErrOrganizationInUse
+		}
+
+		_, err = tx.Exec(ctx, `DELETE FROM public.organization WHERE id = $1;`, organizationID)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, domain.ErrOrganizationInUse) {
+			return models.Organization{}, err
+		}
+
+		return models.Organization{}, fmt.Errorf("cannot delete organization: %w", err)
+	}
+
+	return organization, nil
+}
@@ -3,36 +3,40 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
 	"zadanie-6105/internal/storage/models"
 
 	"github.com/jackc/pgx/v5"
 )
 
 const (
-    StatusCreated = "Created"
-    StatusPublished = "Published"
-    StatusClosed = "Closed"
-    StatusCanceled = "Canceled"
+    StatusCreated = domain.TenderCreated
+    StatusPublished = domain.TenderPublished
+    StatusClosed = domain.TenderClosed
+    StatusCanceled = domain.TenderCanceled
 )
 
 // GetTenderList takes limit, offset and service_type to return
-// list of tenders with provided params
-func (s *Storage) GetTenderList(ctx context.Context, limit, offset int, service_type string) ([]models.Tender, error) {
+// list of tenders with provided params which userID can see.
+// Empty userID sees only public tenders
+func (s *Storage) GetTenderList(ctx context.Context, limit, offset int, service_type, userID string) ([]models.Tender, error) {
     var query string
     if service_type != "" {
         query = `
-            SELECT id, name, description, status, service_type, version, created_at
-            FROM tenders
-            WHERE service_type = $1
-            ORDER BY name ASC
+            SELECT t.id, t.name, t.description, t.status, t.service_type, t.organization_id, t.version, t.created_at
+            FROM tenders t
+            WHERE t.service_type = $1 AND ` + tenderVisibleTo(4) + `
+            ORDER BY t.name ASC
             LIMIT $2 OFFSET $3
 		`
         
     } else {
         query = `
-            SELECT id, name, description, status, service_type, version, created_at
-            FROM tenders
-            ORDER BY name ASC
+            SELECT t.id, t.name, t.description, t.status, t.service_type, t.organization_id, t.version, t.created_at
+            FROM tenders t
+            WHERE ` + tenderVisibleTo(3) + `
+            ORDER BY t.name ASC
             LIMIT $1 OFFSET $2
 		`  
     }
@@ -40,9 +44,9 @@ func (s *Storage) GetTenderList(ctx context.Context, limit, offset int, service_
     var rows pgx.Rows
     var err error
     if service_type != "" {
-        rows, err = s.Pool.Query(ctx, query, service_type, limit, offset)
+        rows, err = s.Pool.Query(ctx, query, service_type, limit, offset, userID)
     } else {
-        rows, err = s.Pool.Query(ctx, query, limit, offset)
+        rows, err = s.Pool.Query(ctx, query, limit, offset, userID)
     }
     
     if err != nil {
@@ -55,7 +59,7 @@ func (s *Storage) GetTenderList(ctx context.Context, limit, offset int, service_
         var t models.Tender
         err := rows.Scan(
             &t.ID, &t.Name, &t.Description, &t.Status,
-            &t.ServiceType, &t.Version, &t.CreatedAt,
+            &t.ServiceType, &t.OrganizationID, &t.Version, &t.CreatedAt,
         )
         if err != nil {
             return nil, fmt.Errorf("cannot scan row: %w", err)
@@ -76,7 +80,7 @@ func (s *Storage) InsertTender(ctx context.Context, newTender *models.NewTenderR
     query := `
         INSERT INTO public.tenders (name, organization_id, creator_id, description, status, service_type, version)
         VALUES ($1, $2, $3, $4, $5, $6, $7)
-        RETURNING id, name, description, status, service_type, version, created_at;
+        RETURNING id, name, description, status, service_type, organization_id, version, created_at;
     `
     
     var tender models.Tender
@@ -84,7 +88,7 @@ func (s *Storage) InsertTender(ctx context.Context, newTender *models.NewTenderR
     row := s.Pool.QueryRow(ctx, query, newTender.Name, newTender.OrganizationID, creatorID, newTender.Description, StatusCreated, newTender.ServiceType, 1)
     err := row.Scan(
         &tender.ID, &tender.Name, &tender.Description, &tender.Status,
-        &tender.ServiceType, &tender.Version, &tender.CreatedAt,
+        &tender.ServiceType, &tender.OrganizationID, &tender.Version, &tender.CreatedAt,
     )
 
     if err != nil {
@@ -96,16 +100,17 @@ func (s *Storage) InsertTender(ctx context.Context, newTender *models.NewTenderR
 
 // GetMyTendersList takes limit, offset, userID and return 
 // slice of tenders where creator_id=userID and error if occurs
-func (s *Storage) GetMyTendersList(ctx context.Context, limit, offset int, userID string) ([]models.Tender, error) {
+func (s *Storage) GetMyTendersList(ctx context.Context, limit, offset int, userID, organizationID string) ([]models.Tender, error) {
     query := `
-        SELECT id, name, description, status, service_type, version, created_at
+        SELECT id, name, description, status, service_type, organization_id, version, created_at
         FROM tenders
         WHERE creator_id = $1
+            AND (NULLIF($4, '') IS NULL OR organization_id = NULLIF($4, '')::uuid)
         ORDER BY name ASC
         LIMIT $2 OFFSET $3
     `
 
-    rows, err := s.Pool.Query(ctx, query, userID, limit, offset)
+    rows, err := s.Pool.Query(ctx, query, userID, limit, offset, organizationID)
     if err != nil {
         return nil, fmt.Errorf("cannot get tender list: %w", err)
     }
@@ -117,7 +122,7 @@ func (s *Storage) GetMyTendersList(ctx context.Context, limit, offset int, userI
         var t models.Tender
         err := rows.Scan(
             &t.ID, &t.Name, &t.Description, &t.Status,
-            &t.ServiceType, &t.Version, &t.CreatedAt,
+            &t.ServiceType, &t.OrganizationID, &t.Version, &t.CreatedAt,
         )
         if err != nil {
             return nil, fmt.Errorf("cannot scan row: %w", err)
@@ -154,177 +159,244 @@ func (s *Storage) GetTenderStatus(ctx context.Context, tenderID string) (string,
     return status, nil
 }
 
+// ChangeTenderStatus moves tender to status if domain allows such
+// transition. Tender row is locked, so concurrent changes and bid
+// decisions see the status written here
 func (s *Storage) ChangeTenderStatus(ctx context.Context, tenderID, status string) (models.Tender, error) {
-    switch status {
-    case "Created":
-        status = StatusCreated
-    case "Published":
-        status = StatusPublished
-    case "Closed":
-        status = StatusClosed
+    var tender models.Tender
+    err := s.inTx(ctx, func(tx pgx.Tx) error {
+        var current string
+        err := tx.QueryRow(ctx, "SELECT status FROM tenders WHERE id=$1 FOR UPDATE", tenderID).Scan(&current)
+        if err != nil {
+            if err == pgx.ErrNoRows {
+                return storage.ErrNotFound
+            }
+
+            return fmt.Errorf("cannot get tender status: %w", err)
+        }
+
+        if err := domain.TenderTransition(current, status); err != nil {
+            return err
+        }
+
+        query := `
+            UPDATE tenders SET status=$1
+            WHERE id=$2
+            RETURNING id, name, description, status, service_type, organization_id, version, created_at;
+        `
+
+        row := tx.QueryRow(ctx, query, status, tenderID)
+        err = row.Scan(
+            &tender.ID, &tender.Name, &tender.Description,
+            &tender.Status, &tender.ServiceType, &tender.OrganizationID, &tender.Version, &tender.CreatedAt,
+        )
+        if err != nil {
+            return fmt.Errorf("cannot update tender: %w", err)
+        }
+
+        return nil
+    })
+    if err != nil {
+        return models.Tender{}, err
     }
 
-    query := `
-        UPDATE tenders SET status=$1
-        WHERE id=$2
-        RETURNING id, name, description, status, service_type, version, created_at;
-    `
+    return tender, nil
+}
+
+// EditTender applies patch to tender on behalf of userID with parameterized
+// UPDATE and increments version, tender_version_trigger saves it to history.
+// Returns storage.ErrNotFound if there is no such tender
+func (s *Storage) EditTender(ctx context.Context, tenderID, userID string, patch models.EditTenderRequest) (models.Tender, error) {
+    if err := patch.Validate(); err != nil {
+        return models.Tender{}, fmt.Errorf("cannot change tender: %w", err)
+    }
+
+    set := newSetClause(tenderID)
+    if patch.Name.Set {
+        set.add("name", patch.Name.Value)
+    }
+    if patch.Description.Set {
+        set.add("description", patch.Description.Value)
+    }
+    if patch.ServiceType.Set {
+        set.add("service_type", patch.ServiceType.Value)
+    }
+    set.add("updated_by", userID)
+
+    query := fmt.Sprintf("UPDATE tenders SET %s, version=version+1 WHERE id=$1 RETURNING id, name, description, status, service_type, organization_id, version, created_at;", set)
 
     var tender models.Tender
-    row := s.Pool.QueryRow(ctx, query, status, tenderID)
+    row := s.Pool.QueryRow(ctx, query, set.args...)
     err := row.Scan(
         &tender.ID, &tender.Name, &tender.Description,
-        &tender.Status, &tender.ServiceType, &tender.Version, &tender.CreatedAt,
+        &tender.Status, &tender.ServiceType, &tender.OrganizationID, &tender.Version, &tender.CreatedAt,
     )
     if err != nil {
         if err == pgx.ErrNoRows {
-            return models.Tender{}, nil
+            return models.Tender{}, storage.ErrNotFound
         }
 
-        return models.Tender{}, fmt.Errorf("cannot update tender: %w", err)
+        return models.Tender{}, fmt.Errorf("cannot change tender: %w", err)
     }
 
     return tender, nil
 }
 
-func (s *Storage) EditTender(ctx context.Context, tenderID, changeStr string) (models.Tender, error) {
-    query := fmt.Sprintf("UPDATE tenders SET %s WHERE id=$1 RETURNING id, name, description, status, service_type, version, created_at;", changeStr)
+// RollbackTender copies editable fields of the requested version forward
+// as a new version, history is kept untouched. Status is not versioned and
+// stays as is. Returns storage.ErrNotFound if tender or version does not exist
+func (s *Storage) RollbackTender(ctx context.Context, tenderID, userID string, version int) (models.Tender, error) {
+    query := `
+        UPDATE tenders t
+        SET name = th.name,
+            description = th.description,
+            service_type = th.service_type,
+            version = t.version + 1,
+            updated_by = $3
+        FROM tenders_history th
+        WHERE t.id = $1 AND th.tender_id = t.id AND th.version = $2
+        RETURNING t.id, t.name, t.description, t.status, t.service_type, t.organization_id, t.version, t.created_at;
+    `
 
     var tender models.Tender
-    row := s.Pool.QueryRow(ctx, query, tenderID)
+    row := s.Pool.QueryRow(ctx, query, tenderID, version, userID)
     err := row.Scan(
         &tender.ID, &tender.Name, &tender.Description,
-        &tender.Status, &tender.ServiceType, &tender.Version, &tender.CreatedAt,
+        &tender.Status, &tender.ServiceType, &tender.OrganizationID, &tender.Version, &tender.CreatedAt,
     )
     if err != nil {
         if err == pgx.ErrNoRows {
-            return models.Tender{}, nil
+            return models.Tender{}, storage.ErrNotFound
         }
 
-        return models.Tender{}, fmt.Errorf("cannot change tender: %w", err)
+        return models.Tender{}, fmt.Errorf("cannot rollback tender: %w", err)
     }
 
     return tender, nil
 }
 
-func (s *Storage) RollbackTender(ctx context.Context, tenderID, version string) (models.Tender, error) {
-    conn, err := s.Pool.Acquire(ctx)
-    if err != nil {
-        
-        return models.Tender{}, err
-    }
-    defer conn.Release()
-
-    transaction, err := conn.BeginTx(ctx, pgx.TxOptions{})
-    if err != nil {
-        
-        return models.Tender{}, err
-    }
-
-    // Отключаем триггер
-    _, err = transaction.Exec(ctx, "ALTER TABLE tenders DISABLE TRIGGER tender_version_trigger")
-    if err != nil {
-        
-        return models.Tender{}, transaction.Rollback(ctx)
-    }
-
-    // Запрос на обновление тендера
-    updateQuery := `
-        UPDATE tenders
-        SET organization_id = th.organization_id,
-            creator_id = th.creator_id,
-            name = th.name,
-            description = th.description,
-            status = th.status,
-            service_type = th.service_type,
-            created_at = th.created_at,
-            version = $2
+// GetTenderVersions returns page of tender versions starting from the newest
+func (s *Storage) GetTenderVersions(ctx context.Context, tenderID string, limit, offset int) ([]models.TenderVersion, error) {
+    query := `
+        SELECT th.version, th.name, th.description, th.service_type, th.status,
+            COALESCE(e.username, ''), th.changed_at
         FROM tenders_history th
-        WHERE th.tender_id = $1 AND th.version = $2 AND tenders.id = th.tender_id;
+        LEFT JOIN employee e ON e.id = th.changed_by
+        WHERE th.tender_id = $1
+        ORDER BY th.version DESC
+        LIMIT $2 OFFSET $3;
     `
-    
-    
-    _, err = transaction.Exec(ctx, updateQuery, tenderID, version)
-    if err != nil {
-        
-        return models.Tender{}, transaction.Rollback(ctx)
-    }
 
-    // Удаляем все версии после той и ту, к которой мы делаем роллбэк
-    deleteQuery := `
-        DELETE FROM tenders_history
-        WHERE tender_id = $1 AND version >= $2;
-    `
-    
-    _, err = transaction.Exec(ctx, deleteQuery, tenderID, version)
+    rows, err := s.Pool.Query(ctx, query, tenderID, limit, offset)
     if err != nil {
-        
-        return models.Tender{}, transaction.Rollback(ctx)
+        return nil, fmt.Errorf("cannot get tender versions: %w", err)
     }
+    defer rows.Close()
 
-    // Включаем триггер обратно
-    _, err = transaction.Exec(ctx, "ALTER TABLE tenders ENABLE TRIGGER tender_version_trigger")
-    if err != nil {
-        
-        return models.Tender{}, transaction.Rollback(ctx)
+    versions := make([]models.TenderVersion, 0, limit)
+    for rows.Next() {
+        var v models.TenderVersion
+        err := rows.Scan(
+            &v.Version, &v.Name, &v.Description, &v.ServiceType,
+            &v.Status, &v.ChangedBy, &v.ChangedAt,
+        )
+        if err != nil {
+            return nil, fmt.Errorf("cannot scan row: %w", err)
+        }
+        versions = append(versions, v)
     }
-
-    // Коммитим транзакцию
-    if err := transaction.Commit(ctx); err != nil {
-        
-        return models.Tender{}, err
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("error while reading rows: %w", err)
     }
 
-    // Получаем обновленный тендер
-    var tender models.Tender
-    selectQuery := `
-        SELECT id, name, description, status, service_type, version, created_at
-        FROM tenders
-        WHERE id = $1;
+    return versions, nil
+}
+
+// GetTenderVersion returns single version of tender
+// or storage.ErrNotFound if it does not exist
+func (s *Storage) GetTenderVersion(ctx context.Context, tenderID string, version int) (models.TenderVersion, error) {
+    query := `
+        SELECT th.version, th.name, th.description, th.service_type, th.status,
+            COALESCE(e.username, ''), th.changed_at
+        FROM tenders_history th
+        LEFT JOIN employee e ON e.id = th.changed_by
+        WHERE th.tender_id = $1 AND th.version = $2;
     `
-    
-    err = conn.QueryRow(ctx, selectQuery, tenderID).Scan(
-        &tender.ID, &tender.Name, &tender.Description,
-        &tender.Status, &tender.ServiceType, &tender.Version, &tender.CreatedAt,
+
+    var v models.TenderVersion
+    err := s.Pool.QueryRow(ctx, query, tenderID, version).Scan(
+        &v.Version, &v.Name, &v.Description, &v.ServiceType,
+        &v.Status, &v.ChangedBy, &v.ChangedAt,
     )
-    
     if err != nil {
-        
-        return models.Tender{}, err
+        if err == pgx.ErrNoRows {
+            return models.TenderVersion{}, storage.ErrNotFound
+        }
+
+        return models.TenderVersion{}, fmt.Errorf("cannot get tender version: %w", err)
     }
 
-    return tender, nil
+    return v, nil
 }
 
+// InsertBid creates bid on tender which accepts bids. Tender row is
+// locked for share, so its status cannot change until the bid is saved.
+// Returns storage.ErrNotFound if there is no such tender and
+// domain.ErrTenderClosed if it does not accept bids
 func (s *Storage) InsertBid(ctx context.Context, bid models.BidRequest, organizationID string) (models.Bid, error) {
-    query := `
-        INSERT INTO public.bids (tender_id, organization_id, name, description, status, author_type, author_id, version)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-        RETURNING id, name, status, author_type, author_id, version, created_at;
-    `
-
-    row := s.Pool.QueryRow(ctx, query, bid.TenderID, organizationID, bid.Name, bid.Description, 
-        StatusCreated, bid.AuthorType, bid.AuthorID, 1)
     var b models.Bid
-    err := row.Scan(
-        &b.ID, &b.Name, &b.Status, &b.AuthorType,
-        &b.AuthorID, &b.Version, &b.CreatedAt,
-    )
+    err := s.inTx(ctx, func(tx pgx.Tx) error {
+        var status string
+        err := tx.QueryRow(ctx, "SELECT status FROM tenders WHERE id=$1 FOR SHARE", bid.TenderID).Scan(&status)
+        if err != nil {
+            if err == pgx.ErrNoRows {
+                return storage.ErrNotFound
+            }
+
+            return fmt.Errorf("cannot get tender status: %w", err)
+        }
+        if !domain.TenderAcceptsBids(status) {
+            return domain.ErrTenderClosed
+        }
+
+        query := `
+            INSERT INTO public.bids (tender_id, organization_id, name, description, status, author_type, author_id, version, updated_by)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $7)
+            RETURNING id, name, description, status, tender_id, author_type, author_id, version, created_at;
+        `
+
+        row := tx.QueryRow(ctx, query, bid.TenderID, organizationID, bid.Name, bid.Description,
+            StatusCreated, bid.AuthorType, bid.AuthorID, 1)
+        err = row.Scan(
+            &b.ID, &b.Name, &b.Description, &b.Status, &b.TenderID, &b.AuthorType,
+            &b.AuthorID, &b.Version, &b.CreatedAt,
+        )
+        if err != nil {
+            return fmt.Errorf("cannot insert new bid: %w", err)
+        }
+
+        return nil
+    })
     if err != nil {
-        return models.Bid{}, fmt.Errorf("cannot insert new bid: %w", err)
+        return models.Bid{}, err
     }
 
     return b, nil
 }
 
-func (s *Storage) GetMyBidsList(ctx context.Context, limit, offset int, userID string) ([]models.Bid, error) {
+// GetMyBidsList returns page of bids made by userID or by organizations
+// he is responsible for, only bids of organizationID if it is not empty
+func (s *Storage) GetMyBidsList(ctx context.Context, limit, offset int, userID, organizationID string) ([]models.Bid, error) {
     query := `
-        SELECT id, name, status, author_type, author_id, version, created_at
-        FROM bids
-        WHERE author_id=$1;
+        SELECT b.id, b.name, b.description, b.status, b.tender_id, b.author_type, b.author_id, b.version, b.created_at
+        FROM bids b
+        WHERE ` + bidOwnedBy(1) + `
+            AND (NULLIF($4, '') IS NULL OR b.organization_id = NULLIF($4, '')::uuid)
+        ORDER BY b.name ASC
+        LIMIT $2 OFFSET $3;
     `
 
-    rows, err := s.Pool.Query(ctx, query, userID)
+    rows, err := s.Pool.Query(ctx, query, userID, limit, offset, organizationID)
     if err != nil {
         return nil, fmt.Errorf("cannot get bids list: %w", err)
     }
@@ -334,7 +406,7 @@ func (s *Storage) GetMyBidsList(ctx context.Context, limit, offset int, userID s
     for rows.Next() {
         var b models.Bid
         err := rows.Scan(
-            &b.ID, &b.Name, &b.Status, &b.AuthorType,
+            &b.ID, &b.Name, &b.Description, &b.Status, &b.TenderID, &b.AuthorType,
             &b.AuthorID, &b.Version, &b.CreatedAt,
         )
         if err != nil {
@@ -342,19 +414,24 @@ func (s *Storage) GetMyBidsList(ctx context.Context, limit, offset int, userID s
         }
         bids = append(bids, b)
     }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("error while reading rows: %w", err)
+    }
 
     return bids, nil
 }
 
-func (s *Storage) GetTenderBids(ctx context.Context, limit, offset int, tenderID string) ([]models.Bid, error) {
+// GetTenderBids returns page of bids on tender which userID can see
+func (s *Storage) GetTenderBids(ctx context.Context, limit, offset int, tenderID, userID string) ([]models.Bid, error) {
     query := `
-        SELECT id, name, status, author_type, author_id, version, created_at
-        FROM bids
-        WHERE tender_id=$1
+        SELECT b.id, b.name, b.description, b.status, b.tender_id, b.author_type, b.author_id, b.version, b.created_at
+        FROM bids b
+        WHERE b.tender_id=$1 AND ` + bidVisibleTo(4) + `
+        ORDER BY b.name ASC
         LIMIT $2 offset $3;
     `
 
-    rows, err := s.Pool.Query(ctx, query, tenderID, limit, offset)
+    rows, err := s.Pool.Query(ctx, query, tenderID, limit, offset, userID)
     if err != nil {
         return nil, fmt.Errorf("cannot get bids list: %w", err)
     }
@@ -364,7 +441,7 @@ func (s *Storage) GetTenderBids(ctx context.Context, limit, offset int, tenderID
     for rows.Next() {
         var b models.Bid
         err := rows.Scan(
-            &b.ID, &b.Name, &b.Status, &b.AuthorType,
+            &b.ID, &b.Name, &b.Description, &b.Status, &b.TenderID, &b.AuthorType,
             &b.AuthorID, &b.Version, &b.CreatedAt,
         )
         if err != nil {            
@@ -372,10 +449,14 @@ func (s *Storage) GetTenderBids(ctx context.Context, limit, offset int, tenderID
         }
         bids = append(bids, b)
     }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("error while reading rows: %w", err)
+    }
 
     return bids, nil
 }
 
+// GetBidStatus returns stored status of bid, empty if bid does not exist
 func (s *Storage) GetBidStatus(ctx context.Context, bidID string) (string, error) {
     query := `
         SELECT status
@@ -394,86 +475,194 @@ func (s *Storage) GetBidStatus(ctx context.Context, bidID string) (string, error
         return "", err
     }
 
-    switch status {
-    case StatusCreated:
-        status = "Created"
-    case StatusPublished:
-        status = "Published"
-    case StatusCanceled:
-        status = "Canceled"
-    }
-
     return status, nil
 }
 
+// ChangeBitStatus moves bid to status if domain allows such transition
 func (s *Storage) ChangeBitStatus(ctx context.Context, bidID, status string) (models.Bid, error) {
-    query := `
-        UPDATE bids SET status=$1
-        WHERE id=$2
-        RETURNING id, name, status, author_type, author_id, version, created_at;
-    `
-
-    row := s.Pool.QueryRow(ctx, query, status, bidID)
     var b models.Bid
-    err := row.Scan(
-        &b.ID, &b.Name, &b.Status, &b.AuthorType,
-        &b.AuthorID, &b.Version, &b.CreatedAt,
-    )
+    err := s.inTx(ctx, func(tx pgx.Tx) error {
+        var current string
+        err := tx.QueryRow(ctx, "SELECT status FROM bids WHERE id=$1 FOR UPDATE", bidID).Scan(&current)
+        if err != nil {
+            if err == pgx.ErrNoRows {
+                return storage.ErrNotFound
+            }
+
+            return fmt.Errorf("cannot get bid status: %w", err)
+        }
+
+        if err := domain.BidTransition(current, status); err != nil {
+            return err
+        }
+
+        query := `
+            UPDATE bids SET status=$1
+            WHERE id=$2
+            RETURNING id, name, description, status, tender_id, author_type, author_id, version, created_at;
+        `
+
+        row := tx.QueryRow(ctx, query, status, bidID)
+        err = row.Scan(
+            &b.ID, &b.Name, &b.Description, &b.Status, &b.TenderID, &b.AuthorType,
+            &b.AuthorID, &b.Version, &b.CreatedAt,
+        )
+        if err != nil {
+            return fmt.Errorf("cannot update row: %w", err)
+        }
+
+        return nil
+    })
     if err != nil {
-        return models.Bid{}, fmt.Errorf("cannot update row: %w", err)
+        return models.Bid{}, err
     }
 
     return b, nil
 }
 
-func (s *Storage) EditBid(ctx context.Context, bidID, changeStr string) (models.Bid, error) {
-    query := fmt.Sprintf("UPDATE bids SET %s WHERE id=$1 RETURNING id, name, status, author_type, author_id, version, created_at;", changeStr)
-    
+// EditBid applies patch to bid on behalf of userID with parameterized
+// UPDATE and increments version, bid_version_trigger saves it to history.
+// Bids in final status cannot be edited
+func (s *Storage) EditBid(ctx context.Context, bidID, userID string, patch models.EditBidRequest) (models.Bid, error) {
+    if err := patch.Validate(); err != nil {
+        return models.Bid{}, fmt.Errorf("cannot edit row: %w", err)
+    }
+
+    set := newSetClause(bidID)
+    if patch.Name.Set {
+        set.add("name", patch.Name.Value)
+    }
+    if patch.Description.Set {
+        set.add("description", patch.Description.Value)
+    }
+    set.add("updated_by", userID)
+
+    query := fmt.Sprintf("UPDATE bids SET %s, version=version+1 WHERE id=$1 RETURNING id, name, description, status, tender_id, author_type, author_id, version, created_at;", set)
 
-    row := s.Pool.QueryRow(ctx, query, bidID)
     var b models.Bid
-    err := row.Scan(
-        &b.ID, &b.Name, &b.Status, &b.AuthorType,
-        &b.AuthorID, &b.Version, &b.CreatedAt,
-    )
-    
+    err := s.inTx(ctx, func(tx pgx.Tx) error {
+        if err := lockEditableBid(ctx, tx, bidID); err != nil {
+            return err
+        }
+
+        row := tx.QueryRow(ctx, query, set.args...)
+        err := row.Scan(
+            &b.ID, &b.Name, &b.Description, &b.Status, &b.TenderID, &b.AuthorType,
+            &b.AuthorID, &b.Version, &b.CreatedAt,
+        )
+        if err != nil {
+            return fmt.Errorf("cannot edit row: %w", err)
+        }
+
+        return nil
+    })
     if err != nil {
-        return models.Bid{}, fmt.Errorf("cannot edit row: %w", err)
+        return models.Bid{}, err
     }
 
-    
     return b, nil
 }
 
-func (s *Storage) BidDecision(ctx context.Context, bidID, decision string) (models.Bid, error) {
-    var query string
-    if decision == "Approved" {
-        query = `
-            UPDATE submissions s
-            SET accept_rate = accept_rate + 1
-            FROM bids b
-            WHERE s.bid_id = b.id AND b.id = $1
-            RETURNING b.id, b.name, b.status, b.author_type, b.author_id, b.version, b.created_at;
-        `     
+// BidDecision saves decision of userID on bid in one transaction.
+// Single rejection rejects the bid, approval is reached when number of
+// approvals reaches quorum of responsibles whose role allows to decide
+// and then the tender is closed. Tender row is
+// locked, so decisions on bids of the same tender are serialized
+func (s *Storage) BidDecision(ctx context.Context, bidID, userID, decision string) (models.Bid, error) {
+    var b models.Bid
+    err := s.inTx(ctx, func(tx pgx.Tx) error {
+        var err error
+        b, err = bidDecision(ctx, tx, bidID, userID, decision)
+        return err
+    })
+    if err != nil {
+        return models.Bid{}, err
+    }
+
+    return b, nil
+}
+
+func bidDecision(ctx context.Context, tx pgx.Tx, bidID, userID, decision string) (models.Bid, error) {
+    query := `
+        SELECT b.tender_id, t.status, b.status
+        FROM bids b
+        JOIN tenders t ON t.id = b.tender_id
+        WHERE b.id = $1
+        FOR UPDATE OF t, b;
+    `
+
+    var tenderID, tenderStatus, bidStatus string
+    err := tx.QueryRow(ctx, query, bidID).Scan(&tenderID, &tenderStatus, &bidStatus)
+    if err != nil {
+        if err == pgx.ErrNoRows {
+            return models.Bid{}, storage.ErrNotFound
+        }
+
+        return models.Bid{}, fmt.Errorf("cannot get bid: %w", err)
+    }
+    if !domain.TenderAcceptsBids(tenderStatus) || !domain.BidAcceptsDecisions(bidStatus) {
+        return models.Bid{}, storage.ErrDecisionClosed
+    }
+
+    query = `
+        INSERT INTO bid_decisions (bid_id, user_id, decision)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (bid_id, user_id)
+        DO UPDATE SET decision = EXCLUDED.decision, created_at = CURRENT_TIMESTAMP;
+    `
+    _, err = tx.Exec(ctx, query, bidID, userID, decision)
+    if err != nil {
+        return models.Bid{}, fmt.Errorf("cannot save decision: %w", err)
+    }
+
+    if decision == models.DecisionRejected {
+        _, err = tx.Exec(ctx, "UPDATE bids SET status = $2 WHERE id = $1", bidID, domain.BidRejected)
+        if err != nil {
+            return models.Bid{}, fmt.Errorf("cannot reject bid: %w", err)
+        }
     } else {
         query = `
-            UPDATE submissions s
-            SET rejected = true
-            FROM bids b
-            WHERE s.bid_id = b.id AND s.bid_id = $1
-            RETURNING b.id, b.name, b.status, b.author_type, b.author_id, b.version, b.created_at;
+            SELECT
+                (SELECT COUNT(*) FROM bid_decisions
+                    WHERE bid_id = $1 AND decision = 'Approved'),
+                (SELECT COUNT(*) FROM organization_responsible r
+                    JOIN tenders t ON t.organization_id = r.organization_id
+                    WHERE t.id = $2 AND r.role = ANY($3));
         `
+
+        var approvals, responsibles int
+        deciders := domain.RolesAllowedTo(domain.ActionDecideBid)
+        err = tx.QueryRow(ctx, query, bidID, tenderID, deciders).Scan(&approvals, &responsibles)
+        if err != nil {
+            return models.Bid{}, fmt.Errorf("cannot count approvals: %w", err)
+        }
+
+        if approvals >= models.DecisionQuorum(responsibles) {
+            _, err = tx.Exec(ctx, "UPDATE bids SET status = $2 WHERE id = $1", bidID, domain.BidApproved)
+            if err != nil {
+                return models.Bid{}, fmt.Errorf("cannot approve bid: %w", err)
+            }
+
+            _, err = tx.Exec(ctx, "UPDATE tenders SET status = $2 WHERE id = $1", tenderID, domain.TenderClosed)
+            if err != nil {
+                return models.Bid{}, fmt.Errorf("cannot close tender: %w", err)
+            }
+        }
     }
 
-    row := s.Pool.QueryRow(ctx, query, bidID)
+    query = `
+        SELECT id, name, description, status, tender_id, author_type, author_id, version, created_at
+        FROM bids
+        WHERE id = $1;
+    `
+
     var b models.Bid
-    err := row.Scan(
-        &b.ID, &b.Name, &b.Status, &b.AuthorType,
+    err = tx.QueryRow(ctx, query, bidID).Scan(
+        &b.ID, &b.Name, &b.Description, &b.Status, &b.TenderID, &b.AuthorType,
         &b.AuthorID, &b.Version, &b.CreatedAt,
     )
-    
     if err != nil {
-        return models.Bid{}, fmt.Errorf("cannot edit row: %w", err)
+        return models.Bid{}, fmt.Errorf("cannot get bid: %w", err)
     }
 
     return b, nil
@@ -492,67 +681,131 @@ func (s *Storage) SendFeedback(ctx context.Context, bidID, userID, feedback stri
     return nil
 }
 
-func (s *Storage) RollbackBid(ctx context.Context, bidID string, version int) error {
-    conn, err := s.Pool.Acquire(ctx)
-    if err != nil {
-        
-        return err
-    }
-    defer conn.Release()
+// RollbackBid copies editable fields of the requested version forward
+// as a new version, history is kept untouched. Status is not versioned and
+// stays as is. Returns storage.ErrNotFound if bid or version does not exist
+// and domain.ErrFinalStatus if bid is in final status
+func (s *Storage) RollbackBid(ctx context.Context, bidID, userID string, version int) (models.Bid, error) {
+    query := `
+        UPDATE bids b
+        SET name = bh.name,
+            description = bh.description,
+            version = b.version + 1,
+            updated_by = $3
+        FROM bids_history bh
+        WHERE b.id = $1 AND bh.bid_id = b.id AND bh.version = $2
+        RETURNING b.id, b.name, b.description, b.status, b.tender_id, b.author_type, b.author_id, b.version, b.created_at;
+    `
+
+    var b models.Bid
+    err := s.inTx(ctx, func(tx pgx.Tx) error {
+        if err := lockEditableBid(ctx, tx, bidID); err != nil {
+            return err
+        }
 
-    transaction, err := conn.BeginTx(ctx, pgx.TxOptions{})
+        row := tx.QueryRow(ctx, query, bidID, version, userID)
+        err := row.Scan(
+            &b.ID, &b.Name, &b.Description, &b.Status, &b.TenderID, &b.AuthorType,
+            &b.AuthorID, &b.Version, &b.CreatedAt,
+        )
+        if err != nil {
+            if err == pgx.ErrNoRows {
+                return storage.ErrNotFound
+            }
+
+            return fmt.Errorf("cannot rollback bid: %w", err)
+        }
+
+        return nil
+    })
     if err != nil {
-        return err
+        return models.Bid{}, err
     }
 
-    _, err = transaction.Exec(ctx, "ALTER TABLE bids DISABLE TRIGGER bid_version_trigger")
+    return b, nil
+}
+
+// lockEditableBid locks bid row and returns storage.ErrNotFound if there
+// is no such bid or domain.ErrFinalStatus if bid cannot be changed
+func lockEditableBid(ctx context.Context, tx pgx.Tx, bidID string) error {
+    var status string
+    err := tx.QueryRow(ctx, "SELECT status FROM bids WHERE id=$1 FOR UPDATE", bidID).Scan(&status)
     if err != nil {
-        return transaction.Rollback(ctx)
+        if err == pgx.ErrNoRows {
+            return storage.ErrNotFound
+        }
+
+        return fmt.Errorf("cannot get bid status: %w", err)
+    }
+    if domain.BidFinal(status) {
+        return domain.ErrFinalStatus
     }
 
-    updateQuery := `
-        UPDATE bids
-        SET tender_id = bh.tender_id,
-            organization_id = bh.organization_id,
-            name = bh.name,
-            description = bh.description,
-            status = bh.status,
-            author_type = bh.author_type,
-            author_id = bh.author_id,
-            version = $1,
-            created_at = bh.created_at
+    return nil
+}
+
+// GetBidVersions returns page of bid versions starting from the newest
+func (s *Storage) GetBidVersions(ctx context.Context, bidID string, limit, offset int) ([]models.BidVersion, error) {
+    query := `
+        SELECT bh.version, bh.name, bh.description, bh.status,
+            COALESCE(e.username, ''), bh.changed_at
         FROM bids_history bh
-        WHERE bh.bid_id = $2 AND bh.version = $1 AND bids.id = bh.bid_id
+        LEFT JOIN employee e ON e.id = bh.changed_by
+        WHERE bh.bid_id = $1
+        ORDER BY bh.version DESC
+        LIMIT $2 OFFSET $3;
     `
 
-    _, err = transaction.Exec(ctx, updateQuery, version, bidID)
+    rows, err := s.Pool.Query(ctx, query, bidID, limit, offset)
     if err != nil {
-        
-        return transaction.Rollback(ctx)
+        return nil, fmt.Errorf("cannot get bid versions: %w", err)
     }
+    defer rows.Close()
 
-    deleteQuery := `
-        DELETE FROM bids_history
-        WHERE bid_id = $1 AND version >= $2;
-    `
-
-    _, err = transaction.Exec(ctx, deleteQuery, bidID, version)
-    if err != nil {
-        
-        return transaction.Rollback(ctx)
+    versions := make([]models.BidVersion, 0, limit)
+    for rows.Next() {
+        var v models.BidVersion
+        err := rows.Scan(
+            &v.Version, &v.Name, &v.Description,
+            &v.Status, &v.ChangedBy, &v.ChangedAt,
+        )
+        if err != nil {
+            return nil, fmt.Errorf("cannot scan row: %w", err)
+        }
+        versions = append(versions, v)
     }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("error while reading rows: %w", err)
+    }
+
+    return versions, nil
+}
+
+// GetBidVersion returns single version of bid
+// or storage.ErrNotFound if it does not exist
+func (s *Storage) GetBidVersion(ctx context.Context, bidID string, version int) (models.BidVersion, error) {
+    query := `
+        SELECT bh.version, bh.name, bh.description, bh.status,
+            COALESCE(e.username, ''), bh.changed_at
+        FROM bids_history bh
+        LEFT JOIN employee e ON e.id = bh.changed_by
+        WHERE bh.bid_id = $1 AND bh.version = $2;
+    `
 
-    _, err = transaction.Exec(ctx, "ALTER TABLE bids ENABLE TRIGGER bid_version_trigger")
+    var v models.BidVersion
+    err := s.Pool.QueryRow(ctx, query, bidID, version).Scan(
+        &v.Version, &v.Name, &v.Description,
+        &v.Status, &v.ChangedBy, &v.ChangedAt,
+    )
     if err != nil {
-        return transaction.Rollback(ctx)
-    }
+        if err == pgx.ErrNoRows {
+            return models.BidVersion{}, storage.ErrNotFound
+        }
 
-    if err := transaction.Commit(ctx); err != nil {
-        
-        return err
+        return models.BidVersion{}, fmt.Errorf("cannot get bid version: %w", err)
     }
 
-    return nil
+    return v, nil
 }
 
 // FIXME не работаю
@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
+	"zadanie-6105/internal/storage/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetRole returns role of user in organization
+// or empty string if he is not responsible for it
+func (s *Storage) GetRole(ctx context.Context, organizationID, userID string) (string, error) {
+	query := `
+		SELECT role FROM public.organization_responsible
+		WHERE organization_id = $1 AND user_id = $2;
+	`
+
+	var role string
+	err := s.Pool.QueryRow(ctx, query, organizationID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("cannot get role: %w", err)
+	}
+
+	return role, nil
+}
+
+// GetMembers returns responsibles of organization ordered by username
+func (s *Storage) GetMembers(ctx context.Context, organizationID string) ([]models.Member, error) {
+	query := `
+		SELECT r.user_id, e.username, r.role
+		FROM public.organization_responsible r
+		JOIN public.employee e ON e.id = r.user_id
+		WHERE r.organization_id = $1
+		ORDER BY e.username;
+	`
+
+	rows, err := s.Pool.Query(ctx, query, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get members: %w", err)
+	}
+	defer rows.Close()
+
+	members := make([]models.Member, 0)
+	for rows.Next() {
+		var m models.Member
+		if err := rows.Scan(&m.UserID, &m.Username, &m.Role); err != nil {
+			return nil, fmt.Errorf("cannot scan member: %w", err)
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot get members: %w", err)
+	}
+
+	return members, nil
+}
+
+// SetRole grants role to user, returns domain.ErrLastOwner
+// if the only owner of organization would be demoted
+func (s *Storage) SetRole(ctx context.Context, organizationID, userID, role string) (models.Member, error) {
+	var member models.Member
+	err := s.inTx(ctx, func(tx pgx.Tx) error {
+		if role != domain.RoleOwner {
+			if err := checkLastOwner(ctx, tx, organizationID, userID); err != nil {
+				return err
+			}
+		}
+
+		query := `
+			WITH r AS (
+				INSERT INTO public.organization_responsible (organization_id, user_id, role)
+				VALUES ($1, $2, $3)
+				ON CONFLICT (organization_id, user_id) DO UPDATE SET role = EXCLUDED.role
+				RETURNING user_id, role
+			)
+			SELECT r.user_id, e.username, r.role
+			FROM r JOIN public.employee e ON e.id = r.user_id;
+		`
+		return tx.QueryRow(ctx, query, organizationID, userID, role).Scan(&member.UserID, &member.Username, &member.Role)
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrLastOwner) {
+			return models.Member{}, err
+		}
+
+		return models.Member{}, fmt.Errorf("cannot set role: %w", err)
+	}
+
+	return member, nil
+}
+
+// RevokeRole removes user from responsibles of organization. Returns
+// storage.ErrNotFound if he is not responsible for it and
+// domain.ErrLastOwner if he is the only owner
+func (s *Storage) RevokeRole(ctx context.Context, organizationID, userID string) (models.Member, error) {
+	var member models.Member
+	err := s.inTx(ctx, func(tx pgx.Tx) error {
+		if err := checkLastOwner(ctx, tx, organizationID, userID); err != nil {
+			return err
+		}
+
+		query := `
+			WITH r AS (
+				DELETE FROM public.organization_responsible
+				WHERE organization_id = $1 AND user_id = $2
+				RETURNING user_id, role
+			)
+			SELECT r.user_id, e.username, r.role
+			FROM r JOIN public.employee e ON e.id = r.user_id;
+		`
+		err := tx.QueryRow(ctx, query, organizationID, userID).Scan(&member.UserID, &member.Username, &member.Role)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.ErrNotFound
+		}
+
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrLastOwner) || errors.Is(err, storage.ErrNotFound) {
+			return models.Member{}, err
+		}
+
+		return models.Member{}, fmt.Errorf("cannot revoke role: %w", err)
+	}
+
+	return member, nil
+}
+
+// checkLastOwner returns domain.ErrLastOwner if user is the only owner
+// of organization. Owners are locked until the end of transaction,
+// so two owners cannot demote each other concurrently
+func checkLastOwner(ctx context.Context, tx pgx.Tx, organizationID, userID string) error {
+	query := `
+		SELECT user_id FROM public.organization_responsible
+		WHERE organization_id = $1 AND role = $2
+		FOR UPDATE;
+	`
+
+	rows, err := tx.Query(ctx, query, organizationID, domain.RoleOwner)
+	if err != nil {
+		return err
+	}
+	owners, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+
+	if len(owners) == 1 && owners[0] == userID {
+		return domain.ErrLastOwner
+	}
+
+	return nil
+}
+
+// GetMemberships returns organizations user is responsible for
+// and his roles in them ordered by organization name
+func (s *Storage) GetMemberships(ctx context.Context, userID string) ([]models.Membership, error) {
+	query := `
+		SELECT o.id, o.name, COALESCE(o.type::text, ''), r.role
+		FROM public.organization_responsible r
+		JOIN public.organization o ON o.id = r.organization_id
+		WHERE r.user_id = $1
+		ORDER BY o.name;
+	`
+
+	rows, err := s.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get memberships: %w", err)
+	}
+	defer rows.Close()
+
+	memberships := make([]models.Membership, 0)
+	for rows.Next() {
+		var m models.Membership
+		if err := rows.Scan(&m.OrganizationID, &m.Name, &m.Type, &m.Role); err != nil {
+			return nil, fmt.Errorf("cannot scan membership: %w", err)
+		}
+		memberships = append(memberships, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot get memberships: %w", err)
+	}
+
+	return memberships, nil
+}
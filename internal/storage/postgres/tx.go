@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// maxTxAttempts limits how many times transaction is repeated
+// after serialization failure or deadlock
+const maxTxAttempts = 5
+
+// retryable reports whether transaction failed because of concurrent
+// transaction and can be safely repeated from the beginning
+func retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	// serialization_failure and deadlock_detected
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// inTx runs fn in transaction and commits it. Transaction is repeated
+// when it is aborted by serialization failure or deadlock
+func (s *Storage) inTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	var err error
+	for attempt := 0; attempt < maxTxAttempts; attempt++ {
+		err = pgx.BeginFunc(ctx, s.Pool, fn)
+		if !retryable(err) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("transaction failed after %d attempts: %w", maxTxAttempts, err)
+}
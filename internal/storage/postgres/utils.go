@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"zadanie-6105/internal/config"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/storage"
 	"zadanie-6105/internal/storage/models"
 
 	"github.com/jackc/pgx/v5"
@@ -17,45 +20,117 @@ type Storage struct {
 	Pool *pgxpool.Pool
 }
 
+var _ storage.Storage = (*Storage)(nil)
+
 // New creates new pool of connections to database if POSTGRES_CONN or
-// username, password, host, port and dbname env variables were provided
+// username, password, host, port and dbname env variables were provided.
+// Pending migrations are applied if MIGRATE_ON_START is set
 func New(ctx context.Context, cfg config.Config) (*Storage, error) {
-	if cfg.POSTGRES_CONN != "" {
-		pool, err := pgxpool.New(ctx, cfg.POSTGRES_CONN)
-		if err != nil {
-			return nil, err
-		}
+	connString := cfg.POSTGRES_CONN
+	if connString == "" {
+		connString = fmt.Sprintf("postgres://%s:%s@%s:%s/%s",
+	cfg.POSTGRES_USERNAME, cfg.POSTGRES_PASSWORD, cfg.POSTGRES_HOST, cfg.POSTGRES_PORT, cfg.POSTGRES_DATABASE)
+	}
 
-		err = pool.Ping(ctx)
-		if err != nil {
-			return nil, err
-		}
+	pool, err := pgxpool.New(ctx, connString)
+	if err != nil {
+		return nil, err
+	}
 
-		return &Storage{Pool: pool}, nil
-	} else {
-		connString := fmt.Sprintf("postgres://%s:%s@%s:%s/%s",
-	cfg.POSTGRES_USERNAME, cfg.POSTGRES_PASSWORD, cfg.POSTGRES_HOST, cfg.POSTGRES_PORT, cfg.POSTGRES_DATABASE)
-		pool, err := pgxpool.New(ctx, connString)
-		if err != nil {
-			return nil, err
-		}
+	err = pool.Ping(ctx)
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
 
-		err = pool.Ping(ctx)
+	storage := &Storage{Pool: pool}
+	if cfg.MIGRATE_ON_START {
+		err = storage.MigrateUp(ctx)
 		if err != nil {
-			return nil, err
+			pool.Close()
+			return nil, fmt.Errorf("cannot migrate database: %w", err)
 		}
-		return &Storage{Pool: pool}, nil
 	}
 
+	return storage, nil
+}
+
+// tenderVisibleTo returns condition on tenders aliased as t which keeps
+// public tenders and tenders of organizations where user passed as
+// parameter $n is responsible. Empty user sees only public tenders
+func tenderVisibleTo(n int) string {
+	return fmt.Sprintf(`(t.status = '%s' OR t.organization_id IN (
+		SELECT organization_id FROM organization_responsible
+		WHERE user_id = NULLIF($%d, '')::uuid))`, domain.TenderPublished, n)
+}
+
+// bidOwnedBy returns condition on bids aliased as b which keeps bids
+// made by user passed as parameter $n or by his organization
+func bidOwnedBy(n int) string {
+	return fmt.Sprintf(`(b.author_id = NULLIF($%[1]d, '')::uuid
+		OR b.organization_id IN (
+			SELECT organization_id FROM organization_responsible
+			WHERE user_id = NULLIF($%[1]d, '')::uuid))`, n)
+}
+
+// bidVisibleTo returns condition on bids aliased as b which follows
+// domain.BidVisible for user passed as parameter $n
+func bidVisibleTo(n int) string {
+	statuses := make([]string, len(domain.BidTenderOwnerStatuses))
+	for i, status := range domain.BidTenderOwnerStatuses {
+		statuses[i] = "'" + status + "'"
+	}
+
+	return fmt.Sprintf(`(%[1]s
+		OR (b.status IN (%[2]s) AND b.tender_id IN (
+			SELECT t.id FROM tenders t
+			JOIN organization_responsible r ON r.organization_id = t.organization_id
+			WHERE r.user_id = NULLIF($%[3]d, '')::uuid)))`, bidOwnedBy(n), strings.Join(statuses, ", "), n)
+}
+
+// BidVisible reports whether userID can see the bid
+func (s *Storage) BidVisible(ctx context.Context, bidID, userID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM bids b
+			WHERE b.id = $1 AND ` + bidVisibleTo(2) + `
+		);
+	`
+
+	var visible bool
+	err := s.Pool.QueryRow(ctx, query, bidID, userID).Scan(&visible)
+	if err != nil {
+		return false, fmt.Errorf("cannot check bid visibility: %w", err)
+	}
+
+	return visible, nil
+}
+
+// TenderVisible reports whether userID can see the tender
+func (s *Storage) TenderVisible(ctx context.Context, tenderID, userID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM tenders t
+			WHERE t.id = $1 AND ` + tenderVisibleTo(2) + `
+		);
+	`
+
+	var visible bool
+	err := s.Pool.QueryRow(ctx, query, tenderID, userID).Scan(&visible)
+	if err != nil {
+		return false, fmt.Errorf("cannot check tender visibility: %w", err)
+	}
+
+	return visible, nil
 }
 
 // GetUserID return string with userID from table employee or error
-// if error occurs
+// if error occurs. Deactivated employees are not found
 func (s *Storage) GetUserID(ctx context.Context, username string) (string, error) {
 	var userID string
 	query := `
 		SELECT id FROM public.employee
-		WHERE username=$1; 
+		WHERE username=$1 AND deactivated_at IS NULL;
 	`
 	row := s.Pool.QueryRow(ctx, query, username)
 	err := row.Scan(&userID)
@@ -69,27 +144,6 @@ func (s *Storage) GetUserID(ctx context.Context, username string) (string, error
 	return userID, nil
 }
 
-func (s *Storage) GetOrganizationID(ctx context.Context, userID string) (string, error) {
-	var organizationID string
-	query := `
-		SELECT organization_id
-		FROM public.organization_responsible
-		WHERE user_id = $1;
-	`
-
-	row := s.Pool.QueryRow(ctx, query, userID)
-	err := row.Scan(&organizationID)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return "", nil
-		}
-
-		return "", fmt.Errorf("cannot select id from organization_responsible: %w", err)
-	}
-
-	return organizationID, nil
-}
-
 func (s *Storage) GetOrganizationIDByTender(ctx context.Context, tenderID string) (string, error) {
 	query := `
 		SELECT organization_id
@@ -110,10 +164,11 @@ func (s *Storage) GetOrganizationIDByTender(ctx context.Context, tenderID string
 	return organizationID, nil
 }
 
+// GetUsername returns empty string for unknown or deactivated employee
 func (s *Storage) GetUsername(ctx context.Context, userID string) (string, error) {
 	query := `
 		SELECT username FROM public.employee
-		WHERE id=$1;
+		WHERE id=$1 AND deactivated_at IS NULL;
 	`
 
 	row := s.Pool.QueryRow(ctx, query, userID)
@@ -149,10 +204,11 @@ func (s *Storage) TenderExists(ctx context.Context, tenderID string) (bool, erro
 	return true, nil
 }
 
+// UserExists reports whether active employee with userID exists
 func (s *Storage) UserExists(ctx context.Context, userID string) (bool, error) {
 	query := `
 		SELECT id FROM public.employee
-		WHERE id=$1;
+		WHERE id=$1 AND deactivated_at IS NULL;
 	`
 
 	row := s.Pool.QueryRow(ctx, query, userID)
@@ -191,9 +247,31 @@ func (s *Storage) GetBidOrganizationID(ctx context.Context, bidID string) (strin
 	return organizationID, nil
 }
 
+// GetBidTenderID returns id of tender the bid was made for
+// or empty string if there is no such bid
+func (s *Storage) GetBidTenderID(ctx context.Context, bidID string) (string, error) {
+	query := `
+		SELECT tender_id
+		FROM bids
+		WHERE id=$1;
+	`
+
+	var tenderID string
+	err := s.Pool.QueryRow(ctx, query, bidID).Scan(&tenderID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("cannot get tender id of bid: %w", err)
+	}
+
+	return tenderID, nil
+}
+
 func (s *Storage) GetBidByID(ctx context.Context, bidID string) (models.Bid, error) {
 	query := `
-		SELECT id, name, status, author_type, author_id, version, created_at
+		SELECT id, name, description, status, tender_id, author_type, author_id, version, created_at
 		FROM bids
 		WHERE id=$1;
 	`
@@ -201,7 +279,7 @@ func (s *Storage) GetBidByID(ctx context.Context, bidID string) (models.Bid, err
 	row := s.Pool.QueryRow(ctx, query, bidID)
     var b models.Bid
     err := row.Scan(
-        &b.ID, &b.Name, &b.Status, &b.AuthorType,
+        &b.ID, &b.Name, &b.Description, &b.Status, &b.TenderID, &b.AuthorType,
         &b.AuthorID, &b.Version, &b.CreatedAt,
     )
     log.Println(err)
@@ -230,5 +308,26 @@ func (s *Storage) AuthorBidExist(ctx context.Context, authorID, tenderID string)
 		return false, err
 	}
 
-	return true, nil
+	return cnt > 0, nil
+}
+
+// setClause builds SET part of UPDATE query. Column names must come
+// from code, values are always passed as parameters. The first
+// parameter is reserved for id of the updated row
+type setClause struct {
+	parts []string
+	args  []any
+}
+
+func newSetClause(id string) *setClause {
+	return &setClause{args: []any{id}}
+}
+
+func (c *setClause) add(column string, value any) {
+	c.args = append(c.args, value)
+	c.parts = append(c.parts, fmt.Sprintf("%s=$%d", column, len(c.args)))
+}
+
+func (c *setClause) String() string {
+	return strings.Join(c.parts, ", ")
 }
\ No newline at end of file
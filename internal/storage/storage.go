@@ -1 +1,126 @@
 package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+	"zadanie-6105/internal/storage/models"
+)
+
+var (
+	// ErrNotFound is returned when requested entity or its version does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrDecisionClosed is returned when bid or its tender is not
+	// published, so decisions are not accepted
+	ErrDecisionClosed = errors.New("decision is already made")
+	// ErrAlreadyExists is returned when unique field like
+	// username is already taken
+	ErrAlreadyExists = errors.New("already exists")
+)
+
+// TenderRepository describes operations on tenders and their versions
+type TenderRepository interface {
+	GetTenderList(ctx context.Context, limit, offset int, serviceType, userID string) ([]models.Tender, error)
+	InsertTender(ctx context.Context, newTender *models.NewTenderRequest, creatorID string) (models.Tender, error)
+	GetMyTendersList(ctx context.Context, limit, offset int, userID, organizationID string) ([]models.Tender, error)
+	GetTenderStatus(ctx context.Context, tenderID string) (string, error)
+	ChangeTenderStatus(ctx context.Context, tenderID, status string) (models.Tender, error)
+	EditTender(ctx context.Context, tenderID, userID string, patch models.EditTenderRequest) (models.Tender, error)
+	RollbackTender(ctx context.Context, tenderID, userID string, version int) (models.Tender, error)
+	GetTenderVersions(ctx context.Context, tenderID string, limit, offset int) ([]models.TenderVersion, error)
+	GetTenderVersion(ctx context.Context, tenderID string, version int) (models.TenderVersion, error)
+	TenderExists(ctx context.Context, tenderID string) (bool, error)
+	TenderVisible(ctx context.Context, tenderID, userID string) (bool, error)
+	GetOrganizationIDByTender(ctx context.Context, tenderID string) (string, error)
+}
+
+// BidRepository describes operations on bids, their versions and decisions
+type BidRepository interface {
+	InsertBid(ctx context.Context, bid models.BidRequest, organizationID string) (models.Bid, error)
+	GetMyBidsList(ctx context.Context, limit, offset int, userID, organizationID string) ([]models.Bid, error)
+	GetTenderBids(ctx context.Context, limit, offset int, tenderID, userID string) ([]models.Bid, error)
+	BidVisible(ctx context.Context, bidID, userID string) (bool, error)
+	GetBidStatus(ctx context.Context, bidID string) (string, error)
+	ChangeBitStatus(ctx context.Context, bidID, status string) (models.Bid, error)
+	EditBid(ctx context.Context, bidID, userID string, patch models.EditBidRequest) (models.Bid, error)
+	BidDecision(ctx context.Context, bidID, userID, decision string) (models.Bid, error)
+	RollbackBid(ctx context.Context, bidID, userID string, version int) (models.Bid, error)
+	GetBidVersions(ctx context.Context, bidID string, limit, offset int) ([]models.BidVersion, error)
+	GetBidVersion(ctx context.Context, bidID string, version int) (models.BidVersion, error)
+	GetBidTenderID(ctx context.Context, bidID string) (string, error)
+	GetBidOrganizationID(ctx context.Context, bidID string) (string, error)
+	GetBidByID(ctx context.Context, bidID string) (models.Bid, error)
+	AuthorBidExist(ctx context.Context, authorID, tenderID string) (bool, error)
+}
+
+// FeedbackRepository describes operations on feedback left for bids
+type FeedbackRepository interface {
+	SendFeedback(ctx context.Context, bidID, userID, feedback string) error
+	GetFeedback(ctx context.Context, authorUserID string, limit, offset int) ([]models.Feedback, error)
+}
+
+// EmployeeRepository describes the directory of employees. Lookups by
+// username or id see only active employees, deactivated ones are listed
+// by GetEmployees and GetEmployee only
+type EmployeeRepository interface {
+	GetUserID(ctx context.Context, username string) (string, error)
+	GetUsername(ctx context.Context, userID string) (string, error)
+	UserExists(ctx context.Context, userID string) (bool, error)
+	InsertEmployee(ctx context.Context, newEmployee models.NewEmployeeRequest, passwordHash string) (models.Employee, error)
+	GetEmployees(ctx context.Context, limit, offset int) ([]models.Employee, error)
+	GetEmployee(ctx context.Context, username string) (models.Employee, error)
+	EditEmployee(ctx context.Context, userID string, patch models.EditEmployeeRequest) (models.Employee, error)
+	DeactivateEmployee(ctx context.Context, userID string) (models.Employee, error)
+}
+
+// CredentialRepository describes passwords of employees, login
+// lockout and password reset tokens. Only hashes are stored
+type CredentialRepository interface {
+	GetCredentials(ctx context.Context, userID string) (models.Credentials, error)
+	SetPassword(ctx context.Context, userID, passwordHash string) error
+	RecordLoginFailure(ctx context.Context, userID string, maxAttempts int, lockout time.Duration) error
+	ResetLoginFailures(ctx context.Context, userID string) error
+	CreatePasswordReset(ctx context.Context, userID, tokenHash string, ttl time.Duration) error
+	ResetPassword(ctx context.Context, tokenHash, passwordHash string) (string, error)
+}
+
+// APIKeyRepository describes API keys of organizations. Only hashes
+// of keys are stored, revoked keys are kept for audit
+type APIKeyRepository interface {
+	InsertAPIKey(ctx context.Context, organizationID, userID string, newKey models.NewAPIKeyRequest, keyHash, prefix string) (models.APIKey, error)
+	GetAPIKeys(ctx context.Context, organizationID string, limit, offset int) ([]models.APIKey, error)
+	RotateAPIKey(ctx context.Context, keyID, organizationID, userID, keyHash, prefix string) (models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, keyID, organizationID string) (models.APIKey, error)
+	GetAPIKeyOwner(ctx context.Context, keyHash string) (models.APIKeyOwner, error)
+}
+
+// RoleRepository describes roles of employees responsible
+// for organizations, see domain.RoleAllows
+type RoleRepository interface {
+	GetRole(ctx context.Context, organizationID, userID string) (string, error)
+	GetMembers(ctx context.Context, organizationID string) ([]models.Member, error)
+	SetRole(ctx context.Context, organizationID, userID, role string) (models.Member, error)
+	RevokeRole(ctx context.Context, organizationID, userID string) (models.Member, error)
+	GetMemberships(ctx context.Context, userID string) ([]models.Membership, error)
+}
+
+// OrganizationRepository describes operations on organizations. Deletion
+// cascades to tenders, bids and responsibles of organization
+type OrganizationRepository interface {
+	InsertOrganization(ctx context.Context, newOrganization models.NewOrganizationRequest, ownerID string) (models.Organization, error)
+	GetOrganization(ctx context.Context, organizationID string) (models.Organization, error)
+	EditOrganization(ctx context.Context, organizationID string, patch models.EditOrganizationRequest) (models.Organization, error)
+	DeleteOrganization(ctx context.Context, organizationID string) (models.Organization, error)
+}
+
+// Storage unites all repositories used by handlers
+type Storage interface {
+	TenderRepository
+	BidRepository
+	FeedbackRepository
+	EmployeeRepository
+	CredentialRepository
+	APIKeyRepository
+	RoleRepository
+	OrganizationRepository
+}
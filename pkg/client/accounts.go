@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+)
+
+// Ping checks that server is ready to accept requests
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, get("ping"), nil)
+}
+
+// Spec returns OpenAPI specification of the API in YAML
+func (c *Client) Spec(ctx context.Context) ([]byte, error) {
+	var spec []byte
+	err := c.do(ctx, get("openapi.yaml"), &spec)
+
+	return spec, err
+}
+
+// Login exchanges username and password for token
+// to be used with BearerToken
+func (c *Client) Login(ctx context.Context, login LoginRequest) (TokenResponse, error) {
+	var token TokenResponse
+	err := c.do(ctx, call{method: http.MethodPost, path: []string{"auth", "login"}, body: login}, &token)
+
+	return token, err
+}
+
+// ChangePassword sets password of the authenticated employee,
+// current password is required if one is already set
+func (c *Client) ChangePassword(ctx context.Context, change ChangePasswordRequest) error {
+	return c.do(ctx, call{method: http.MethodPut, path: []string{"auth", "password"}, body: change}, nil)
+}
+
+// ResetPassword asks server to send password reset token to employee,
+// it succeeds whether employee exists or not
+func (c *Client) ResetPassword(ctx context.Context, username string) error {
+	body := map[string]string{"username": username}
+	return c.do(ctx, call{method: http.MethodPost, path: []string{"auth", "password", "reset"}, body: body}, nil)
+}
+
+// ConfirmPasswordReset sets new password with token sent by ResetPassword
+func (c *Client) ConfirmPasswordReset(ctx context.Context, confirm PasswordResetConfirmRequest) error {
+	return c.do(ctx, call{method: http.MethodPost, path: []string{"auth", "password", "reset", "confirm"}, body: confirm}, nil)
+}
+
+// Employees returns page of employees ordered by username
+func (c *Client) Employees(ctx context.Context, page Page) ([]Employee, error) {
+	var employees []Employee
+	err := c.do(ctx, get("employees").with(page.query()), &employees)
+
+	return employees, err
+}
+
+// AllEmployees iterates over all employees ordered by username
+func (c *Client) AllEmployees(ctx context.Context) iter.Seq2[Employee, error] {
+	return all(ctx, c.Employees)
+}
+
+// CreateEmployee creates employee, only administrators may call it
+func (c *Client) CreateEmployee(ctx context.Context, employee NewEmployeeRequest) (Employee, error) {
+	var created Employee
+	err := c.do(ctx, call{method: http.MethodPost, path: []string{"employees", "new"}, body: employee}, &created)
+
+	return created, err
+}
+
+// Employee returns employee with organizations he is responsible for
+func (c *Client) Employee(ctx context.Context, username string) (EmployeeProfile, error) {
+	var profile EmployeeProfile
+	err := c.do(ctx, get("employees", username), &profile)
+
+	return profile, err
+}
+
+// EditEmployee changes names of employee, fields of edit
+// are built with Set and Null
+func (c *Client) EditEmployee(ctx context.Context, username string, edit EditEmployeeRequest) (Employee, error) {
+	var employee Employee
+	err := c.do(ctx, call{method: http.MethodPatch, path: []string{"employees", username, "edit"}, body: edit}, &employee)
+
+	return employee, err
+}
+
+// DeactivateEmployee deactivates employee, only administrators may call it
+func (c *Client) DeactivateEmployee(ctx context.Context, username string) (Employee, error) {
+	var employee Employee
+	err := c.do(ctx, call{method: http.MethodPut, path: []string{"employees", username, "deactivate"}}, &employee)
+
+	return employee, err
+}
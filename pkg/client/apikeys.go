@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+)
+
+// APIKeys returns page of keys of the acting organization, see ForOrganization
+func (c *Client) APIKeys(ctx context.Context, page Page) ([]APIKey, error) {
+	var keys []APIKey
+	err := c.do(ctx, get("api_keys").with(page.query()), &keys)
+
+	return keys, err
+}
+
+// AllAPIKeys iterates over keys of the acting organization
+func (c *Client) AllAPIKeys(ctx context.Context) iter.Seq2[APIKey, error] {
+	return all(ctx, c.APIKeys)
+}
+
+// CreateAPIKey creates key of the acting organization, the key
+// itself is returned only once
+func (c *Client) CreateAPIKey(ctx context.Context, key NewAPIKeyRequest) (APIKeyWithSecret, error) {
+	var created APIKeyWithSecret
+	err := c.do(ctx, call{method: http.MethodPost, path: []string{"api_keys", "new"}, body: key}, &created)
+
+	return created, err
+}
+
+// RotateAPIKey replaces key with a new one, the old key stops working
+func (c *Client) RotateAPIKey(ctx context.Context, keyID string) (APIKeyWithSecret, error) {
+	var rotated APIKeyWithSecret
+	err := c.do(ctx, call{method: http.MethodPut, path: []string{"api_keys", keyID, "rotate"}}, &rotated)
+
+	return rotated, err
+}
+
+func (c *Client) RevokeAPIKey(ctx context.Context, keyID string) (APIKey, error) {
+	var revoked APIKey
+	err := c.do(ctx, call{method: http.MethodPut, path: []string{"api_keys", keyID, "revoke"}}, &revoked)
+
+	return revoked, err
+}
@@ -0,0 +1,44 @@
+package client
+
+import "net/http"
+
+// Authenticator adds credentials to every request sent by Client
+type Authenticator interface {
+	Authenticate(r *http.Request) error
+}
+
+// AuthenticatorFunc turns function into Authenticator
+type AuthenticatorFunc func(r *http.Request) error
+
+func (f AuthenticatorFunc) Authenticate(r *http.Request) error {
+	return f(r)
+}
+
+// BearerToken authenticates with token issued by Login
+// or by OpenID Connect provider
+func BearerToken(token string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) error {
+		r.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// APIKeyAuth authenticates with key of organization, it is accepted
+// only by endpoints allowed by scopes of the key
+func APIKeyAuth(key string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) error {
+		r.Header.Set("Authorization", "ApiKey "+key)
+		return nil
+	})
+}
+
+// LegacyUsername sends username param which server trusts
+// only if it runs with AUTH_LEGACY_USERNAME
+func LegacyUsername(username string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) error {
+		query := r.URL.Query()
+		query.Set("username", username)
+		r.URL.RawQuery = query.Encode()
+		return nil
+	})
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreateBid creates bid on behalf of the acting organization
+// or of organization set in bid
+func (c *Client) CreateBid(ctx context.Context, bid BidRequest) (Bid, error) {
+	var created Bid
+	err := c.do(ctx, call{method: http.MethodPost, path: []string{"bids", "new"}, body: bid}, &created)
+
+	return created, err
+}
+
+// MyBids returns page of bids made by the authenticated employee
+// or his organizations, only of organization if it is not empty
+func (c *Client) MyBids(ctx context.Context, organizationID string, page Page) ([]Bid, error) {
+	query := page.query()
+	if organizationID != "" {
+		query.Set("organizationId", organizationID)
+	}
+
+	var bids []Bid
+	err := c.do(ctx, get("bids", "my").with(query), &bids)
+
+	return bids, err
+}
+
+// AllMyBids iterates over bids made by the authenticated employee
+func (c *Client) AllMyBids(ctx context.Context, organizationID string) iter.Seq2[Bid, error] {
+	return all(ctx, func(ctx context.Context, page Page) ([]Bid, error) {
+		return c.MyBids(ctx, organizationID, page)
+	})
+}
+
+// TenderBids returns page of bids on tender visible to the
+// authenticated employee. Server answers ErrNotFound if there are none
+func (c *Client) TenderBids(ctx context.Context, tenderID string, page Page) ([]Bid, error) {
+	var bids []Bid
+	err := c.do(ctx, get("bids", tenderID, "list").with(page.query()), &bids)
+
+	return bids, err
+}
+
+// AllTenderBids iterates over bids on tender visible
+// to the authenticated employee
+func (c *Client) AllTenderBids(ctx context.Context, tenderID string) iter.Seq2[Bid, error] {
+	return all(ctx, endsWithNotFound(func(ctx context.Context, page Page) ([]Bid, error) {
+		return c.TenderBids(ctx, tenderID, page)
+	}))
+}
+
+func (c *Client) BidStatus(ctx context.Context, bidID string) (string, error) {
+	var status string
+	err := c.do(ctx, get("bids", bidID, "status"), &status)
+
+	return status, err
+}
+
+// SetBidStatus moves bid to status set by its author,
+// setting the current status again changes nothing
+func (c *Client) SetBidStatus(ctx context.Context, bidID, status string) (Bid, error) {
+	var bid Bid
+	err := c.do(ctx, call{
+		method:     http.MethodPut,
+		path:       []string{"bids", bidID, "status"},
+		query:      url.Values{"status": {status}},
+		idempotent: true,
+	}, &bid)
+
+	return bid, err
+}
+
+// EditBid changes bid and creates its new version,
+// fields of edit are built with Set and Null
+func (c *Client) EditBid(ctx context.Context, bidID string, edit EditBidRequest) (Bid, error) {
+	var bid Bid
+	err := c.do(ctx, call{method: http.MethodPatch, path: []string{"bids", bidID, "edit"}, body: edit}, &bid)
+
+	return bid, err
+}
+
+// SubmitDecision approves or rejects bid on behalf of
+// the organization of tender
+func (c *Client) SubmitDecision(ctx context.Context, bidID, decision string) (Bid, error) {
+	var bid Bid
+	err := c.do(ctx, call{
+		method: http.MethodPut,
+		path:   []string{"bids", bidID, "submit_decision"},
+		query:  url.Values{"decision": {decision}},
+	}, &bid)
+
+	return bid, err
+}
+
+// SubmitFeedback leaves review on bid
+func (c *Client) SubmitFeedback(ctx context.Context, bidID, feedback string) (Bid, error) {
+	var bid Bid
+	err := c.do(ctx, call{
+		method: http.MethodPut,
+		path:   []string{"bids", bidID, "feedback"},
+		query:  url.Values{"bidFeedback": {feedback}},
+	}, &bid)
+
+	return bid, err
+}
+
+// RollbackBid creates new version of bid equal to version
+func (c *Client) RollbackBid(ctx context.Context, bidID string, version int) (Bid, error) {
+	var bid Bid
+	err := c.do(ctx, call{method: http.MethodPut, path: []string{"bids", bidID, "rollback", strconv.Itoa(version)}}, &bid)
+
+	return bid, err
+}
+
+// Reviews returns page of reviews on bids of author, it is available to
+// responsibles of tender organization. Server answers ErrNotFound if
+// there are none
+func (c *Client) Reviews(ctx context.Context, tenderID, authorUsername string, page Page) ([]Feedback, error) {
+	query := page.query()
+	query.Set("authorUsername", authorUsername)
+
+	var reviews []Feedback
+	err := c.do(ctx, get("bids", tenderID, "reviews").with(query), &reviews)
+
+	return reviews, err
+}
+
+// AllReviews iterates over reviews on bids of author
+func (c *Client) AllReviews(ctx context.Context, tenderID, authorUsername string) iter.Seq2[Feedback, error] {
+	return all(ctx, endsWithNotFound(func(ctx context.Context, page Page) ([]Feedback, error) {
+		return c.Reviews(ctx, tenderID, authorUsername, page)
+	}))
+}
+
+// BidVersions returns page of bid history, latest version first
+func (c *Client) BidVersions(ctx context.Context, bidID string, page Page) ([]BidVersion, error) {
+	var versions []BidVersion
+	err := c.do(ctx, get("bids", bidID, "versions").with(page.query()), &versions)
+
+	return versions, err
+}
+
+// AllBidVersions iterates over bid history, latest version first
+func (c *Client) AllBidVersions(ctx context.Context, bidID string) iter.Seq2[BidVersion, error] {
+	return all(ctx, func(ctx context.Context, page Page) ([]BidVersion, error) {
+		return c.BidVersions(ctx, bidID, page)
+	})
+}
+
+// DiffBidVersions returns fields of bid which differ between versions
+func (c *Client) DiffBidVersions(ctx context.Context, bidID string, from, to int) (VersionDiff, error) {
+	var diff VersionDiff
+	err := c.do(ctx, get("bids", bidID, "versions", strconv.Itoa(from), "diff", strconv.Itoa(to)), &diff)
+
+	return diff, err
+}
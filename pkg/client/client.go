@@ -0,0 +1,246 @@
+// Package client is a typed Go client of the tender API described in
+// api/openapi.yml. It covers every endpoint served by the API except
+// Swagger UI at /docs/, which is meant for browsers.
+//
+//	c, err := client.New("http://localhost:8080/api", client.WithAuth(client.BearerToken(token)))
+//	for tender, err := range c.AllTenders(ctx, "Delivery") {
+//		...
+//	}
+//
+// Responses with status 4xx and 5xx are returned as *Error, which
+// matches ErrNotFound and other sentinels with errors.Is
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const organizationHeader = "X-Organization-Id"
+
+// Client calls the tender API. It is safe for concurrent use, methods
+// As and ForOrganization return copies which share the HTTP client
+type Client struct {
+	baseURL      string
+	httpClient   *http.Client
+	auth         Authenticator
+	organization string
+	retries      int
+	backoff      time.Duration
+}
+
+type Option func(*Client)
+
+// WithHTTPClient sets HTTP client used to send requests,
+// http.DefaultClient is used by default
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAuth sets credentials added to every request
+func WithAuth(auth Authenticator) Option {
+	return func(c *Client) {
+		c.auth = auth
+	}
+}
+
+// WithRetries sets how many times idempotent call is repeated after
+// transport error or 5xx response. Pause before n-th retry is backoff
+// multiplied by 2^(n-1). By default call is retried twice after 100ms
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// New returns client of the API served at baseURL,
+// for example http://localhost:8080/api
+func New(baseURL string, options ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse base url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("base url must be absolute http or https url: %q", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    strings.TrimSuffix(u.String(), "/"),
+		httpClient: http.DefaultClient,
+		retries:    2,
+		backoff:    100 * time.Millisecond,
+	}
+	for _, option := range options {
+		option(c)
+	}
+
+	return c, nil
+}
+
+// As returns copy of client which sends requests with other credentials
+func (c *Client) As(auth Authenticator) *Client {
+	copied := *c
+	copied.auth = auth
+
+	return &copied
+}
+
+// ForOrganization returns copy of client which acts on behalf of
+// organization. It is required for employees responsible for several
+// organizations when they work with bids and API keys
+func (c *Client) ForOrganization(organizationID string) *Client {
+	copied := *c
+	copied.organization = organizationID
+
+	return &copied
+}
+
+// call describes request to an endpoint. Path segments are escaped,
+// body is encoded as JSON, PATCH body without fields which are not
+// set. Only idempotent calls are retried
+type call struct {
+	method     string
+	path       []string
+	query      url.Values
+	body       any
+	idempotent bool
+}
+
+func get(path ...string) call {
+	return call{method: http.MethodGet, path: path, idempotent: true}
+}
+
+func (c call) with(query url.Values) call {
+	c.query = query
+	return c
+}
+
+// do sends call and decodes successful response into out if it is not nil
+func (c *Client) do(ctx context.Context, call call, out any) error {
+	var body []byte
+	if call.body != nil {
+		value := call.body
+		if call.method == http.MethodPatch {
+			fields, err := patch(value)
+			if err != nil {
+				return fmt.Errorf("cannot encode request: %w", err)
+			}
+			value = fields
+		}
+
+		var err error
+		body, err = json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("cannot encode request: %w", err)
+		}
+	}
+
+	attempts := 1
+	if call.idempotent {
+		attempts += c.retries
+	}
+
+	var resp *http.Response
+	for attempt := 1; ; attempt++ {
+		var err error
+		resp, err = c.send(ctx, call, body)
+		if attempt == attempts || !retryable(ctx, resp, err) {
+			if err != nil {
+				return err
+			}
+			break
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.backoff << (attempt - 1)):
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	if raw, ok := out.(*[]byte); ok {
+		data, err := io.ReadAll(resp.Body)
+		*raw = data
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("cannot decode response of %s %s: %w", call.method, strings.Join(call.path, "/"), err)
+	}
+
+	return nil
+}
+
+func (c *Client) send(ctx context.Context, call call, body []byte) (*http.Response, error) {
+	segments := make([]string, len(call.path))
+	for i, segment := range call.path {
+		segments[i] = url.PathEscape(segment)
+	}
+	u := c.baseURL + "/" + strings.Join(segments, "/")
+	if len(call.query) > 0 {
+		u += "?" + call.query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, call.method, u, reader)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.organization != "" {
+		req.Header.Set(organizationHeader, c.organization)
+	}
+	if c.auth != nil {
+		if err := c.auth.Authenticate(req); err != nil {
+			return nil, fmt.Errorf("cannot authenticate request: %w", err)
+		}
+	}
+
+	return c.httpClient.Do(req)
+}
+
+// retryable reports whether call may succeed if it is sent again,
+// cancellation of ctx is never retried
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,412 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"zadanie-6105/api"
+	"zadanie-6105/internal/auth"
+	"zadanie-6105/internal/config"
+	"zadanie-6105/internal/domain"
+	"zadanie-6105/internal/server"
+	authmw "zadanie-6105/internal/server/middleware/auth"
+	"zadanie-6105/internal/server/middleware/validation"
+	"zadanie-6105/internal/storage/memory"
+	"zadanie-6105/internal/storage/models"
+	"zadanie-6105/pkg/client"
+
+	"github.com/gorilla/mux"
+)
+
+type notifier struct{}
+
+func (notifier) NotifyPasswordReset(ctx context.Context, p auth.Principal, token string, expiresAt time.Time) error {
+	return nil
+}
+
+// newRouter returns router of the API over st built the same way as in main
+func newRouter(t *testing.T, st *memory.Storage) http.Handler {
+	t.Helper()
+
+	doc, err := api.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	validator, err := validation.New(log, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Config{
+		AUTH_LEGACY_USERNAME:    true,
+		AUTH_MAX_LOGIN_ATTEMPTS: 3,
+		AUTH_LOCKOUT_DURATION:   time.Minute,
+		AUTH_RESET_TOKEN_TTL:    time.Hour,
+	}
+	tokens := auth.NewTokens([]byte("secret"), time.Hour)
+
+	r := mux.NewRouter()
+	apiRouter := r.PathPrefix("/api").Subrouter()
+	apiRouter.Use(validator, authmw.New(log, tokens, nil, st, cfg.AUTH_LEGACY_USERNAME))
+	server.LoadRoutes(apiRouter, st, tokens, notifier{}, doc, cfg)
+
+	return r
+}
+
+func newClient(t *testing.T, handler http.Handler, options ...client.Option) *client.Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c, err := client.New(srv.URL+"/api", options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+// signIn sets password of employee with username
+// and returns token issued for it by Login
+func signIn(t *testing.T, c *client.Client, st *memory.Storage, username string) string {
+	t.Helper()
+
+	ctx := context.Background()
+	userID, err := st.GetUserID(ctx, username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := auth.HashPassword(username + " password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetPassword(ctx, userID, hash); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := c.Login(ctx, client.LoginRequest{Username: username, Password: username + " password"})
+	if err != nil {
+		t.Fatalf("login: %s", err)
+	}
+
+	return token.Token
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+	ownerID := st.AddEmployee("owner", "", "")
+	bidderID := st.AddEmployee("bidder", "", "")
+	organizationID := st.AddOrganization("acme", "", "LLC")
+	st.AddResponsible(organizationID, ownerID)
+	st.AddResponsible(st.AddOrganization("bidco", "", "IE"), bidderID)
+
+	c := newClient(t, newRouter(t, st))
+	if err := c.Ping(ctx); err != nil {
+		t.Fatalf("ping: %s", err)
+	}
+
+	owner := c.As(client.BearerToken(signIn(t, c, st, "owner")))
+	bidder := c.As(client.LegacyUsername("bidder"))
+
+	tender, err := owner.CreateTender(ctx, client.NewTenderRequest{
+		Name:           "Доставка",
+		Description:    "Казань - Москва",
+		ServiceType:    "Delivery",
+		OrganizationID: organizationID,
+	})
+	if err != nil {
+		t.Fatalf("create tender: %s", err)
+	}
+	if tender.OrganizationID != organizationID || tender.Version != 1 {
+		t.Fatalf("unexpected tender %+v", tender)
+	}
+
+	tender, err = owner.EditTender(ctx, tender.ID, client.EditTenderRequest{
+		Name:        client.Set("Доставка оборудования"),
+		Description: client.Null[string](),
+	})
+	if err != nil {
+		t.Fatalf("edit tender: %s", err)
+	}
+	if tender.Name != "Доставка оборудования" || tender.Description != "" || tender.Version != 2 {
+		t.Fatalf("unexpected edited tender %+v", tender)
+	}
+
+	if _, err := owner.SetTenderStatus(ctx, tender.ID, domain.TenderPublished); err != nil {
+		t.Fatalf("publish tender: %s", err)
+	}
+	status, err := bidder.TenderStatus(ctx, tender.ID)
+	if err != nil || status != domain.TenderPublished {
+		t.Fatalf("expected published tender, got %q, %v", status, err)
+	}
+
+	diff, err := owner.DiffTenderVersions(ctx, tender.ID, 1, 2)
+	if err != nil {
+		t.Fatalf("diff tender versions: %s", err)
+	}
+	if len(diff.Changes) != 2 {
+		t.Fatalf("expected name and description to change, got %+v", diff.Changes)
+	}
+
+	bid, err := bidder.CreateBid(ctx, client.BidRequest{
+		Name:       "Доставим",
+		TenderID:   tender.ID,
+		AuthorType: "User",
+		AuthorID:   bidderID,
+	})
+	if err != nil {
+		t.Fatalf("create bid: %s", err)
+	}
+	if bid.TenderID != tender.ID || bid.Version != 1 {
+		t.Fatalf("unexpected bid %+v", bid)
+	}
+	if _, err := bidder.SetBidStatus(ctx, bid.ID, domain.BidPublished); err != nil {
+		t.Fatalf("publish bid: %s", err)
+	}
+
+	key, err := owner.CreateAPIKey(ctx, client.NewAPIKeyRequest{Name: "ci", Scopes: []string{auth.ScopeSubmitDecisions}})
+	if err != nil {
+		t.Fatalf("create api key: %s", err)
+	}
+	bid, err = c.As(client.APIKeyAuth(key.Key)).SubmitDecision(ctx, bid.ID, models.DecisionApproved)
+	if err != nil {
+		t.Fatalf("submit decision: %s", err)
+	}
+	if bid.Status != domain.BidApproved {
+		t.Fatalf("expected approved bid, got %s", bid.Status)
+	}
+
+	_, err = bidder.SubmitDecision(ctx, bid.ID, models.DecisionRejected)
+	if !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("expected forbidden, got %v", err)
+	}
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.Reason == "" {
+		t.Fatalf("expected reason of error, got %#v", err)
+	}
+
+	_, err = owner.Organization(ctx, organizationID)
+	if err != nil {
+		t.Fatalf("get organization: %s", err)
+	}
+	_, err = owner.Employee(ctx, "ghost")
+	if !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	_, err = c.MyTenders(ctx, "", client.Page{})
+	if !errors.Is(err, client.ErrUnauthorized) {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+	_, err = owner.Tenders(ctx, client.Page{Limit: client.MaxPageSize + 1})
+	if !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("expected bad request, got %v", err)
+	}
+}
+
+func TestIterators(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+	ownerID := st.AddEmployee("owner", "", "")
+	organizationID := st.AddOrganization("acme", "", "LLC")
+	st.AddResponsible(organizationID, ownerID)
+
+	tender, err := st.InsertTender(ctx, &models.NewTenderRequest{
+		Name:           "tender",
+		ServiceType:    "Construction",
+		OrganizationID: organizationID,
+	}, ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.ChangeTenderStatus(ctx, tender.ID, domain.TenderPublished); err != nil {
+		t.Fatal(err)
+	}
+
+	// exactly two full pages, so that iterator requests the third one
+	// which is empty or answered with 404
+	total := 2 * client.MaxPageSize
+	for i := 1; i < total; i++ {
+		_, err := st.InsertTender(ctx, &models.NewTenderRequest{
+			Name:           fmt.Sprintf("tender %d", i),
+			ServiceType:    "Delivery",
+			OrganizationID: organizationID,
+		}, ownerID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < total; i++ {
+		authorID := st.AddEmployee(fmt.Sprintf("author%d", i), "", "")
+		st.AddResponsible(st.AddOrganization(fmt.Sprintf("bidco %d", i), "", "IE"), authorID)
+		bid, err := st.InsertBid(ctx, models.BidRequest{
+			Name:       fmt.Sprintf("bid %d", i),
+			TenderID:   tender.ID,
+			AuthorType: "User",
+			AuthorID:   authorID,
+		}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := st.ChangeBitStatus(ctx, bid.ID, domain.BidPublished); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var requests atomic.Int32
+	router := newRouter(t, st)
+	counting := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		router.ServeHTTP(w, r)
+	})
+	owner := newClient(t, counting).As(client.LegacyUsername("owner"))
+
+	count := func(name string, seq func(yield func(struct{}, error) bool)) {
+		t.Helper()
+
+		requests.Store(0)
+		n := 0
+		for _, err := range seq {
+			if err != nil {
+				t.Fatalf("%s: %s", name, err)
+			}
+			n++
+		}
+		if n != total {
+			t.Errorf("%s: expected %d items, got %d", name, total, n)
+		}
+		if requests.Load() != 3 {
+			t.Errorf("%s: expected 3 pages to be requested, got %d", name, requests.Load())
+		}
+	}
+
+	count("my tenders", func(yield func(struct{}, error) bool) {
+		for _, err := range owner.AllMyTenders(ctx, organizationID) {
+			if !yield(struct{}{}, err) {
+				return
+			}
+		}
+	})
+	count("tender bids", func(yield func(struct{}, error) bool) {
+		for _, err := range owner.AllTenderBids(ctx, tender.ID) {
+			if !yield(struct{}{}, err) {
+				return
+			}
+		}
+	})
+
+	// breaking out of loop stops requesting pages
+	requests.Store(0)
+	for range owner.AllMyTenders(ctx, organizationID) {
+		break
+	}
+	if requests.Load() != 1 {
+		t.Errorf("expected 1 page to be requested, got %d", requests.Load())
+	}
+
+	// error stops iteration
+	for _, err := range owner.AllTenderBids(ctx, "unknown") {
+		if !errors.Is(err, client.ErrForbidden) && !errors.Is(err, client.ErrNotFound) {
+			t.Fatalf("expected error of unknown tender, got %v", err)
+		}
+	}
+}
+
+// flaky answers with status to the first failures requests and
+// passes the rest to next
+func flaky(next http.Handler, failures int32, status int) (http.Handler, *atomic.Int32) {
+	var requests atomic.Int32
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}), &requests
+}
+
+func TestRetries(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+	ownerID := st.AddEmployee("owner", "", "")
+	organizationID := st.AddOrganization("acme", "", "LLC")
+	st.AddResponsible(organizationID, ownerID)
+	router := newRouter(t, st)
+	owner := client.WithAuth(client.BearerToken(signIn(t, newClient(t, router), st, "owner")))
+	options := []client.Option{owner, client.WithRetries(2, time.Millisecond)}
+
+	t.Run("idempotent call is retried", func(t *testing.T) {
+		handler, requests := flaky(router, 2, http.StatusServiceUnavailable)
+		c := newClient(t, handler, options...)
+
+		if _, err := c.Organization(ctx, organizationID); err != nil {
+			t.Fatalf("expected success after retries, got %v", err)
+		}
+		if requests.Load() != 3 {
+			t.Errorf("expected 3 requests, got %d", requests.Load())
+		}
+	})
+
+	t.Run("retries are limited", func(t *testing.T) {
+		handler, requests := flaky(router, 5, http.StatusBadGateway)
+		c := newClient(t, handler, options...)
+
+		_, err := c.Organization(ctx, organizationID)
+		var apiErr *client.Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+			t.Fatalf("expected bad gateway, got %v", err)
+		}
+		if requests.Load() != 3 {
+			t.Errorf("expected 3 requests, got %d", requests.Load())
+		}
+	})
+
+	t.Run("client error is not retried", func(t *testing.T) {
+		handler, requests := flaky(router, 1, http.StatusConflict)
+		c := newClient(t, handler, options...)
+
+		if _, err := c.Organization(ctx, organizationID); !errors.Is(err, client.ErrConflict) {
+			t.Fatalf("expected conflict, got %v", err)
+		}
+		if requests.Load() != 1 {
+			t.Errorf("expected 1 request, got %d", requests.Load())
+		}
+	})
+
+	t.Run("not idempotent call is not retried", func(t *testing.T) {
+		handler, requests := flaky(router, 1, http.StatusServiceUnavailable)
+		c := newClient(t, handler, options...)
+
+		_, err := c.CreateOrganization(ctx, client.NewOrganizationRequest{Name: "acme holding", Type: "JSC"})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if requests.Load() != 1 {
+			t.Errorf("expected 1 request, got %d", requests.Load())
+		}
+	})
+
+	t.Run("canceled context stops retries", func(t *testing.T) {
+		handler, requests := flaky(router, 5, http.StatusServiceUnavailable)
+		c := newClient(t, handler, owner, client.WithRetries(5, time.Hour))
+
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err := c.Organization(ctx, organizationID)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+		if requests.Load() != 1 {
+			t.Errorf("expected 1 request, got %d", requests.Load())
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Error is returned for response with status 4xx or 5xx. Reason is
+// taken from errorResponse body, it is empty if server sent none
+type Error struct {
+	StatusCode int
+	Reason     string
+}
+
+// Sentinels to check status of *Error with errors.Is
+var (
+	ErrBadRequest      = &Error{StatusCode: http.StatusBadRequest}
+	ErrUnauthorized    = &Error{StatusCode: http.StatusUnauthorized}
+	ErrForbidden       = &Error{StatusCode: http.StatusForbidden}
+	ErrNotFound        = &Error{StatusCode: http.StatusNotFound}
+	ErrConflict        = &Error{StatusCode: http.StatusConflict}
+	ErrTooManyRequests = &Error{StatusCode: http.StatusTooManyRequests}
+)
+
+func (e *Error) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("tender api: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+
+	return fmt.Sprintf("tender api: %d %s", e.StatusCode, e.Reason)
+}
+
+// Is matches errors with the same status code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.StatusCode == e.StatusCode
+}
+
+// errorResponse is a body of response with error status
+type errorResponse struct {
+	Reason string `json:"reason"`
+}
+
+func decodeError(resp *http.Response) error {
+	var body errorResponse
+	data, err := io.ReadAll(resp.Body)
+	if err == nil {
+		// body of 5xx responses may be empty
+		json.Unmarshal(data, &body)
+	}
+
+	return &Error{StatusCode: resp.StatusCode, Reason: body.Reason}
+}
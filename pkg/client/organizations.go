@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// CreateOrganization creates organization, its creator becomes owner
+func (c *Client) CreateOrganization(ctx context.Context, organization NewOrganizationRequest) (Organization, error) {
+	var created Organization
+	err := c.do(ctx, call{method: http.MethodPost, path: []string{"organizations", "new"}, body: organization}, &created)
+
+	return created, err
+}
+
+// MyOrganizations returns organizations the authenticated
+// employee is responsible for and his roles in them
+func (c *Client) MyOrganizations(ctx context.Context) ([]Membership, error) {
+	var memberships []Membership
+	err := c.do(ctx, get("organizations", "my"), &memberships)
+
+	return memberships, err
+}
+
+func (c *Client) Organization(ctx context.Context, organizationID string) (Organization, error) {
+	var organization Organization
+	err := c.do(ctx, get("organizations", organizationID), &organization)
+
+	return organization, err
+}
+
+// EditOrganization changes organization, fields of edit
+// are built with Set and Null
+func (c *Client) EditOrganization(ctx context.Context, organizationID string, edit EditOrganizationRequest) (Organization, error) {
+	var organization Organization
+	err := c.do(ctx, call{method: http.MethodPatch, path: []string{"organizations", organizationID, "edit"}, body: edit}, &organization)
+
+	return organization, err
+}
+
+// DeleteOrganization deletes organization which has no
+// active tenders and bids and returns it
+func (c *Client) DeleteOrganization(ctx context.Context, organizationID string) (Organization, error) {
+	var organization Organization
+	err := c.do(ctx, call{method: http.MethodDelete, path: []string{"organizations", organizationID}}, &organization)
+
+	return organization, err
+}
+
+// Members returns employees responsible for organization and their roles
+func (c *Client) Members(ctx context.Context, organizationID string) ([]Member, error) {
+	var members []Member
+	err := c.do(ctx, get("organizations", organizationID, "members"), &members)
+
+	return members, err
+}
+
+// GrantRole makes employee responsible for organization with role
+// or changes his role. Granting the same role again changes nothing
+func (c *Client) GrantRole(ctx context.Context, organizationID, username, role string) (Member, error) {
+	var member Member
+	err := c.do(ctx, call{
+		method:     http.MethodPut,
+		path:       []string{"organizations", organizationID, "members", username, "grant"},
+		query:      url.Values{"role": {role}},
+		idempotent: true,
+	}, &member)
+
+	return member, err
+}
+
+// RevokeRole removes employee from responsibles of organization
+func (c *Client) RevokeRole(ctx context.Context, organizationID, username string) (Member, error) {
+	var member Member
+	err := c.do(ctx, call{method: http.MethodPut, path: []string{"organizations", organizationID, "members", username, "revoke"}}, &member)
+
+	return member, err
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// MaxPageSize is the largest limit the API accepts
+const MaxPageSize = 50
+
+// Page selects part of list, zero Limit means
+// the server default of 5 items
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+func (p Page) query() url.Values {
+	query := url.Values{}
+	if p.Limit > 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset > 0 {
+		query.Set("offset", strconv.Itoa(p.Offset))
+	}
+
+	return query
+}
+
+type lister[T any] func(ctx context.Context, page Page) ([]T, error)
+
+// all iterates over every item of list requesting pages of MaxPageSize
+// until a shorter page is returned. Iteration stops after first error
+func all[T any](ctx context.Context, list lister[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		page := Page{Limit: MaxPageSize}
+		for {
+			items, err := list(ctx, page)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(items) < page.Limit {
+				return
+			}
+			page.Offset += page.Limit
+		}
+	}
+}
+
+// endsWithNotFound adapts list of endpoint which answers 404 instead of
+// empty page when offset goes past the last item
+func endsWithNotFound[T any](list lister[T]) lister[T] {
+	return func(ctx context.Context, page Page) ([]T, error) {
+		items, err := list(ctx, page)
+		if page.Offset > 0 && errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+
+		return items, err
+	}
+}
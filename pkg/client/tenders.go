@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Tenders returns page of tenders visible to the authenticated
+// employee, filtered by service types if any are given
+func (c *Client) Tenders(ctx context.Context, page Page, serviceTypes ...string) ([]Tender, error) {
+	query := page.query()
+	for _, serviceType := range serviceTypes {
+		query.Add("service_type", serviceType)
+	}
+
+	var tenders []Tender
+	err := c.do(ctx, get("tenders").with(query), &tenders)
+
+	return tenders, err
+}
+
+// AllTenders iterates over tenders visible to the authenticated employee
+func (c *Client) AllTenders(ctx context.Context, serviceTypes ...string) iter.Seq2[Tender, error] {
+	return all(ctx, func(ctx context.Context, page Page) ([]Tender, error) {
+		return c.Tenders(ctx, page, serviceTypes...)
+	})
+}
+
+func (c *Client) CreateTender(ctx context.Context, tender NewTenderRequest) (Tender, error) {
+	var created Tender
+	err := c.do(ctx, call{method: http.MethodPost, path: []string{"tenders", "new"}, body: tender}, &created)
+
+	return created, err
+}
+
+// MyTenders returns page of tenders created by the authenticated
+// employee, only of organization if it is not empty
+func (c *Client) MyTenders(ctx context.Context, organizationID string, page Page) ([]Tender, error) {
+	query := page.query()
+	if organizationID != "" {
+		query.Set("organizationId", organizationID)
+	}
+
+	var tenders []Tender
+	err := c.do(ctx, get("tenders", "my").with(query), &tenders)
+
+	return tenders, err
+}
+
+// AllMyTenders iterates over tenders created by the authenticated employee
+func (c *Client) AllMyTenders(ctx context.Context, organizationID string) iter.Seq2[Tender, error] {
+	return all(ctx, func(ctx context.Context, page Page) ([]Tender, error) {
+		return c.MyTenders(ctx, organizationID, page)
+	})
+}
+
+func (c *Client) TenderStatus(ctx context.Context, tenderID string) (string, error) {
+	var status string
+	err := c.do(ctx, get("tenders", tenderID, "status"), &status)
+
+	return status, err
+}
+
+// SetTenderStatus moves tender to status, setting the
+// current status again changes nothing
+func (c *Client) SetTenderStatus(ctx context.Context, tenderID, status string) (Tender, error) {
+	var tender Tender
+	err := c.do(ctx, call{
+		method:     http.MethodPut,
+		path:       []string{"tenders", tenderID, "status"},
+		query:      url.Values{"status": {status}},
+		idempotent: true,
+	}, &tender)
+
+	return tender, err
+}
+
+// EditTender changes tender and creates its new version,
+// fields of edit are built with Set and Null
+func (c *Client) EditTender(ctx context.Context, tenderID string, edit EditTenderRequest) (Tender, error) {
+	var tender Tender
+	err := c.do(ctx, call{method: http.MethodPatch, path: []string{"tenders", tenderID, "edit"}, body: edit}, &tender)
+
+	return tender, err
+}
+
+// RollbackTender creates new version of tender equal to version
+func (c *Client) RollbackTender(ctx context.Context, tenderID string, version int) (Tender, error) {
+	var tender Tender
+	err := c.do(ctx, call{method: http.MethodPut, path: []string{"tenders", tenderID, "rollback", strconv.Itoa(version)}}, &tender)
+
+	return tender, err
+}
+
+// TenderVersions returns page of tender history, latest version first
+func (c *Client) TenderVersions(ctx context.Context, tenderID string, page Page) ([]TenderVersion, error) {
+	var versions []TenderVersion
+	err := c.do(ctx, get("tenders", tenderID, "versions").with(page.query()), &versions)
+
+	return versions, err
+}
+
+// AllTenderVersions iterates over tender history, latest version first
+func (c *Client) AllTenderVersions(ctx context.Context, tenderID string) iter.Seq2[TenderVersion, error] {
+	return all(ctx, func(ctx context.Context, page Page) ([]TenderVersion, error) {
+		return c.TenderVersions(ctx, tenderID, page)
+	})
+}
+
+// DiffTenderVersions returns fields of tender which differ between versions
+func (c *Client) DiffTenderVersions(ctx context.Context, tenderID string, from, to int) (VersionDiff, error) {
+	var diff VersionDiff
+	err := c.do(ctx, get("tenders", tenderID, "versions", strconv.Itoa(from), "diff", strconv.Itoa(to)), &diff)
+
+	return diff, err
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"zadanie-6105/internal/storage/models"
+)
+
+// Requests and responses are the structs the server encodes and
+// decodes, aliased so that packages outside this module can name them
+type (
+	Tender            = models.Tender
+	NewTenderRequest  = models.NewTenderRequest
+	EditTenderRequest = models.EditTenderRequest
+	TenderVersion     = models.TenderVersion
+
+	Bid            = models.Bid
+	BidRequest     = models.BidRequest
+	EditBidRequest = models.EditBidRequest
+	BidVersion     = models.BidVersion
+	Feedback       = models.Feedback
+
+	VersionDiff = models.VersionDiff
+	FieldChange = models.FieldChange
+
+	LoginRequest                = models.LoginRequest
+	TokenResponse               = models.TokenResponse
+	ChangePasswordRequest       = models.ChangePasswordRequest
+	PasswordResetConfirmRequest = models.PasswordResetConfirmRequest
+
+	Employee            = models.Employee
+	EmployeeProfile     = models.EmployeeProfile
+	NewEmployeeRequest  = models.NewEmployeeRequest
+	EditEmployeeRequest = models.EditEmployeeRequest
+
+	Organization            = models.Organization
+	NewOrganizationRequest  = models.NewOrganizationRequest
+	EditOrganizationRequest = models.EditOrganizationRequest
+	Member                  = models.Member
+	Membership              = models.Membership
+
+	APIKey           = models.APIKey
+	NewAPIKeyRequest = models.NewAPIKeyRequest
+	APIKeyWithSecret = models.APIKeyResponse
+)
+
+// Set returns field of edit request which is changed to value
+func Set[T any](value T) models.Optional[T] {
+	return models.Optional[T]{Set: true, Value: value}
+}
+
+// Null returns field of edit request which is cleared
+func Null[T any]() models.Optional[T] {
+	return models.Optional[T]{Set: true, Null: true}
+}
+
+// patch encodes edit request without fields which are not set, because
+// Optional encodes them as null and server would clear them
+func patch(edit any) (map[string]json.RawMessage, error) {
+	v := reflect.Indirect(reflect.ValueOf(edit))
+	body := make(map[string]json.RawMessage, v.NumField())
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if set := v.Field(i).FieldByName("Set"); set.IsValid() && !set.Bool() {
+			continue
+		}
+
+		value, err := json.Marshal(v.Field(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		body[name] = value
+	}
+
+	return body, nil
+}